@@ -0,0 +1,10 @@
+// Package adminhttp provides the http.Handler with the JSON endpoints to operate a proxym.ProxyManagerImpl
+// at runtime: list the proxies with their statistics, enable and disable them, add and remove them
+// and view the resources.
+//
+// The handler serves the paths from the root, mount it under a prefix with http.StripPrefix:
+//
+//	mux.Handle("/admin/proxym/", http.StripPrefix("/admin/proxym", adminhttp.NewHandler(pm)))
+//
+// The handler has no authentication, protect it like other admin endpoints.
+package adminhttp
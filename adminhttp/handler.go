@@ -0,0 +1,256 @@
+package adminhttp
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/nezbut/proxym"
+)
+
+// maxBodySize is the maximum size of a request body.
+const maxBodySize = 1 << 20
+
+// Proxy is the JSON representation of a proxy.
+type Proxy struct {
+	ID            string               `json:"id"`
+	URL           string               `json:"url"`
+	Direct        bool                 `json:"direct"`
+	Disabled      bool                 `json:"disabled"`
+	DisabledUntil *time.Time           `json:"disabled_until,omitempty"`
+	Draining      bool                 `json:"draining"`
+	Quarantined   bool                 `json:"quarantined"`
+	Active        int                  `json:"active"`
+	InFlight      int                  `json:"in_flight"`
+	Stats         proxym.StatsSnapshot `json:"stats"`
+	Country       string               `json:"country,omitempty"`
+	Priority      proxym.ProxyPriority `json:"priority"`
+	Tags          []string             `json:"tags,omitempty"`
+}
+
+// Resource is the JSON representation of a resource.
+type Resource struct {
+	Domain        string                   `json:"domain"`
+	MatchPriority int                      `json:"match_priority"`
+	Proxies       []string                 `json:"proxies"`
+	Banned        []string                 `json:"banned,omitempty"`
+	Counters      proxym.SelectionCounters `json:"counters"`
+	Stats         proxym.StatsSnapshot     `json:"stats"`
+}
+
+// addRequest is the body of the request to add the proxies.
+type addRequest struct {
+	URLs []string `json:"urls"`
+}
+
+// Handler is the http.Handler with the JSON endpoints of the ProxyManagerImpl:
+//
+//	GET    /proxies               lists the proxies of the full fleet with their statistics
+//	POST   /proxies               adds the proxies {"urls": ["http://1.2.3.4:8080"]} to the global pool
+//	GET    /proxies/{id}          returns the proxy by the id, see proxym.Proxy.ID
+//	DELETE /proxies/{id}          removes the proxy from the global pool and from the resources
+//	POST   /proxies/{id}/enable   enables the proxy
+//	POST   /proxies/{id}/disable  disables the proxy, for a duration with ?for=10m
+//	POST   /proxies/{id}/reset    clears the statistics of the proxy
+//	GET    /resources             lists the resources with their proxy ids, banned proxy ids and statistics
+//
+// The errors are returned as {"error": "message"} with the status code of the error.
+type Handler struct {
+	pm  *proxym.ProxyManagerImpl
+	mux *http.ServeMux
+}
+
+// NewHandler returns a new Handler of the manager.
+func NewHandler(pm *proxym.ProxyManagerImpl) *Handler {
+	h := &Handler{pm: pm, mux: http.NewServeMux()}
+	h.mux.HandleFunc("GET /proxies", h.listProxies)
+	h.mux.HandleFunc("POST /proxies", h.addProxies)
+	h.mux.HandleFunc("GET /proxies/{id}", h.getProxy)
+	h.mux.HandleFunc("DELETE /proxies/{id}", h.removeProxy)
+	h.mux.HandleFunc("POST /proxies/{id}/enable", h.enableProxy)
+	h.mux.HandleFunc("POST /proxies/{id}/disable", h.disableProxy)
+	h.mux.HandleFunc("POST /proxies/{id}/reset", h.resetProxy)
+	h.mux.HandleFunc("GET /resources", h.listResources)
+	return h
+}
+
+// ServeHTTP serves the endpoints.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mux.ServeHTTP(w, r)
+}
+
+// listProxies lists the proxies of the full fleet.
+func (h *Handler) listProxies(w http.ResponseWriter, _ *http.Request) {
+	fleet := h.pm.Fleet()
+	proxies := make([]Proxy, 0, len(fleet))
+	for _, p := range fleet {
+		proxies = append(proxies, newProxy(p))
+	}
+	writeJSON(w, http.StatusOK, proxies)
+}
+
+// addProxies adds the proxies to the global pool.
+func (h *Handler) addProxies(w http.ResponseWriter, r *http.Request) {
+	var req addRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodySize)).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	proxies := make([]*proxym.Proxy, 0, len(req.URLs))
+	for _, rawURL := range req.URLs {
+		proxy, err := proxym.NewProxyParsedStr(rawURL, nil)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		proxies = append(proxies, proxy)
+	}
+	if err := h.pm.AddProxies(proxies...); err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, proxym.ErrDuplicateProxy) {
+			status = http.StatusConflict
+		}
+		writeError(w, status, err)
+		return
+	}
+
+	added := make([]Proxy, 0, len(proxies))
+	for _, p := range proxies {
+		added = append(added, newProxy(p))
+	}
+	writeJSON(w, http.StatusCreated, added)
+}
+
+// getProxy returns the proxy by the id.
+func (h *Handler) getProxy(w http.ResponseWriter, r *http.Request) {
+	if proxy, ok := h.proxy(w, r); ok {
+		writeJSON(w, http.StatusOK, newProxy(proxy))
+	}
+}
+
+// removeProxy removes the proxy by the id from the full fleet.
+func (h *Handler) removeProxy(w http.ResponseWriter, r *http.Request) {
+	proxy, ok := h.proxy(w, r)
+	if !ok {
+		return
+	}
+	h.pm.RemoveWhere(func(p *proxym.Proxy) bool {
+		return p.ID() == proxy.ID()
+	})
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// enableProxy enables the proxy by the id.
+func (h *Handler) enableProxy(w http.ResponseWriter, r *http.Request) {
+	proxy, ok := h.proxy(w, r)
+	if !ok {
+		return
+	}
+	proxy.Enable()
+	writeJSON(w, http.StatusOK, newProxy(proxy))
+}
+
+// disableProxy disables the proxy by the id, for the duration of the "for" query parameter if it is set.
+func (h *Handler) disableProxy(w http.ResponseWriter, r *http.Request) {
+	var duration time.Duration
+	if value := r.URL.Query().Get("for"); value != "" {
+		var err error
+		if duration, err = time.ParseDuration(value); err != nil || duration <= 0 {
+			writeError(w, http.StatusBadRequest, errors.New("invalid duration: "+value))
+			return
+		}
+	}
+	proxy, ok := h.proxy(w, r)
+	if !ok {
+		return
+	}
+	if duration > 0 {
+		proxy.DisableFor(duration)
+	} else {
+		proxy.Disable()
+	}
+	writeJSON(w, http.StatusOK, newProxy(proxy))
+}
+
+// resetProxy clears the statistics of the proxy by the id.
+func (h *Handler) resetProxy(w http.ResponseWriter, r *http.Request) {
+	proxy, ok := h.proxy(w, r)
+	if !ok {
+		return
+	}
+	proxy.Stats().Reset()
+	writeJSON(w, http.StatusOK, newProxy(proxy))
+}
+
+// listResources lists the resources.
+func (h *Handler) listResources(w http.ResponseWriter, _ *http.Request) {
+	resources := h.pm.Resources()
+	list := make([]Resource, 0, len(resources))
+	for _, rc := range resources {
+		list = append(list, Resource{
+			Domain:        rc.Domain(),
+			MatchPriority: rc.MatchPriority(),
+			Proxies:       proxyIDs(rc.GetProxies()),
+			Banned:        proxyIDs(rc.BannedProxies()),
+			Counters:      rc.SelectionCounters(),
+			Stats:         rc.Stats().Snapshot(),
+		})
+	}
+	writeJSON(w, http.StatusOK, list)
+}
+
+// proxyIDs returns the ids of the proxies.
+func proxyIDs(proxies []*proxym.Proxy) []string {
+	ids := make([]string, 0, len(proxies))
+	for _, p := range proxies {
+		ids = append(ids, p.ID())
+	}
+	return ids
+}
+
+// proxy returns the proxy by the id of the path, it writes the not found error if there is none.
+func (h *Handler) proxy(w http.ResponseWriter, r *http.Request) (*proxym.Proxy, bool) {
+	proxy, err := h.pm.GetProxyByID(r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return nil, false
+	}
+	return proxy, true
+}
+
+// newProxy returns the JSON representation of the proxy, the password of the url is redacted.
+func newProxy(p *proxym.Proxy) Proxy {
+	meta := p.Metadata()
+	proxy := Proxy{
+		ID:          p.ID(),
+		URL:         p.URL().Redacted(),
+		Direct:      p.IsDirect(),
+		Disabled:    p.IsDisabled(),
+		Draining:    p.IsDraining(),
+		Quarantined: p.IsQuarantined(),
+		Active:      p.ActiveCount(),
+		InFlight:    p.InFlight(),
+		Stats:       p.Stats().Snapshot(),
+		Country:     meta.Country(),
+		Priority:    meta.Priority(),
+		Tags:        meta.Tags(),
+	}
+	if until := p.DisabledUntil(); !until.IsZero() {
+		proxy.DisabledUntil = &until
+	}
+	return proxy
+}
+
+// writeJSON writes the value as JSON with the status code.
+func writeJSON(w http.ResponseWriter, status int, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(value)
+}
+
+// writeError writes the error as JSON with the status code.
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
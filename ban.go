@@ -0,0 +1,126 @@
+package proxym
+
+import (
+	"slices"
+	"time"
+)
+
+// Ban bans the proxy for the domains of the ResourceConfig for the ttl, or until Unban if the ttl is zero,
+// so the selections for the resource skip it while the other resources and the global pool keep using it.
+//
+// A banned last used proxy is always rotated. The ban is dropped when the proxy is removed from the resource.
+func (rc *ResourceConfig) Ban(proxy *Proxy, ttl time.Duration) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	var until time.Time
+	if ttl > 0 {
+		until = clockNow(rc.clock).Add(ttl)
+	}
+	if rc.bans == nil {
+		rc.bans = make(map[*Proxy]time.Time)
+	}
+	rc.bans[proxy] = until
+}
+
+// Unban lifts the ban of the proxy for the domains of the ResourceConfig, see Ban.
+func (rc *ResourceConfig) Unban(proxy *Proxy) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	delete(rc.bans, proxy)
+}
+
+// IsBanned returns true if the proxy is banned for the domains of the ResourceConfig, see Ban.
+func (rc *ResourceConfig) IsBanned(proxy *Proxy) bool {
+	rc.mu.RLock()
+	defer rc.mu.RUnlock()
+	return rc.isBanned(proxy, clockNow(rc.clock))
+}
+
+// BannedProxies returns the proxies of the ResourceConfig that are banned, see Ban.
+func (rc *ResourceConfig) BannedProxies() []*Proxy {
+	rc.mu.RLock()
+	defer rc.mu.RUnlock()
+
+	now := clockNow(rc.clock)
+	var banned []*Proxy
+	for _, p := range rc.proxies {
+		if rc.isBanned(p, now) {
+			banned = append(banned, p)
+		}
+	}
+	return banned
+}
+
+// BanProxy bans the proxy for the resource by domain for the ttl, see ResourceConfig.Ban.
+//
+// It returns an ErrResourceNotFound error if no resource has the domain.
+func (pm *ProxyManagerImpl) BanProxy(domain string, proxy *Proxy, ttl time.Duration) error {
+	resource, err := pm.getResourceByDomain(domain)
+	if err != nil {
+		return err
+	}
+	resource.Ban(proxy, ttl)
+	return nil
+}
+
+// UnbanProxy lifts the ban of the proxy for the resource by domain, see ResourceConfig.Unban.
+//
+// It returns an ErrResourceNotFound error if no resource has the domain.
+func (pm *ProxyManagerImpl) UnbanProxy(domain string, proxy *Proxy) error {
+	resource, err := pm.getResourceByDomain(domain)
+	if err != nil {
+		return err
+	}
+	resource.Unban(proxy)
+	return nil
+}
+
+// isBanned returns true if the proxy is banned at the time, rc.mu must be held.
+func (rc *ResourceConfig) isBanned(proxy *Proxy, now time.Time) bool {
+	until, ok := rc.bans[proxy]
+	return ok && (until.IsZero() || now.Before(until))
+}
+
+// unbannedProxies returns the copied list of the proxies that are not banned.
+func (rc *ResourceConfig) unbannedProxies() []*Proxy {
+	rc.mu.RLock()
+	defer rc.mu.RUnlock()
+
+	if len(rc.bans) == 0 {
+		return slices.Clone(rc.proxies)
+	}
+	now := clockNow(rc.clock)
+	proxies := make([]*Proxy, 0, len(rc.proxies))
+	for _, p := range rc.proxies {
+		if !rc.isBanned(p, now) {
+			proxies = append(proxies, p)
+		}
+	}
+	return proxies
+}
+
+// unbannedProvider is the SelectStrategyProxyProvider of the select strategy of the ResourceConfig,
+// it skips the banned proxies, see ResourceConfig.Ban.
+type unbannedProvider struct {
+	rc *ResourceConfig
+}
+
+// GetProxies returns the copied list of the proxies of the ResourceConfig that are not banned.
+func (p unbannedProvider) GetProxies() []*Proxy {
+	return p.rc.unbannedProxies()
+}
+
+// AddProxies adds the proxies to the ResourceConfig unless it has them already,
+// so the strategies that add the proxies to their provider, like pac.Select, keep working.
+func (p unbannedProvider) AddProxies(proxies ...*Proxy) {
+	current := p.rc.GetProxies()
+	missing := make([]*Proxy, 0, len(proxies))
+	for _, proxy := range proxies {
+		if !slices.Contains(current, proxy) {
+			missing = append(missing, proxy)
+		}
+	}
+	if len(missing) != 0 {
+		p.rc.AddProxies(missing...)
+	}
+}
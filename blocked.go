@@ -0,0 +1,143 @@
+package proxym
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// ResponseValidator inspects the responses received by ProxyTransport to detect the blocked ones,
+// for example the captcha pages and the WAF challenges, see WithResponseValidator.
+type ResponseValidator interface {
+	// Validate returns an error wrapping ErrResponseBlocked if the response is blocked.
+	//
+	// The body is the beginning of the response body up to the peek size of WithResponseValidator,
+	// the response body is still read from the start by the caller, so the response must not be read.
+	Validate(resp *http.Response, body []byte) error
+}
+
+// ResponseValidatorFunc is a function that implements ResponseValidator.
+type ResponseValidatorFunc func(resp *http.Response, body []byte) error
+
+// Validate calls the function.
+func (f ResponseValidatorFunc) Validate(resp *http.Response, body []byte) error {
+	return f(resp, body)
+}
+
+// BlockedStatusCodes returns the ResponseValidator that flags the responses with the status codes as blocked,
+// for example BlockedStatusCodes(http.StatusForbidden, http.StatusTooManyRequests).
+func BlockedStatusCodes(codes ...int) ResponseValidator {
+	blocked := make(map[int]struct{}, len(codes))
+	for _, code := range codes {
+		blocked[code] = struct{}{}
+	}
+	return ResponseValidatorFunc(func(resp *http.Response, _ []byte) error {
+		if _, ok := blocked[resp.StatusCode]; ok {
+			return fmt.Errorf("%w: status %d", ErrResponseBlocked, resp.StatusCode)
+		}
+		return nil
+	})
+}
+
+// BlockedBodyContains returns the ResponseValidator that flags the responses as blocked
+// if the peeked body contains any of the markers, for example "g-recaptcha" or "cf-challenge".
+func BlockedBodyContains(markers ...string) ResponseValidator {
+	return ResponseValidatorFunc(func(_ *http.Response, body []byte) error {
+		for _, marker := range markers {
+			if bytes.Contains(body, []byte(marker)) {
+				return fmt.Errorf("%w: body contains %q", ErrResponseBlocked, marker)
+			}
+		}
+		return nil
+	})
+}
+
+// ResponseValidators returns the ResponseValidator that returns the error of the first validator
+// that flags the response as blocked.
+func ResponseValidators(validators ...ResponseValidator) ResponseValidator {
+	return ResponseValidatorFunc(func(resp *http.Response, body []byte) error {
+		for _, v := range validators {
+			if err := v.Validate(resp, body); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// blockHandler is implemented by the managers that exclude the blocked proxies for the domain of the request.
+//
+// ProxyTransport calls blockProxy with the WithBanBlocked option.
+type blockHandler interface {
+	blockProxy(info *RequestInfo, proxy *Proxy, ttl time.Duration)
+}
+
+// blockProxy bans the proxy for the resource of the request for the ttl, so the other domains keep using it,
+// see ResourceConfig.Ban.
+//
+// The requests without a resource are not affected, the global pool is shared by all domains.
+func (pm *ProxyManagerImpl) blockProxy(info *RequestInfo, proxy *Proxy, ttl time.Duration) {
+	resource, err := pm.getResource(info)
+	if err != nil {
+		return
+	}
+	resource.Ban(proxy, ttl)
+}
+
+// validate returns the error of the ResponseValidator if the response is blocked, see WithResponseValidator.
+//
+// The body of the response is peeked and replaced by the body that is read from the start.
+func (pt *ProxyTransport) validate(resp *http.Response, err error) error {
+	if pt.validator == nil || err != nil || resp == nil {
+		return nil
+	}
+	var body []byte
+	if pt.peekSize > 0 && resp.Body != nil && resp.Body != http.NoBody {
+		body, resp.Body = peekBody(resp.Body, pt.peekSize)
+	}
+	return pt.validator.Validate(resp, body)
+}
+
+// blocked handles the blocked response of the request through the proxy, see WithBanBlocked.
+func (pt *ProxyTransport) blocked(info *RequestInfo, proxy *Proxy) {
+	if !pt.banBlocked {
+		return
+	}
+	if handler, ok := pt.pm.(blockHandler); ok {
+		handler.blockProxy(info, proxy, pt.banTTL)
+	}
+}
+
+// peekBody reads up to n bytes of the body and returns them with the body that is read from the start.
+//
+// The read error, if any, is returned by the body after the peeked bytes.
+func peekBody(body io.ReadCloser, n int) ([]byte, io.ReadCloser) {
+	buf := make([]byte, n)
+	read, err := io.ReadFull(body, buf)
+	buf = buf[:read]
+
+	rest := io.Reader(body)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		rest = errReader{err: err}
+	}
+	return buf, &peekedBody{Reader: io.MultiReader(bytes.NewReader(buf), rest), Closer: body}
+}
+
+// peekedBody is the response body with the peeked bytes put back.
+type peekedBody struct {
+	io.Reader
+	io.Closer
+}
+
+// errReader is the reader that returns the error.
+type errReader struct {
+	err error
+}
+
+// Read returns the error.
+func (r errReader) Read([]byte) (int, error) {
+	return 0, r.err
+}
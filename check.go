@@ -0,0 +1,71 @@
+package proxym
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// ProxyChecker checks a proxy, for example by a request to a known target through it,
+// see ProxyManagerImpl.CheckProxies. healthcheck.HTTPChecker implements it.
+type ProxyChecker interface {
+	// CheckProxy returns the status code of the response of the check, zero if there is none,
+	// and a non-nil error if the proxy failed the check.
+	CheckProxy(ctx context.Context, proxy *Proxy) (int, error)
+}
+
+// ProxyCheckerFunc is a function that implements ProxyChecker.
+type ProxyCheckerFunc func(ctx context.Context, proxy *Proxy) (int, error)
+
+// CheckProxy calls the function.
+func (f ProxyCheckerFunc) CheckProxy(ctx context.Context, proxy *Proxy) (int, error) {
+	return f(ctx, proxy)
+}
+
+// ProxyCheck is the report of the check of a proxy, see ProxyManagerImpl.CheckProxies.
+type ProxyCheck struct {
+	// Proxy is the checked proxy.
+	Proxy *Proxy
+	// Latency is the duration of the check.
+	Latency time.Duration
+	// StatusCode is the status code of the response of the check, zero if there is none.
+	StatusCode int
+	// Err is the error of the failed check, it is the context error if the check was not run.
+	Err error
+}
+
+// CheckProxies runs the check against every proxy of the full fleet with up to concurrency checks at once
+// and returns the reports in the order of Fleet, for example to verify the pool before starting a large job.
+//
+// The checks do not change the proxies, their statistics and their disabled flags, the caller decides
+// what to do with the failed ones, for example disables them. If the context is done,
+// the remaining proxies are reported with the context error. The concurrency less than 1 is 1.
+func (pm *ProxyManagerImpl) CheckProxies(ctx context.Context, checker ProxyChecker, concurrency int) []ProxyCheck {
+	fleet := pm.fleet()
+	reports := make([]ProxyCheck, len(fleet))
+	sem := make(chan struct{}, max(concurrency, 1))
+	var wg sync.WaitGroup
+
+	for i, p := range fleet {
+		reports[i].Proxy = p
+		select {
+		case <-ctx.Done():
+			reports[i].Err = ctx.Err()
+			continue
+		case sem <- struct{}{}:
+		}
+
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			start := time.Now()
+			reports[i].StatusCode, reports[i].Err = checker.CheckProxy(ctx, p)
+			reports[i].Latency = time.Since(start)
+		}()
+	}
+	wg.Wait()
+	return reports
+}
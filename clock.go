@@ -0,0 +1,27 @@
+package proxym
+
+import "time"
+
+// Clock is an interface for getting the current time.
+//
+// It is used by the time-based strategies and can be replaced to control the time, for example in tests.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+}
+
+// SystemClock is a Clock that returns the system time.
+type SystemClock struct{}
+
+// Now returns the current system time.
+func (SystemClock) Now() time.Time {
+	return time.Now()
+}
+
+// clockNow returns the current time of the clock, the system time if the clock is nil.
+func clockNow(clock Clock) time.Time {
+	if clock == nil {
+		return time.Now()
+	}
+	return clock.Now()
+}
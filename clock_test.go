@@ -0,0 +1,43 @@
+package proxym_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nezbut/proxym"
+)
+
+func TestWithClock(t *testing.T) {
+	clock := newFakeClock()
+	proxies := newProxies(2)
+	rc := newResource("a.com", proxies...)
+	pm := newManager(proxies, proxym.WithClock(clock), proxym.WithResources(rc), proxym.WithStatsHalfLife(time.Hour))
+
+	cooled, banned := proxies[0], proxies[1]
+	cooled.DisableFor(time.Minute)
+	rc.Ban(banned, time.Minute)
+	lease, err := pm.Acquire("b.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	lease.Release(nil)
+	used := lease.Proxy()
+
+	if got := used.Stats().LastUsed(); !got.Equal(clock.Now()) {
+		t.Errorf("got last used %s, want the time of the clock %s", got, clock.Now())
+	}
+	if !cooled.IsDisabled() || !rc.IsBanned(banned) {
+		t.Fatal("got the cooldown or the ban over before the clock advanced")
+	}
+
+	clock.Advance(time.Hour)
+	if cooled.IsDisabled() {
+		t.Error("got the proxy disabled after its cooldown on the clock")
+	}
+	if rc.IsBanned(banned) {
+		t.Error("got the proxy banned after its ban on the clock")
+	}
+	if got := used.Stats().Decayed().Successes; got != 0.5 {
+		t.Errorf("got %v decayed successes after the half-life on the clock, want 0.5", got)
+	}
+}
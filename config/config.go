@@ -0,0 +1,303 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/nezbut/proxym"
+)
+
+// Errors of the configuration.
+var (
+	ErrInvalidConfig   = errors.New("invalid config")
+	ErrUnknownStrategy = errors.New("unknown strategy")
+	ErrUnknownFilter   = errors.New("unknown filter")
+	ErrInvalidParam    = errors.New("invalid param")
+)
+
+// directURL is the url of the ProxyConfig of the direct connection.
+const directURL = "direct"
+
+// Config is the configuration of the ProxyManagerImpl.
+type Config struct {
+	// Proxies are the proxies of the global pool.
+	Proxies []ProxyConfig `json:"proxies" yaml:"proxies"`
+	// Select is the select strategy of the global pool, by default selects.DefaultSelectStrategy.
+	Select *StrategyConfig `json:"select,omitempty" yaml:"select,omitempty"`
+	// Rotation is the rotation strategy of the global pool, by default rotations.DefaultRotationStrategy.
+	Rotation *StrategyConfig `json:"rotation,omitempty" yaml:"rotation,omitempty"`
+	// Resources are the resources, see proxym.ResourceConfig.
+	Resources []ResourceConfig `json:"resources,omitempty" yaml:"resources,omitempty"`
+	// MaxPool is the maximum size of the proxy pool, see proxym.WithMaxPool.
+	MaxPool int `json:"max_pool,omitempty" yaml:"max_pool,omitempty"`
+	// Eviction is the eviction policy of the pool, "oldest_last_used" (by default) or "worst_success_rate".
+	Eviction string `json:"eviction,omitempty" yaml:"eviction,omitempty"`
+	// FairShare enables the fair-share accounting, see proxym.WithFairShare.
+	FairShare bool `json:"fair_share,omitempty" yaml:"fair_share,omitempty"`
+	// PoolDebounce is the debounce duration of the pool callbacks, see proxym.WithPoolDebounce.
+	PoolDebounce time.Duration `json:"pool_debounce,omitempty" yaml:"pool_debounce,omitempty"`
+	// StatsHalfLife is the half-life of the decayed counts of the proxy statistics, see proxym.WithStatsHalfLife.
+	StatsHalfLife time.Duration `json:"stats_half_life,omitempty" yaml:"stats_half_life,omitempty"`
+	// DisableAfter is the number of the consecutive errors that disables a proxy, see proxym.WithDisablePolicy.
+	DisableAfter uint `json:"disable_after,omitempty" yaml:"disable_after,omitempty"`
+	// DisableBackoff is the duration of the first automatic disable, it doubles on each subsequent one.
+	DisableBackoff time.Duration `json:"disable_backoff,omitempty" yaml:"disable_backoff,omitempty"`
+	// DisableMaxBackoff caps the duration of the automatic disables, zero is unlimited.
+	DisableMaxBackoff time.Duration `json:"disable_max_backoff,omitempty" yaml:"disable_max_backoff,omitempty"`
+}
+
+// ProxyConfig is the configuration of a proxy.
+type ProxyConfig struct {
+	// URL is the url of the proxy, "direct" is the direct connection, see proxym.NewDirectConnection.
+	URL string `json:"url" yaml:"url"`
+	// Country is the country of the proxy.
+	Country string `json:"country,omitempty" yaml:"country,omitempty"`
+	// Priority is the priority of the proxy: 0 is low, 1 is medium and 2 is high.
+	Priority uint `json:"priority,omitempty" yaml:"priority,omitempty"`
+	// Weight is the weight of the proxy, see proxym.ProxyMetadata.SetWeight.
+	Weight uint `json:"weight,omitempty" yaml:"weight,omitempty"`
+	// Timeout is the timeout of the requests through the proxy, see proxym.ProxyMetadata.SetTimeout.
+	Timeout time.Duration `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+	// ExpiresAt is the expiration time of the proxy in RFC 3339.
+	ExpiresAt time.Time `json:"expires_at,omitempty" yaml:"expires_at,omitempty"`
+	// Tags are the tags of the proxy.
+	Tags []string `json:"tags,omitempty" yaml:"tags,omitempty"`
+	// Headers are the headers sent to the proxy, see proxym.ProxyMetadata.SetHeader.
+	Headers map[string]string `json:"headers,omitempty" yaml:"headers,omitempty"`
+}
+
+// StrategyConfig is the configuration of a named select or rotation strategy.
+type StrategyConfig struct {
+	// Name is the name of the strategy.
+	Name string `json:"name" yaml:"name"`
+	// Params are the parameters of the strategy.
+	Params Params `json:"params,omitempty" yaml:"params,omitempty"`
+	// Strategies are the nested strategies, for example the tiers strategy of "priority"
+	// or the strategies of the composite rotation "any".
+	Strategies []StrategyConfig `json:"strategies,omitempty" yaml:"strategies,omitempty"`
+	// Filters are the filters of the select strategy, see selects.NewFilteredSelectFactory.
+	Filters []FilterConfig `json:"filters,omitempty" yaml:"filters,omitempty"`
+}
+
+// FilterConfig is the configuration of a named select filter.
+type FilterConfig struct {
+	// Name is the name of the filter.
+	Name string `json:"name" yaml:"name"`
+	// Params are the parameters of the filter.
+	Params Params `json:"params,omitempty" yaml:"params,omitempty"`
+}
+
+// ResourceConfig is the configuration of a resource, see proxym.ResourceConfig.
+type ResourceConfig struct {
+	// Domain is the domain of the resource, it is normalized.
+	Domain string `json:"domain,omitempty" yaml:"domain,omitempty"`
+	// Patterns are the glob patterns of the domains, see proxym.WithDomainPattern.
+	Patterns []string `json:"patterns,omitempty" yaml:"patterns,omitempty"`
+	// IgnoreSubdomains matches the subdomains of the domain, by default true.
+	IgnoreSubdomains *bool `json:"ignore_subdomains,omitempty" yaml:"ignore_subdomains,omitempty"`
+	// ETLDPlusOne compares the domains by eTLD+1, see proxym.WithETLDPlusOne.
+	ETLDPlusOne bool `json:"etld_plus_one,omitempty" yaml:"etld_plus_one,omitempty"`
+	// Schemes scope the resource to the schemes, see proxym.WithSchemes.
+	Schemes []string `json:"schemes,omitempty" yaml:"schemes,omitempty"`
+	// Ports scope the resource to the ports, see proxym.WithPorts.
+	Ports []int `json:"ports,omitempty" yaml:"ports,omitempty"`
+	// Paths scope the resource to the path prefixes, see proxym.PathPrefixMatcher.
+	Paths []string `json:"paths,omitempty" yaml:"paths,omitempty"`
+	// Methods scope the resource to the methods, see proxym.MethodMatcher.
+	Methods []string `json:"methods,omitempty" yaml:"methods,omitempty"`
+	// MatchPriority is the match priority of the resource, see proxym.WithMatchPriority.
+	MatchPriority int `json:"match_priority,omitempty" yaml:"match_priority,omitempty"`
+	// Share is the fair share of the resource, see proxym.WithResourceShare.
+	Share uint `json:"share,omitempty" yaml:"share,omitempty"`
+	// FallbackToGlobal falls back to the global pool, see proxym.WithResourceFallbackToGlobal.
+	FallbackToGlobal bool `json:"fallback_to_global,omitempty" yaml:"fallback_to_global,omitempty"`
+	// Proxies are the proxies of the resource.
+	Proxies []ProxyConfig `json:"proxies,omitempty" yaml:"proxies,omitempty"`
+	// Select is the select strategy of the resource, by default selects.DefaultSelectStrategy.
+	Select *StrategyConfig `json:"select,omitempty" yaml:"select,omitempty"`
+	// Rotation is the rotation strategy of the resource, by default rotations.DefaultRotationStrategy.
+	Rotation *StrategyConfig `json:"rotation,omitempty" yaml:"rotation,omitempty"`
+}
+
+// LoadFile reads and parses the configuration from the YAML or JSON file.
+func LoadFile(path string) (*Config, error) {
+	data, err := os.ReadFile(path) //nolint: gosec // the path of the configuration is chosen by the caller
+	if err != nil {
+		return nil, err
+	}
+	return Parse(data)
+}
+
+// Parse parses the configuration from the YAML or JSON document, JSON is parsed as YAML.
+//
+// The unknown fields are errors, so typos in the configuration are not silently ignored.
+func Parse(data []byte) (*Config, error) {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	cfg := &Config{}
+	if err := decoder.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidConfig, err)
+	}
+	return cfg, nil
+}
+
+// Build builds the ProxyManagerImpl by the configuration.
+//
+// The strategies and the filters are looked up by the names among the built-in ones
+// and the ones registered by WithSelect, WithRotation and WithFilter.
+func (c *Config) Build(opts ...Option) (*proxym.ProxyManagerImpl, error) {
+	r := newRegistry()
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	proxies, err := buildProxies(c.Proxies)
+	if err != nil {
+		return nil, err
+	}
+	selectFactory, err := r.buildSelect(c.Select)
+	if err != nil {
+		return nil, err
+	}
+	rotation, err := r.buildRotation(c.Rotation)
+	if err != nil {
+		return nil, err
+	}
+	eviction, err := evictionPolicy(c.Eviction)
+	if err != nil {
+		return nil, err
+	}
+
+	resources := make([]*proxym.ResourceConfig, 0, len(c.Resources))
+	for i := range c.Resources {
+		rc, err := c.Resources[i].build(r)
+		if err != nil {
+			return nil, fmt.Errorf("resource %d: %w", i, err)
+		}
+		resources = append(resources, rc)
+	}
+
+	managerOpts := []proxym.ProxyManagerImplOption{
+		proxym.WithProxies(proxies...),
+		proxym.WithSelectStrategy(selectFactory),
+		proxym.WithRotationStrategy(rotation),
+		proxym.WithResources(resources...),
+		proxym.WithMaxPool(c.MaxPool, eviction),
+		proxym.WithFairShare(c.FairShare),
+		proxym.WithPoolDebounce(c.PoolDebounce),
+		proxym.WithStatsHalfLife(c.StatsHalfLife),
+		proxym.WithDisablePolicy(proxym.DisablePolicy{
+			Threshold: c.DisableAfter,
+			Backoff:   proxym.Cooldown{Base: c.DisableBackoff, Max: c.DisableMaxBackoff},
+		}),
+	}
+	return proxym.NewProxyManager(append(managerOpts, r.managerOpts...)...), nil
+}
+
+// build builds the proxym.ResourceConfig by the configuration.
+func (c *ResourceConfig) build(r *registry) (*proxym.ResourceConfig, error) {
+	if c.Domain == "" && len(c.Patterns) == 0 && len(c.Schemes) == 0 && len(c.Ports) == 0 &&
+		len(c.Paths) == 0 && len(c.Methods) == 0 {
+		return nil, fmt.Errorf("%w: the resource has no domain, patterns or matchers", ErrInvalidConfig)
+	}
+	proxies, err := buildProxies(c.Proxies)
+	if err != nil {
+		return nil, err
+	}
+	selectFactory, err := r.buildSelect(c.Select)
+	if err != nil {
+		return nil, err
+	}
+	rotation, err := r.buildRotation(c.Rotation)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := []proxym.ResourceConfigOption{
+		proxym.WithDomain(c.Domain),
+		proxym.WithResourceProxies(proxies...),
+		proxym.WithResourceSelectStrategy(selectFactory),
+		proxym.WithResourceRotationStrategy(rotation),
+		proxym.WithETLDPlusOne(c.ETLDPlusOne),
+		proxym.WithMatchPriority(c.MatchPriority),
+		proxym.WithResourceFallbackToGlobal(c.FallbackToGlobal),
+	}
+	if len(c.Patterns) != 0 {
+		opts = append(opts, proxym.WithDomainPattern(c.Patterns...))
+	}
+	if c.IgnoreSubdomains != nil {
+		opts = append(opts, proxym.WithIgnoreSubdomains(*c.IgnoreSubdomains))
+	}
+	if len(c.Schemes) != 0 {
+		opts = append(opts, proxym.WithSchemes(c.Schemes...))
+	}
+	if len(c.Ports) != 0 {
+		opts = append(opts, proxym.WithPorts(c.Ports...))
+	}
+	if len(c.Paths) != 0 {
+		opts = append(opts, proxym.WithResourceMatcher(proxym.PathPrefixMatcher(c.Paths...)))
+	}
+	if len(c.Methods) != 0 {
+		opts = append(opts, proxym.WithResourceMatcher(proxym.MethodMatcher(c.Methods...)))
+	}
+	if c.Share != 0 {
+		opts = append(opts, proxym.WithResourceShare(c.Share))
+	}
+	return proxym.NewResourceConfig(true, opts...), nil
+}
+
+// buildProxies builds the proxies by the configurations.
+func buildProxies(configs []ProxyConfig) ([]*proxym.Proxy, error) {
+	proxies := make([]*proxym.Proxy, 0, len(configs))
+	for i := range configs {
+		proxy, err := configs[i].build()
+		if err != nil {
+			return nil, fmt.Errorf("proxy %d: %w", i, err)
+		}
+		proxies = append(proxies, proxy)
+	}
+	return proxies, nil
+}
+
+// build builds the proxy by the configuration.
+func (c *ProxyConfig) build() (*proxym.Proxy, error) {
+	if c.URL == directURL {
+		return proxym.NewDirectConnection(), nil
+	}
+	if c.URL == "" {
+		return nil, fmt.Errorf("%w: the proxy has no url", ErrInvalidConfig)
+	}
+
+	meta := proxym.NewProxyMetadata(c.Country, proxym.ProxyPriority(c.Priority), c.ExpiresAt)
+	meta.SetWeight(c.Weight)
+	meta.SetTimeout(c.Timeout)
+	meta.AddTag(c.Tags...)
+	for name, value := range c.Headers {
+		if err := meta.SetHeader(name, value); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidConfig, err)
+		}
+	}
+	proxy, err := proxym.NewProxyParsedStr(c.URL, meta)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidConfig, errors.Unwrap(err))
+	}
+	return proxy, nil
+}
+
+// evictionPolicy returns the eviction policy by the name.
+func evictionPolicy(name string) (proxym.EvictionPolicy, error) {
+	switch name {
+	case "", "oldest_last_used":
+		return proxym.EvictOldestLastUsed, nil
+	case "worst_success_rate":
+		return proxym.EvictWorstSuccessRate, nil
+	default:
+		return 0, fmt.Errorf("%w: unknown eviction policy %q", ErrInvalidConfig, name)
+	}
+}
@@ -0,0 +1,88 @@
+package config_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/nezbut/proxym/config"
+)
+
+const document = `
+proxies:
+  - url: http://proxy1:8080
+    country: DE
+    timeout: 10s
+    tags: [residential]
+  - url: direct
+select:
+  name: round_robin
+  filters:
+    - name: remove_disabled
+    - name: country
+      params: {allowed: [DE, FR]}
+rotation:
+  name: any
+  strategies:
+    - name: only_enabled
+    - name: error_threshold
+      params: {threshold: 3}
+resources:
+  - domain: api.example.com
+    ports: [443]
+    proxies:
+      - url: http://proxy3:8080
+max_pool: 10
+`
+
+func TestParseYAMLRoundTrip(t *testing.T) {
+	cfg, err := config.Parse([]byte(document))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := yaml.Marshal(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, err := config.Parse(data)
+	if err != nil {
+		t.Fatalf("got error %v parsing the marshaled configuration:\n%s", err, data)
+	}
+	if !reflect.DeepEqual(cfg, again) {
+		t.Errorf("got %+v after the round trip, want %+v", again, cfg)
+	}
+
+	pm, err := again.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	proxies := pm.GetProxies()
+	if len(proxies) != 2 {
+		t.Fatalf("got %d proxies of the global pool, want 2", len(proxies))
+	}
+	meta := proxies[0].Metadata()
+	if proxies[0].URL().String() != "http://proxy1:8080" || meta.Country() != "DE" || meta.Timeout() != 10*time.Second {
+		t.Errorf("got proxy %s of %q with timeout %s, want http://proxy1:8080 of DE with 10s",
+			proxies[0].URL(), meta.Country(), meta.Timeout())
+	}
+	if !proxies[1].IsDirect() {
+		t.Errorf("got %s, want the direct connection", proxies[1])
+	}
+	resource, err := pm.GetResource("api.example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := len(resource.GetProxies()); got != 1 {
+		t.Errorf("got %d proxies of the resource, want 1", got)
+	}
+}
+
+func TestParseUnknownField(t *testing.T) {
+	_, err := config.Parse([]byte("proxies:\n  - url: http://proxy1:8080\n    contry: DE\n"))
+	if !errors.Is(err, config.ErrInvalidConfig) {
+		t.Errorf("got error %v for the misspelled field, want ErrInvalidConfig", err)
+	}
+}
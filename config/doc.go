@@ -0,0 +1,29 @@
+// Package config provides building of the proxym.ProxyManagerImpl from the YAML or JSON configuration.
+//
+// The configuration describes the proxies, the resources and the select and rotation strategies
+// with their filters by the names, for example:
+//
+//	proxies:
+//	  - url: http://proxy1:8080
+//	    country: DE
+//	    tags: [residential]
+//	  - url: direct
+//	select:
+//	  name: round_robin
+//	  filters:
+//	    - name: remove_disabled
+//	    - name: country
+//	      params: {allowed: [DE, FR]}
+//	rotation:
+//	  name: any
+//	  strategies:
+//	    - name: only_enabled
+//	    - name: error_threshold
+//	      params: {threshold: 3}
+//	resources:
+//	  - domain: api.example.com
+//	    proxies:
+//	      - url: http://proxy3:8080
+//
+// The custom strategies and filters are registered by WithSelect, WithRotation and WithFilter.
+package config
@@ -0,0 +1,149 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// Params are the parameters of a strategy or a filter from the configuration.
+//
+// The getters return the default value if the parameter is not set
+// and ErrInvalidParam if it has the wrong type.
+type Params map[string]any
+
+// Int returns the integer parameter.
+func (p Params) Int(key string, def int) (int, error) {
+	v, ok := p[key]
+	if !ok {
+		return def, nil
+	}
+	switch n := v.(type) {
+	case int:
+		return n, nil
+	case float64:
+		if n == float64(int(n)) {
+			return int(n), nil
+		}
+	}
+	return 0, invalidParam(key, "an integer", v)
+}
+
+// Uint returns the non-negative integer parameter.
+func (p Params) Uint(key string, def uint) (uint, error) {
+	n, err := p.Int(key, int(def)) //nolint: gosec // the default is a small configuration value
+	if err != nil {
+		return 0, err
+	}
+	if n < 0 {
+		return 0, invalidParam(key, "a non-negative integer", n)
+	}
+	return uint(n), nil
+}
+
+// Float returns the number parameter.
+func (p Params) Float(key string, def float64) (float64, error) {
+	v, ok := p[key]
+	if !ok {
+		return def, nil
+	}
+	switch n := v.(type) {
+	case int:
+		return float64(n), nil
+	case float64:
+		return n, nil
+	}
+	return 0, invalidParam(key, "a number", v)
+}
+
+// Bool returns the boolean parameter.
+func (p Params) Bool(key string, def bool) (bool, error) {
+	v, ok := p[key]
+	if !ok {
+		return def, nil
+	}
+	b, ok := v.(bool)
+	if !ok {
+		return false, invalidParam(key, "a boolean", v)
+	}
+	return b, nil
+}
+
+// String returns the string parameter.
+func (p Params) String(key, def string) (string, error) {
+	v, ok := p[key]
+	if !ok {
+		return def, nil
+	}
+	s, ok := v.(string)
+	if !ok {
+		return "", invalidParam(key, "a string", v)
+	}
+	return s, nil
+}
+
+// Duration returns the duration parameter written like "1m30s", see time.ParseDuration.
+func (p Params) Duration(key string, def time.Duration) (time.Duration, error) {
+	v, ok := p[key]
+	if !ok {
+		return def, nil
+	}
+	s, ok := v.(string)
+	if !ok {
+		return 0, invalidParam(key, "a duration", v)
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, invalidParam(key, "a duration", v)
+	}
+	return d, nil
+}
+
+// Strings returns the list of strings parameter, a single string is a list of one string.
+func (p Params) Strings(key string) ([]string, error) {
+	v, ok := p[key]
+	if !ok {
+		return nil, nil
+	}
+	if s, ok := v.(string); ok {
+		return []string{s}, nil
+	}
+	list, ok := v.([]any)
+	if !ok {
+		return nil, invalidParam(key, "a list of strings", v)
+	}
+	result := make([]string, 0, len(list))
+	for _, item := range list {
+		s, ok := item.(string)
+		if !ok {
+			return nil, invalidParam(key, "a list of strings", v)
+		}
+		result = append(result, s)
+	}
+	return result, nil
+}
+
+// Ints returns the list of integers parameter, a single integer is a list of one integer.
+func (p Params) Ints(key string) ([]int, error) {
+	v, ok := p[key]
+	if !ok {
+		return nil, nil
+	}
+	list, ok := v.([]any)
+	if !ok {
+		list = []any{v}
+	}
+	result := make([]int, 0, len(list))
+	for i := range list {
+		n, err := Params{key: list[i]}.Int(key, 0)
+		if err != nil {
+			return nil, invalidParam(key, "a list of integers", v)
+		}
+		result = append(result, n)
+	}
+	return result, nil
+}
+
+// invalidParam returns ErrInvalidParam for the parameter of the wrong type.
+func invalidParam(key, want string, got any) error {
+	return fmt.Errorf("%w: %q must be %s, got %v", ErrInvalidParam, key, want, got)
+}
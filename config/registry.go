@@ -0,0 +1,457 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/nezbut/proxym"
+	"github.com/nezbut/proxym/rotations"
+	"github.com/nezbut/proxym/selects"
+)
+
+// SelectBuilder builds the select strategy factory from the params
+// and the factories of the nested strategies, see StrategyConfig.Strategies.
+type SelectBuilder func(params Params, inner []proxym.SelectStrategyFactory) (proxym.SelectStrategyFactory, error)
+
+// RotationBuilder builds the rotation strategy from the params
+// and the nested strategies, see StrategyConfig.Strategies.
+type RotationBuilder func(params Params, inner []proxym.RotationStrategy) (proxym.RotationStrategy, error)
+
+// FilterBuilder builds the select filter from the params.
+type FilterBuilder func(params Params) (selects.SelectFilter, error)
+
+// registry is the registry of the named strategies and filters.
+type registry struct {
+	selects     map[string]SelectBuilder
+	rotations   map[string]RotationBuilder
+	filters     map[string]FilterBuilder
+	managerOpts []proxym.ProxyManagerImplOption
+}
+
+// newRegistry returns a new registry with the built-in strategies and filters.
+func newRegistry() *registry {
+	return &registry{
+		selects:   builtinSelects(),
+		rotations: builtinRotations(),
+		filters:   builtinFilters(),
+	}
+}
+
+// Option is option for Config.Build.
+type Option func(*registry)
+
+// WithSelect registers the select strategy by the name, it replaces the built-in strategy with the same name.
+func WithSelect(name string, builder SelectBuilder) Option {
+	return func(r *registry) {
+		r.selects[name] = builder
+	}
+}
+
+// WithRotation registers the rotation strategy by the name, it replaces the built-in strategy with the same name.
+func WithRotation(name string, builder RotationBuilder) Option {
+	return func(r *registry) {
+		r.rotations[name] = builder
+	}
+}
+
+// WithFilter registers the select filter by the name, it replaces the built-in filter with the same name.
+func WithFilter(name string, builder FilterBuilder) Option {
+	return func(r *registry) {
+		r.filters[name] = builder
+	}
+}
+
+// WithManagerOptions adds the options to the ProxyManagerImpl, for example proxym.WithHealthScore.
+//
+// They are applied after the options from the configuration.
+func WithManagerOptions(opts ...proxym.ProxyManagerImplOption) Option {
+	return func(r *registry) {
+		r.managerOpts = append(r.managerOpts, opts...)
+	}
+}
+
+// buildSelect returns the select strategy factory by the configuration.
+func (r *registry) buildSelect(cfg *StrategyConfig) (proxym.SelectStrategyFactory, error) {
+	if cfg == nil {
+		return selects.DefaultSelectStrategy(), nil
+	}
+	builder, ok := r.selects[cfg.Name]
+	if !ok {
+		return nil, fmt.Errorf("%w: select %q", ErrUnknownStrategy, cfg.Name)
+	}
+	inner := make([]proxym.SelectStrategyFactory, 0, len(cfg.Strategies))
+	for i := range cfg.Strategies {
+		factory, err := r.buildSelect(&cfg.Strategies[i])
+		if err != nil {
+			return nil, err
+		}
+		inner = append(inner, factory)
+	}
+	factory, err := builder(cfg.Params, inner)
+	if err != nil {
+		return nil, fmt.Errorf("select %q: %w", cfg.Name, err)
+	}
+	if len(cfg.Filters) == 0 {
+		return factory, nil
+	}
+	filters := make([]selects.SelectFilter, 0, len(cfg.Filters))
+	for _, filterCfg := range cfg.Filters {
+		filter, err := r.buildFilter(filterCfg)
+		if err != nil {
+			return nil, err
+		}
+		filters = append(filters, filter)
+	}
+	return selects.NewFilteredSelectFactory(factory, filters...), nil
+}
+
+// buildRotation returns the rotation strategy by the configuration.
+func (r *registry) buildRotation(cfg *StrategyConfig) (proxym.RotationStrategy, error) {
+	if cfg == nil {
+		return rotations.DefaultRotationStrategy(), nil
+	}
+	builder, ok := r.rotations[cfg.Name]
+	if !ok {
+		return nil, fmt.Errorf("%w: rotation %q", ErrUnknownStrategy, cfg.Name)
+	}
+	if len(cfg.Filters) != 0 {
+		return nil, fmt.Errorf("%w: rotation %q has filters", ErrInvalidConfig, cfg.Name)
+	}
+	inner := make([]proxym.RotationStrategy, 0, len(cfg.Strategies))
+	for i := range cfg.Strategies {
+		strategy, err := r.buildRotation(&cfg.Strategies[i])
+		if err != nil {
+			return nil, err
+		}
+		inner = append(inner, strategy)
+	}
+	strategy, err := builder(cfg.Params, inner)
+	if err != nil {
+		return nil, fmt.Errorf("rotation %q: %w", cfg.Name, err)
+	}
+	return strategy, nil
+}
+
+// buildFilter returns the select filter by the configuration.
+func (r *registry) buildFilter(cfg FilterConfig) (selects.SelectFilter, error) {
+	builder, ok := r.filters[cfg.Name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownFilter, cfg.Name)
+	}
+	filter, err := builder(cfg.Params)
+	if err != nil {
+		return nil, fmt.Errorf("filter %q: %w", cfg.Name, err)
+	}
+	return filter, nil
+}
+
+// noInner returns ErrInvalidConfig if the strategy has the nested strategies.
+func noInner[T any](inner []T) error {
+	if len(inner) != 0 {
+		return fmt.Errorf("%w: the strategy has no nested strategies", ErrInvalidConfig)
+	}
+	return nil
+}
+
+// optionalInner returns the only nested strategy or nil if there are none.
+func optionalInner[T any](inner []T) (T, error) {
+	var zero T
+	switch len(inner) {
+	case 0:
+		return zero, nil
+	case 1:
+		return inner[0], nil
+	default:
+		return zero, fmt.Errorf("%w: the strategy has at most one nested strategy", ErrInvalidConfig)
+	}
+}
+
+// plainSelect returns the SelectBuilder of the select strategy without the params.
+func plainSelect(factory proxym.SelectStrategyFactory) SelectBuilder {
+	return func(_ Params, inner []proxym.SelectStrategyFactory) (proxym.SelectStrategyFactory, error) {
+		return factory, noInner(inner)
+	}
+}
+
+// builtinSelects returns the built-in select strategies.
+func builtinSelects() map[string]SelectBuilder {
+	return map[string]SelectBuilder{
+		"default": func(_ Params, inner []proxym.SelectStrategyFactory) (proxym.SelectStrategyFactory, error) {
+			return selects.DefaultSelectStrategy(), noInner(inner)
+		},
+		"random":            plainSelect(selects.NewRandomSelect),
+		"round_robin":       plainSelect(selects.NewRoundRobinSelect),
+		"least_connections": plainSelect(selects.NewLeastConnectionsSelect),
+		"consistent_hash":   plainSelect(selects.NewConsistentHashSelect),
+		"expiry_soonest":    plainSelect(selects.NewExpirySoonestSelect),
+		"direct":            plainSelect(selects.NewDirectSelect),
+		"weighted_round_robin": func(p Params, inner []proxym.SelectStrategyFactory) (proxym.SelectStrategyFactory, error) {
+			var warmup selects.Warmup
+			var err error
+			if warmup.Duration, err = p.Duration("warmup_duration", 0); err != nil {
+				return nil, err
+			}
+			if warmup.Requests, err = p.Uint("warmup_requests", 0); err != nil {
+				return nil, err
+			}
+			if warmup.MinFactor, err = p.Float("warmup_min_factor", 0); err != nil {
+				return nil, err
+			}
+			return selects.NewWeightedRoundRobinSelectFactory(warmup), noInner(inner)
+		},
+		"fastest": func(p Params, inner []proxym.SelectStrategyFactory) (proxym.SelectStrategyFactory, error) {
+			exploration, err := p.Float("exploration", 0.1) //nolint: mnd // the default exploration of FastestSelect
+			if err != nil {
+				return nil, err
+			}
+			source, err := p.String("source", "traffic")
+			if err != nil {
+				return nil, err
+			}
+			switch source {
+			case "traffic":
+				return selects.NewFastestSelectFactory(exploration, nil), noInner(inner)
+			case "probe":
+				return selects.NewProbedFastestSelectFactory(exploration, nil), noInner(inner)
+			default:
+				return nil, invalidParam("source", `"traffic" or "probe"`, source)
+			}
+		},
+		"epsilon_greedy": func(p Params, inner []proxym.SelectStrategyFactory) (proxym.SelectStrategyFactory, error) {
+			epsilon, err := p.Float("epsilon", 0.1) //nolint: mnd // the default epsilon of EpsilonGreedySelect
+			if err != nil {
+				return nil, err
+			}
+			return selects.NewEpsilonGreedySelectFactory(epsilon, nil), noInner(inner)
+		},
+		"least_errors": func(p Params, inner []proxym.SelectStrategyFactory) (proxym.SelectStrategyFactory, error) {
+			metric, err := p.String("metric", "count")
+			if err != nil {
+				return nil, err
+			}
+			switch metric {
+			case "count":
+				return selects.NewLeastErrorsSelectFactory(selects.ErrorMetricCount, nil), noInner(inner)
+			case "ratio":
+				return selects.NewLeastErrorsSelectFactory(selects.ErrorMetricRatio, nil), noInner(inner)
+			case "recent_ratio":
+				window, err := p.Duration("window", 0)
+				if err != nil {
+					return nil, err
+				}
+				requests, err := p.Uint("requests", 0)
+				if err != nil {
+					return nil, err
+				}
+				return selects.NewRecentLeastErrorsSelectFactory(window, requests, nil), noInner(inner)
+			case "decayed_ratio":
+				return selects.NewLeastErrorsSelectFactory(selects.ErrorMetricDecayedRatio, nil), noInner(inner)
+			default:
+				return nil, invalidParam("metric", `"count", "ratio", "recent_ratio" or "decayed_ratio"`, metric)
+			}
+		},
+		"priority": func(_ Params, inner []proxym.SelectStrategyFactory) (proxym.SelectStrategyFactory, error) {
+			tier, err := optionalInner(inner)
+			if err != nil {
+				return nil, err
+			}
+			if tier == nil {
+				tier = selects.NewRandomSelect
+			}
+			return selects.NewPrioritySelectFactory(tier), nil
+		},
+		"sticky": func(p Params, inner []proxym.SelectStrategyFactory) (proxym.SelectStrategyFactory, error) {
+			ttl, err := p.Duration("ttl", 0)
+			if err != nil {
+				return nil, err
+			}
+			strategy, err := optionalInner(inner)
+			if err != nil {
+				return nil, err
+			}
+			return selects.NewStickySelectFactory(ttl, strategy), nil
+		},
+		"direct_fallback": func(_ Params, inner []proxym.SelectStrategyFactory) (proxym.SelectStrategyFactory, error) {
+			strategy, err := optionalInner(inner)
+			if err != nil {
+				return nil, err
+			}
+			if strategy == nil {
+				strategy = selects.DefaultSelectStrategy()
+			}
+			return selects.NewDirectFallbackSelectFactory(strategy), nil
+		},
+		"fallback": func(_ Params, inner []proxym.SelectStrategyFactory) (proxym.SelectStrategyFactory, error) {
+			if len(inner) == 0 {
+				return nil, fmt.Errorf("%w: the strategy needs at least one nested strategy", ErrInvalidConfig)
+			}
+			return selects.NewFallbackSelectFactory(inner[0], inner[1:]...), nil
+		},
+	}
+}
+
+// builtinRotations returns the built-in rotation strategies.
+func builtinRotations() map[string]RotationBuilder {
+	return map[string]RotationBuilder{
+		"default": func(_ Params, inner []proxym.RotationStrategy) (proxym.RotationStrategy, error) {
+			return rotations.DefaultRotationStrategy(), noInner(inner)
+		},
+		"only_enabled": func(_ Params, inner []proxym.RotationStrategy) (proxym.RotationStrategy, error) {
+			return rotations.OnlyEnabledRotation{}, noInner(inner)
+		},
+		"expired": func(_ Params, inner []proxym.RotationStrategy) (proxym.RotationStrategy, error) {
+			return rotations.NewExpiredRotation(), noInner(inner)
+		},
+		"any": func(_ Params, inner []proxym.RotationStrategy) (proxym.RotationStrategy, error) {
+			return rotations.NewCompositeRotationStrategy(rotations.RotationLogicOR, inner...), nil
+		},
+		"all": func(_ Params, inner []proxym.RotationStrategy) (proxym.RotationStrategy, error) {
+			return rotations.NewCompositeRotationStrategy(rotations.RotationLogicAND, inner...), nil
+		},
+		"error_threshold": func(p Params, inner []proxym.RotationStrategy) (proxym.RotationStrategy, error) {
+			threshold, err := p.Uint("threshold", 1)
+			if err != nil {
+				return nil, err
+			}
+			return rotations.NewErrorThresholdRotation(threshold), noInner(inner)
+		},
+		"decayed_error_threshold": func(p Params, inner []proxym.RotationStrategy) (proxym.RotationStrategy, error) {
+			threshold, err := p.Float("threshold", 1)
+			if err != nil {
+				return nil, err
+			}
+			return rotations.NewDecayedErrorThresholdRotation(threshold), noInner(inner)
+		},
+		"request_limited": func(p Params, inner []proxym.RotationStrategy) (proxym.RotationStrategy, error) {
+			limit, err := p.Uint("limit", 1)
+			if err != nil {
+				return nil, err
+			}
+			return rotations.NewRequestLimitedRotation(limit), noInner(inner)
+		},
+		"sticky_round_robin": func(p Params, inner []proxym.RotationStrategy) (proxym.RotationStrategy, error) {
+			n, err := p.Uint("requests", 1)
+			if err != nil {
+				return nil, err
+			}
+			return rotations.NewStickyRoundRobinRotation(n), noInner(inner)
+		},
+		"status_code": func(p Params, inner []proxym.RotationStrategy) (proxym.RotationStrategy, error) {
+			codes, err := p.Ints("codes")
+			if err != nil {
+				return nil, err
+			}
+			return rotations.NewStatusCodeRotation(codes...), noInner(inner)
+		},
+		"bandwidth_quota": func(p Params, inner []proxym.RotationStrategy) (proxym.RotationStrategy, error) {
+			quota, err := p.Uint("quota", 0)
+			if err != nil {
+				return nil, err
+			}
+			return rotations.NewBandwidthQuotaRotation(uint64(quota)), noInner(inner)
+		},
+		"jittered_ttl": func(p Params, inner []proxym.RotationStrategy) (proxym.RotationStrategy, error) {
+			minTTL, err := p.Duration("min", 0)
+			if err != nil {
+				return nil, err
+			}
+			maxTTL, err := p.Duration("max", minTTL)
+			if err != nil {
+				return nil, err
+			}
+			return rotations.NewJitteredTTLRotation(minTTL, maxTTL), noInner(inner)
+		},
+		"error_rate": func(p Params, inner []proxym.RotationStrategy) (proxym.RotationStrategy, error) {
+			threshold, err := p.Float("threshold", 0)
+			if err != nil {
+				return nil, err
+			}
+			window, err := p.Duration("window", 0)
+			if err != nil {
+				return nil, err
+			}
+			minRequests, err := p.Uint("min_requests", 0)
+			if err != nil {
+				return nil, err
+			}
+			return rotations.NewErrorRateRotation(threshold, window, minRequests), noInner(inner)
+		},
+		"recent_error_rate": func(p Params, inner []proxym.RotationStrategy) (proxym.RotationStrategy, error) {
+			threshold, err := p.Float("threshold", 0)
+			if err != nil {
+				return nil, err
+			}
+			requests, err := p.Uint("requests", 0)
+			if err != nil {
+				return nil, err
+			}
+			minRequests, err := p.Uint("min_requests", 0)
+			if err != nil {
+				return nil, err
+			}
+			return rotations.NewRecentErrorRateRotation(threshold, requests, minRequests), noInner(inner)
+		},
+	}
+}
+
+// builtinFilters returns the built-in select filters.
+func builtinFilters() map[string]FilterBuilder {
+	return map[string]FilterBuilder{
+		"remove_active": func(Params) (selects.SelectFilter, error) {
+			return selects.RemoveActiveProxyFilter{}, nil
+		},
+		"remove_disabled": func(Params) (selects.SelectFilter, error) {
+			return selects.RemoveDisabledFilter{}, nil
+		},
+		"remove_draining": func(Params) (selects.SelectFilter, error) {
+			return selects.RemoveDrainingFilter{}, nil
+		},
+		"remove_quarantined": func(Params) (selects.SelectFilter, error) {
+			return selects.RemoveQuarantinedFilter{}, nil
+		},
+		"remove_rate_limited": func(Params) (selects.SelectFilter, error) {
+			return selects.RemoveRateLimitedFilter{}, nil
+		},
+		"remove_expired": func(Params) (selects.SelectFilter, error) {
+			return selects.NewRemoveExpiredFilter(), nil
+		},
+		"remove_overloaded": func(p Params) (selects.SelectFilter, error) {
+			threshold, err := p.Int("threshold", 1)
+			if err != nil {
+				return nil, err
+			}
+			return selects.NewRemoveOverloadedFilter(threshold), nil
+		},
+		"max_concurrency": func(p Params) (selects.SelectFilter, error) {
+			limit, err := p.Int("limit", 1)
+			if err != nil {
+				return nil, err
+			}
+			return selects.NewMaxConcurrencyFilter(limit), nil
+		},
+		"country": func(p Params) (selects.SelectFilter, error) {
+			allowed, err := p.Strings("allowed")
+			if err != nil {
+				return nil, err
+			}
+			return selects.CountryFilter{Allowed: allowed}, nil
+		},
+		"country_deny": func(p Params) (selects.SelectFilter, error) {
+			denied, err := p.Strings("denied")
+			if err != nil {
+				return nil, err
+			}
+			return selects.CountryDenyFilter{Denied: denied}, nil
+		},
+		"tag": func(p Params) (selects.SelectFilter, error) {
+			tags, err := p.Strings("tags")
+			if err != nil {
+				return nil, err
+			}
+			matchAny, err := p.Bool("match_any", false)
+			if err != nil {
+				return nil, err
+			}
+			return selects.TagFilter{Tags: tags, MatchAny: matchAny}, nil
+		},
+	}
+}
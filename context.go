@@ -0,0 +1,42 @@
+package proxym
+
+import "context"
+
+// proxyContextKey is the context key of the proxy selected for a request.
+type proxyContextKey struct{}
+
+// ContextWithProxy returns a copy of the context with the proxy selected for a request.
+//
+// ProxyTransport stores the selected proxy in the request context,
+// and the ProxySelector uses the proxy from the context instead of selecting a new one.
+func ContextWithProxy(ctx context.Context, proxy *Proxy) context.Context {
+	return context.WithValue(ctx, proxyContextKey{}, proxy)
+}
+
+// ProxyFromContext returns the proxy selected for a request from the context.
+//
+// It can be used with the context of http.Response.Request to attribute a response to the proxy that served it.
+// It returns nil if the context has no proxy.
+func ProxyFromContext(ctx context.Context) *Proxy {
+	proxy, _ := ctx.Value(proxyContextKey{}).(*Proxy)
+	return proxy
+}
+
+// sessionKeyContextKey is the context key of the session key of a request.
+type sessionKeyContextKey struct{}
+
+// ContextWithSessionKey returns a copy of the context with the session key of a request.
+//
+// The session key is used by the select strategies that pin a proxy per session, like selects.StickySelect,
+// pass the context to ProxyManagerImpl.GetNextProxyContext or to the request of ProxyTransport.
+func ContextWithSessionKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, sessionKeyContextKey{}, key)
+}
+
+// SessionKeyFromContext returns the session key of a request from the context.
+//
+// It returns false if the context has no session key.
+func SessionKeyFromContext(ctx context.Context) (string, bool) {
+	key, ok := ctx.Value(sessionKeyContextKey{}).(string)
+	return key, ok
+}
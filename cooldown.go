@@ -0,0 +1,66 @@
+package proxym
+
+import (
+	"math"
+	"time"
+)
+
+// Cooldown is a policy of the temporary disabling of a failing proxy with the exponential backoff,
+// see Proxy.Cooldown.
+//
+// The n-th consecutive cooldown of a proxy lasts Base * 2^(n-1) but not longer than Max,
+// if Max is zero, the duration is not limited.
+type Cooldown struct {
+	Base time.Duration
+	Max  time.Duration
+}
+
+// Duration returns the duration of the n-th consecutive cooldown, it returns zero if n is zero.
+func (c Cooldown) Duration(n uint) time.Duration {
+	if n == 0 || c.Base <= 0 {
+		return 0
+	}
+	d := c.Base
+	for i := uint(1); i < n && (c.Max <= 0 || d < c.Max) && d <= math.MaxInt64/2; i++ {
+		d *= 2
+	}
+	if c.Max > 0 {
+		d = min(d, c.Max)
+	}
+	return d
+}
+
+// DisablePolicy is a policy that disables a failing proxy automatically: when the consecutive errors
+// of the proxy reach Threshold, it is disabled for the next cooldown by Backoff, see Proxy.Cooldown,
+// and it is enabled again when the cooldown is over.
+//
+// The consecutive errors are not reset by the end of the cooldown, so if the first request after it fails,
+// the proxy is disabled again for the doubled duration up to Backoff.Max, until a successful response
+// resets both the consecutive errors and the backoff.
+type DisablePolicy struct {
+	// Threshold is the number of the consecutive errors that disables the proxy, zero turns the policy off.
+	Threshold uint
+	// Backoff is the policy of the durations of the consecutive cooldowns.
+	Backoff Cooldown
+}
+
+// Apply disables the proxy for the next cooldown if its consecutive errors reach the threshold
+// and it is not disabled yet. It returns the duration of the cooldown, zero if the proxy was not disabled.
+func (d DisablePolicy) Apply(proxy *Proxy) time.Duration {
+	if d.Threshold == 0 || d.Backoff.Base <= 0 {
+		return 0
+	}
+	if proxy.Stats().ConsecutiveErrors() < d.Threshold || proxy.IsDisabled() {
+		return 0
+	}
+	return proxy.Cooldown(d.Backoff)
+}
+
+// WithDisablePolicy sets the DisablePolicy of the ProxyManagerImpl, it is applied to the proxy
+// after every failed request recorded by ProxyTransport or ProxyLease.Release, so the pool is cleaned up
+// without WithFailureCooldown on each transport.
+func WithDisablePolicy(policy DisablePolicy) ProxyManagerImplOption {
+	return func(pm *ProxyManagerImpl) {
+		pm.disablePolicy = policy
+	}
+}
@@ -0,0 +1,29 @@
+package proxym
+
+import "sync/atomic"
+
+// SelectionCounters is a snapshot of the selection counters of the manager or of the resource.
+//
+// Selections is the number of proxies selected by the SelectStrategy, Rotations is the number of times
+// the last used proxy was rotated and Failures is the number of times no proxy was available.
+type SelectionCounters struct {
+	Selections uint64
+	Rotations  uint64
+	Failures   uint64
+}
+
+// selectionCounters is the thread-safe selection counters.
+type selectionCounters struct {
+	selections atomic.Uint64
+	rotations  atomic.Uint64
+	failures   atomic.Uint64
+}
+
+// snapshot returns the snapshot of the counters.
+func (c *selectionCounters) snapshot() SelectionCounters {
+	return SelectionCounters{
+		Selections: c.selections.Load(),
+		Rotations:  c.rotations.Load(),
+		Failures:   c.failures.Load(),
+	}
+}
@@ -0,0 +1,88 @@
+package proxym
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// Credentials are the credentials of a proxy issued by a CredentialProvider.
+type Credentials struct {
+	// Username is the username of the proxy.
+	Username string
+	// Password is the password of the proxy.
+	Password string
+	// ExpiresAt is the expiration time of the credentials, zero means they never expire.
+	ExpiresAt time.Time
+}
+
+// CredentialProvider returns the new credentials of the proxy,
+// for example a short-lived token issued by the API of the proxy vendor.
+type CredentialProvider func(ctx context.Context, proxy *Proxy) (Credentials, error)
+
+// SetCredentials sets the username and the password of the proxy url.
+//
+// The url is replaced by a copy, so the url returned by URL before is not changed.
+// It does nothing for a direct connection.
+func (p *Proxy) SetCredentials(username, password string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.url == nil {
+		return
+	}
+	u := *p.url
+	u.User = url.UserPassword(username, password)
+	p.url = &u
+}
+
+// SetCredentialsExpiresAt sets the expiration time of the credentials of the proxy,
+// zero means they never expire, see WithCredentialProvider.
+func (p *Proxy) SetCredentialsExpiresAt(expiresAt time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.credentialsExpiresAt = expiresAt
+}
+
+// CredentialsExpiresAt returns the expiration time of the credentials of the proxy.
+func (p *Proxy) CredentialsExpiresAt() time.Time {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.credentialsExpiresAt
+}
+
+// credentialRefresher refreshes the credentials of the selected proxies that are near expiry.
+type credentialRefresher struct {
+	provider CredentialProvider
+	before   time.Duration
+	mu       sync.Mutex
+}
+
+// needsRefresh returns true if the credentials of the proxy expire within the refresh window.
+func (r *credentialRefresher) needsRefresh(proxy *Proxy, now time.Time) bool {
+	expiresAt := proxy.CredentialsExpiresAt()
+	return !expiresAt.IsZero() && !now.Add(r.before).Before(expiresAt)
+}
+
+// refresh calls the provider if the credentials of the proxy are near expiry and sets the new credentials.
+//
+// The refreshes are serialized, so concurrent selections of the same proxy call the provider once.
+func (r *credentialRefresher) refresh(ctx context.Context, proxy *Proxy, clock Clock) error {
+	if proxy.IsDirect() || !r.needsRefresh(proxy, clock.Now()) {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.needsRefresh(proxy, clock.Now()) {
+		return nil
+	}
+
+	creds, err := r.provider(ctx, proxy)
+	if err != nil {
+		return fmt.Errorf("refresh credentials: %w", err)
+	}
+	proxy.SetCredentials(creds.Username, creds.Password)
+	proxy.SetCredentialsExpiresAt(creds.ExpiresAt)
+	return nil
+}
@@ -0,0 +1,109 @@
+package proxym
+
+import (
+	"math"
+	"time"
+)
+
+// DecayedStats are the success and error counts of the proxy that decay over time, see ProxyStats.Decayed.
+type DecayedStats struct {
+	Successes float64
+	Errors    float64
+}
+
+// ErrorRate returns the ratio of the decayed errors to the decayed requests.
+//
+// If there are no requests, it returns 0.
+func (d DecayedStats) ErrorRate() float64 {
+	if total := d.Successes + d.Errors; total > 0 {
+		return d.Errors / total
+	}
+	return 0
+}
+
+// SuccessRate returns the ratio of the decayed successes to the decayed requests.
+//
+// If there are no requests, it returns 1 like StatsSnapshot.SuccessRate.
+func (d DecayedStats) SuccessRate() float64 {
+	return 1 - d.ErrorRate()
+}
+
+// statsDecay is the exponentially decayed counts of the requests as of the time of the last request.
+type statsDecay struct {
+	halfLife  time.Duration
+	successes float64
+	errors    float64
+	at        time.Time
+}
+
+// record decays the counts to now and counts the request.
+func (d *statsDecay) record(now time.Time, failed bool) {
+	d.successes, d.errors = d.decayed(now)
+	d.at = now
+	if failed {
+		d.errors++
+	} else {
+		d.successes++
+	}
+}
+
+// decayed returns the counts decayed to now, without the half-life the counts do not decay.
+func (d *statsDecay) decayed(now time.Time) (float64, float64) {
+	if d.halfLife <= 0 || d.at.IsZero() || !now.After(d.at) {
+		return d.successes, d.errors
+	}
+	factor := math.Exp2(-float64(now.Sub(d.at)) / float64(d.halfLife))
+	return d.successes * factor, d.errors * factor
+}
+
+// SetHalfLife sets the half-life of the decayed counts of the proxy, see Decayed,
+// so a proxy that misbehaved long ago regains trust without a reset. Zero disables the decay.
+//
+// The counts recorded so far decay from now on with the new half-life.
+func (s *ProxyStats) SetHalfLife(halfLife time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := clockNow(s.clock)
+	s.decay.successes, s.decay.errors = s.decay.decayed(now)
+	s.decay.at = now
+	s.decay.halfLife = halfLife
+}
+
+// HalfLife returns the half-life of the decayed counts of the proxy, zero if the counts do not decay.
+func (s *ProxyStats) HalfLife() time.Duration {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.decay.halfLife
+}
+
+// Decayed returns the success and error counts of the proxy that decay over time by the half-life,
+// see SetHalfLife and WithStatsHalfLife, for example with the half-life of 1 hour an error counts as 0.5 after 1 hour
+// and as 0.25 after 2 hours. Without the half-life the counts are the same as SuccessCount and ErrorCount
+// since the first request.
+func (s *ProxyStats) Decayed() DecayedStats {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	successes, errors := s.decay.decayed(clockNow(s.clock))
+	return DecayedStats{Successes: successes, Errors: errors}
+}
+
+// WithStatsHalfLife sets the half-life of the decayed counts of the statistics of the proxies
+// of the ProxyManagerImpl, including the proxies of the resources and the proxies added later,
+// see ProxyStats.Decayed.
+func WithStatsHalfLife(halfLife time.Duration) ProxyManagerImplOption {
+	return func(pm *ProxyManagerImpl) {
+		pm.statsHalfLife = halfLife
+	}
+}
+
+// applyStatsHalfLife sets the half-life of the decayed counts to the proxies, see WithStatsHalfLife.
+func (pm *ProxyManagerImpl) applyStatsHalfLife(proxies []*Proxy) {
+	if pm.statsHalfLife <= 0 {
+		return
+	}
+	for _, p := range proxies {
+		if p.Stats().HalfLife() != pm.statsHalfLife {
+			p.Stats().SetHalfLife(pm.statsHalfLife)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package proxym
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+)
+
+// ErrDuplicateProxy is the error of a proxy whose normalized url is already in the pool, see RejectDuplicates.
+var ErrDuplicateProxy = errors.New("duplicate proxy")
+
+// DuplicatePolicy is a type for the policy of the proxies whose normalized url is already in the pool,
+// see WithDuplicatePolicy and NormalizeProxyURL.
+type DuplicatePolicy int
+
+// DuplicatePolicy constants.
+const (
+	// AllowDuplicates adds the duplicate proxies, so the same exit is selected more often.
+	AllowDuplicates DuplicatePolicy = iota
+	// SkipDuplicates silently skips the duplicate proxies.
+	SkipDuplicates
+	// RejectDuplicates rejects the added proxies with ErrDuplicateProxy if any of them is a duplicate.
+	RejectDuplicates
+)
+
+// defaultProxyPorts are the default ports of the proxy schemes.
+var defaultProxyPorts = map[string]string{
+	"http":    "80",
+	"https":   "443",
+	"socks4":  "1080",
+	"socks4a": "1080",
+	"socks5":  "1080",
+	"socks5h": "1080",
+}
+
+// NormalizeProxyURL returns the normalized url of the proxy by which the duplicates are detected:
+// the scheme and the host are lowercased, the default port of the scheme is added,
+// the path, the query and the fragment are dropped. The credentials are kept,
+// so the sessions of a gateway are different proxies, see SessionizedProxy.
+//
+// It returns the empty string for a direct connection.
+func NormalizeProxyURL(u *url.URL) string {
+	if u == nil {
+		return ""
+	}
+	scheme := strings.ToLower(u.Scheme)
+	port := u.Port()
+	if port == "" {
+		port = defaultProxyPorts[scheme]
+	}
+	host := strings.ToLower(u.Hostname())
+	if port != "" {
+		host = net.JoinHostPort(host, port)
+	}
+	normalized := &url.URL{Scheme: scheme, User: u.User, Host: host}
+	return normalized.String()
+}
+
+// dedupe returns the added proxies without the duplicates of the existing proxies and of each other by the policy.
+func (d DuplicatePolicy) dedupe(existing, added []*Proxy) ([]*Proxy, error) {
+	if d == AllowDuplicates {
+		return added, nil
+	}
+	seen := make(map[string]struct{}, len(existing)+len(added))
+	for _, p := range existing {
+		seen[NormalizeProxyURL(p.URL())] = struct{}{}
+	}
+
+	unique := make([]*Proxy, 0, len(added))
+	for _, p := range added {
+		key := NormalizeProxyURL(p.URL())
+		if _, ok := seen[key]; ok {
+			if d == RejectDuplicates {
+				return nil, fmt.Errorf("%w: %s", ErrDuplicateProxy, p.URL().Redacted())
+			}
+			continue
+		}
+		seen[key] = struct{}{}
+		unique = append(unique, p)
+	}
+	return unique, nil
+}
@@ -0,0 +1,256 @@
+package proxym
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Dialer dials the network connections through the proxies of the ProxyManagerImpl,
+// so the non-HTTP clients, for example SMTP, Redis or custom TCP protocols, use the same rotation logic.
+//
+// The proxy is checked out by the host of the address like AcquireContext, the connection is dialed
+// through the HTTP and HTTPS proxies with the CONNECT method and through the SOCKS5 proxies with SOCKS5Dialer,
+// the direct connection dials the address itself.
+type Dialer struct {
+	pm        *ProxyManagerImpl
+	forward   DialContextFunc
+	tlsConfig *tls.Config
+}
+
+// DialerOption is option for Dialer.
+type DialerOption func(*Dialer)
+
+// WithForwardDialer sets the function that dials the proxies and the addresses of the direct connection,
+// by default a net.Dialer is used.
+func WithForwardDialer(forward DialContextFunc) DialerOption {
+	return func(d *Dialer) {
+		d.forward = forward
+	}
+}
+
+// WithProxyTLSConfig sets the TLS configuration of the connections to the HTTPS proxies,
+// the server name is set to the host of the proxy if it is empty.
+func WithProxyTLSConfig(config *tls.Config) DialerOption {
+	return func(d *Dialer) {
+		d.tlsConfig = config
+	}
+}
+
+// NewDialer returns a new Dialer of the ProxyManagerImpl.
+func NewDialer(pm *ProxyManagerImpl, opts ...DialerOption) *Dialer {
+	d := &Dialer{pm: pm}
+	for _, opt := range opts {
+		opt(d)
+	}
+	if d.forward == nil {
+		d.forward = (&net.Dialer{}).DialContext
+	}
+	return d
+}
+
+// Dial dials the address on the network through the next available proxy, see DialContext.
+func (d *Dialer) Dial(network, addr string) (net.Conn, error) {
+	return d.DialContext(context.Background(), network, addr)
+}
+
+// DialContext dials the address on the network through the next available proxy by the host of the address.
+//
+// The returned connection is a *ProxyConn that holds the lease of the proxy until it is closed,
+// so it counts as an in-flight request through the proxy, see ProxyLease.
+// A failed dial is recorded as a failure of the proxy, the connection closed by Close as a success,
+// see ProxyConn.CloseWithError.
+//
+// If the proxy has a timeout, see ProxyManagerImpl.ProxyTimeout, it bounds the dial and the handshake with the proxy.
+// The proxies accept only the "tcp", "tcp4" and "tcp6" networks.
+func (d *Dialer) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+	lease, err := d.pm.AcquireContext(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+
+	dialCtx := ctx
+	if timeout := d.pm.ProxyTimeout(lease.Proxy()); timeout > 0 {
+		var cancel context.CancelFunc
+		dialCtx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	conn, err := d.dial(dialCtx, lease.Proxy(), network, addr)
+	if err != nil {
+		lease.Release(err)
+		return nil, err
+	}
+	return &ProxyConn{Conn: conn, lease: lease}, nil
+}
+
+// dial dials the address on the network through the proxy.
+func (d *Dialer) dial(ctx context.Context, proxy *Proxy, network, addr string) (net.Conn, error) {
+	u := proxy.URL()
+	if u == nil {
+		return d.forward(ctx, network, addr)
+	}
+	switch network {
+	case "tcp", "tcp4", "tcp6":
+	default:
+		return nil, fmt.Errorf("%w: %s through %s", ErrUnsupportedNetwork, network, u.Redacted())
+	}
+
+	switch strings.ToLower(u.Scheme) {
+	case "socks5", "socks5h":
+		dial, err := SOCKS5Dialer(u, d.forward)
+		if err != nil {
+			return nil, err
+		}
+		return dial(ctx, network, addr)
+	case "http", "https":
+		return d.connect(ctx, proxy, addr)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedProxyScheme, u.Redacted())
+	}
+}
+
+// connect dials the HTTP or HTTPS proxy and establishes the tunnel to the address with the CONNECT method.
+//
+// The request has the Proxy-Authorization header with the credentials of the proxy url
+// and the headers of the proxy metadata, see ProxyMetadata.Headers.
+func (d *Dialer) connect(ctx context.Context, proxy *Proxy, addr string) (net.Conn, error) {
+	u := proxy.URL()
+	scheme := strings.ToLower(u.Scheme)
+	port := u.Port()
+	if port == "" {
+		port = defaultProxyPorts[scheme]
+	}
+	conn, err := d.forward(ctx, "tcp", net.JoinHostPort(u.Hostname(), port))
+	if err != nil {
+		return nil, err
+	}
+
+	// The deadline of the context bounds the handshake, the cancellation interrupts it.
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+	stop := context.AfterFunc(ctx, func() {
+		_ = conn.SetDeadline(time.Unix(1, 0))
+	})
+	defer stop()
+
+	tunnel, err := d.handshake(ctx, conn, scheme, proxy, addr)
+	if err != nil {
+		conn.Close()
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+		return nil, err
+	}
+	if !stop() {
+		tunnel.Close()
+		return nil, ctx.Err()
+	}
+	_ = conn.SetDeadline(time.Time{})
+	return tunnel, nil
+}
+
+// handshake establishes the TLS connection to the HTTPS proxy and sends the CONNECT request.
+func (d *Dialer) handshake(
+	ctx context.Context, conn net.Conn, scheme string, proxy *Proxy, addr string,
+) (net.Conn, error) {
+	u := proxy.URL()
+	if scheme == "https" {
+		config := &tls.Config{MinVersion: tls.VersionTLS12}
+		if d.tlsConfig != nil {
+			config = d.tlsConfig.Clone()
+		}
+		if config.ServerName == "" {
+			config.ServerName = u.Hostname()
+		}
+		tlsConn := tls.Client(conn, config)
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			return nil, err
+		}
+		conn = tlsConn
+	}
+
+	header := proxy.Metadata().Headers()
+	if header == nil {
+		header = make(http.Header)
+	}
+	if u.User != nil {
+		password, _ := u.User.Password()
+		credentials := base64.StdEncoding.EncodeToString([]byte(u.User.Username() + ":" + password))
+		header.Set("Proxy-Authorization", "Basic "+credentials)
+	}
+	req := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Opaque: addr},
+		Host:   addr,
+		Header: header,
+	}
+	if err := req.Write(conn); err != nil {
+		return nil, err
+	}
+
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, req)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: %s by %s", ErrProxyConnectFailed, resp.Status, u.Redacted())
+	}
+	if br.Buffered() > 0 {
+		return &bufferedConn{Conn: conn, r: br}, nil
+	}
+	return conn, nil
+}
+
+// bufferedConn is a net.Conn that first reads the bytes buffered after the CONNECT response.
+type bufferedConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+// Read reads from the buffer, then from the connection.
+func (c *bufferedConn) Read(p []byte) (int, error) {
+	return c.r.Read(p)
+}
+
+// ProxyConn is a net.Conn dialed by Dialer through a proxy, it holds the lease of the proxy until it is closed.
+type ProxyConn struct {
+	net.Conn
+	lease *ProxyLease
+	once  sync.Once
+}
+
+// Proxy returns the proxy of the connection.
+func (c *ProxyConn) Proxy() *Proxy {
+	return c.lease.Proxy()
+}
+
+// Close closes the connection and releases the proxy with the success outcome.
+func (c *ProxyConn) Close() error {
+	return c.CloseWithError(nil)
+}
+
+// CloseWithError closes the connection and releases the proxy with the outcome of its use,
+// nil means success, any error means failure, see ProxyLease.Release. Only the first call has effect.
+func (c *ProxyConn) CloseWithError(result error) error {
+	var err error
+	c.once.Do(func() {
+		err = c.Conn.Close()
+		c.lease.Release(result)
+	})
+	return err
+}
@@ -0,0 +1,156 @@
+package proxym
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"net/url"
+
+	"golang.org/x/net/http/httpproxy"
+)
+
+// envProbeHost is the host by which the proxies of the environment are resolved.
+const envProbeHost = "proxym.invalid"
+
+// EnvironmentProxies are the proxies configured by the HTTP_PROXY, HTTPS_PROXY and NO_PROXY
+// environment variables or their lowercase versions, like http.ProxyFromEnvironment uses them.
+type EnvironmentProxies struct {
+	// HTTP is the proxy of the http requests, it is nil if HTTP_PROXY is not set.
+	HTTP *Proxy
+	// HTTPS is the proxy of the https requests, it is nil if HTTPS_PROXY is not set.
+	HTTPS *Proxy
+	// noProxy returns nil for the urls excluded from proxying by NO_PROXY.
+	noProxy func(*url.URL) (*url.URL, error)
+}
+
+// NewProxyFromEnvironment returns the proxies configured by the environment variables.
+//
+// The values are parsed like http.ProxyFromEnvironment does, a value without a scheme is an http proxy.
+// It returns an error if a value is not a valid proxy url.
+func NewProxyFromEnvironment() (*EnvironmentProxies, error) {
+	cfg := httpproxy.FromEnvironment()
+	resolve := (&httpproxy.Config{HTTPProxy: cfg.HTTPProxy, HTTPSProxy: cfg.HTTPSProxy}).ProxyFunc()
+
+	httpURL, err := resolve(&url.URL{Scheme: "http", Host: envProbeHost})
+	if err != nil {
+		return nil, fmt.Errorf("invalid HTTP_PROXY: %w", err)
+	}
+	httpsURL, err := resolve(&url.URL{Scheme: "https", Host: envProbeHost})
+	if err != nil {
+		return nil, fmt.Errorf("invalid HTTPS_PROXY: %w", err)
+	}
+
+	env := &EnvironmentProxies{
+		noProxy: (&httpproxy.Config{HTTPProxy: envProbeHost, NoProxy: cfg.NoProxy}).ProxyFunc(),
+	}
+	if httpURL != nil {
+		env.HTTP = NewProxy(httpURL, nil)
+	}
+	switch {
+	case httpsURL == nil:
+	case httpURL != nil && httpsURL.String() == httpURL.String():
+		env.HTTPS = env.HTTP
+	default:
+		env.HTTPS = NewProxy(httpsURL, nil)
+	}
+	return env, nil
+}
+
+// Proxies returns the proxies of the environment without duplicates.
+func (e *EnvironmentProxies) Proxies() []*Proxy {
+	proxies := make([]*Proxy, 0, 2) //nolint: mnd // the http and https proxies
+	if e.HTTP != nil {
+		proxies = append(proxies, e.HTTP)
+	}
+	if e.HTTPS != nil && e.HTTPS != e.HTTP {
+		proxies = append(proxies, e.HTTPS)
+	}
+	return proxies
+}
+
+// Bypass returns true if the request must not be proxied, that is, its host is excluded by NO_PROXY,
+// it is a localhost or a loopback address, or the proxy of its scheme is not set.
+//
+// A request without the http or https scheme, for example of the selection by domain,
+// is proxied if any proxy is set.
+func (e *EnvironmentProxies) Bypass(info *RequestInfo) bool {
+	switch info.Scheme {
+	case "http":
+		if e.HTTP == nil {
+			return true
+		}
+	case "https":
+		if e.HTTPS == nil {
+			return true
+		}
+	default:
+		if e.HTTP == nil && e.HTTPS == nil {
+			return true
+		}
+	}
+
+	host := info.Host
+	if info.Port != "" {
+		host = net.JoinHostPort(host, info.Port)
+	}
+	proxyURL, err := e.noProxy(&url.URL{Scheme: "http", Host: host})
+	return err == nil && proxyURL == nil
+}
+
+// Resources returns the resources that route the requests like http.ProxyFromEnvironment:
+//
+//   - the bypassed requests, see Bypass, use the direct connection, this resource has the highest match priority
+//   - if HTTP_PROXY and HTTPS_PROXY differ, the http and https requests use the proxy of their scheme
+//
+// The other requests use the global pool.
+func (e *EnvironmentProxies) Resources() []*ResourceConfig {
+	resources := []*ResourceConfig{
+		newEnvResource(NewDirectConnection(),
+			WithResourceMatcher(ResourceMatcherFunc(e.Bypass)),
+			WithMatchPriority(math.MaxInt),
+		),
+	}
+	if e.HTTP != nil && e.HTTPS != nil && e.HTTP != e.HTTPS {
+		resources = append(resources,
+			newEnvResource(e.HTTP, WithSchemes("http")),
+			newEnvResource(e.HTTPS, WithSchemes("https")),
+		)
+	}
+	return resources
+}
+
+// newEnvResource returns the resource of the environment that always uses the proxy.
+func newEnvResource(proxy *Proxy, opts ...ResourceConfigOption) *ResourceConfig {
+	return NewResourceConfig(false, append(opts,
+		WithResourceProxies(proxy),
+		WithResourceSelectStrategy(newFirstSelect),
+		WithResourceRotationStrategy(neverRotate{}),
+	)...)
+}
+
+// firstSelect is the select strategy that returns the first proxy.
+type firstSelect struct {
+	provider SelectStrategyProxyProvider
+}
+
+// newFirstSelect returns a new firstSelect.
+func newFirstSelect(provider SelectStrategyProxyProvider) SelectStrategy {
+	return &firstSelect{provider: provider}
+}
+
+// Select returns the first proxy.
+func (s *firstSelect) Select() (*Proxy, error) {
+	proxies := s.provider.GetProxies()
+	if len(proxies) == 0 {
+		return nil, fmt.Errorf("%w: empty proxies from provider", ErrFailedSelectProxy)
+	}
+	return proxies[0], nil
+}
+
+// neverRotate is the rotation strategy that never rotates the proxy.
+type neverRotate struct{}
+
+// ShouldRotate returns false.
+func (neverRotate) ShouldRotate(*Proxy) bool {
+	return false
+}
@@ -1,12 +1,56 @@
 package proxym
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 // Errors.
 var (
 	ErrProxyNotAvailable           = errors.New("proxy not available")
 	ErrUnsupportedRoundTripperImpl = errors.New("unsupported round tripper implementation")
 	ErrResourceNotFound            = errors.New("resource not found")
+	ErrProxyNotFound               = errors.New("proxy not found")
 	ErrEmptyProxyList              = errors.New("empty proxy list in proxy manager")
 	ErrFailedSelectProxy           = errors.New("failed select proxy in select strategy")
+	ErrInvalidHeaderName           = errors.New("invalid header name")
+	ErrProxyExpired                = errors.New("proxy expired")
+	ErrProxyRateLimited            = errors.New("proxy rate limited")
+	ErrResponseBlocked             = errors.New("response blocked")
+	ErrUnsupportedProxyScheme      = errors.New("unsupported proxy scheme")
+	ErrUnsupportedNetwork          = errors.New("unsupported network")
+	ErrProxyConnectFailed          = errors.New("proxy connect failed")
 )
+
+// SelectionError is an error of the proxy selection by domain.
+//
+// It wraps ErrProxyNotAvailable and the cause, so errors.Is works with both of them
+// and errors.As can be used to get the domain.
+type SelectionError struct {
+	// Domain is the domain for which the proxy was selected.
+	Domain string
+	// ResourceMatched is true if a ResourceConfig matched the domain.
+	ResourceMatched bool
+	// Cause is the underlying cause, it may be nil.
+	Cause error
+}
+
+// Error returns the error message.
+func (e *SelectionError) Error() string {
+	pool := "global pool"
+	if e.ResourceMatched {
+		pool = "resource"
+	}
+	if e.Cause == nil {
+		return fmt.Sprintf("%s for domain %q (%s)", ErrProxyNotAvailable, e.Domain, pool)
+	}
+	return fmt.Sprintf("%s for domain %q (%s): %s", ErrProxyNotAvailable, e.Domain, pool, e.Cause)
+}
+
+// Unwrap returns ErrProxyNotAvailable and the cause.
+func (e *SelectionError) Unwrap() []error {
+	if e.Cause == nil {
+		return []error{ErrProxyNotAvailable}
+	}
+	return []error{ErrProxyNotAvailable, e.Cause}
+}
@@ -0,0 +1,37 @@
+package proxym_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/nezbut/proxym"
+)
+
+func TestSelectionError(t *testing.T) {
+	global, resourceProxy := newProxies(1)[0], proxym.NewProxyStr("http://resource:8080", nil)
+	pm := newManager([]*proxym.Proxy{global}, proxym.WithResources(newResource("a.com", resourceProxy)))
+	global.Disable()
+	resourceProxy.Disable()
+
+	for domain, matched := range map[string]bool{"example.com": false, "a.com": true} {
+		_, err := pm.GetNextProxy(domain)
+		var selErr *proxym.SelectionError
+		if !errors.As(err, &selErr) {
+			t.Fatalf("%s: got error %v, want *proxym.SelectionError", domain, err)
+		}
+		if selErr.Domain != domain || selErr.ResourceMatched != matched {
+			t.Errorf("%s: got the domain %q and the resource matched %t, want %q and %t",
+				domain, selErr.Domain, selErr.ResourceMatched, domain, matched)
+		}
+		if !errors.Is(err, proxym.ErrProxyNotAvailable) {
+			t.Errorf("%s: got error %v, want it to be ErrProxyNotAvailable", domain, err)
+		}
+	}
+
+	// The error reaches the caller of the http.Client.
+	_, err := proxym.NewClient(pm).Get("http://a.com/")
+	var selErr *proxym.SelectionError
+	if !errors.As(err, &selErr) || selErr.Domain != "a.com" {
+		t.Errorf("got the client error %v, want *proxym.SelectionError for a.com", err)
+	}
+}
@@ -0,0 +1,181 @@
+package proxym
+
+import "sync"
+
+// SelectEvent is the event of a successful selection, see ProxyManagerImpl.OnSelect.
+type SelectEvent struct {
+	// Domain is the domain of the selection.
+	Domain string
+	// Resource is the resource of the selection, nil is the global pool.
+	Resource *ResourceConfig
+	// Proxy is the selected proxy.
+	Proxy *Proxy
+	// Reused is true if the last used proxy was reused without a rotation.
+	Reused bool
+	// Strategy is the name of the select strategy, that is, its type name.
+	Strategy string
+}
+
+// RotateEvent is the event of a rotation of the last used proxy, see ProxyManagerImpl.OnRotate.
+type RotateEvent struct {
+	// Domain is the domain of the selection.
+	Domain string
+	// Resource is the resource of the selection, nil is the global pool.
+	Resource *ResourceConfig
+	// Previous is the rotated last used proxy.
+	Previous *Proxy
+}
+
+// ErrorEvent is the event of a failed selection, see ProxyManagerImpl.OnError.
+type ErrorEvent struct {
+	// Domain is the domain of the selection.
+	Domain string
+	// Resource is the resource of the selection, nil is the global pool or no matched resource.
+	Resource *ResourceConfig
+	// Err is the *SelectionError of the selection.
+	Err error
+}
+
+// ResultEvent is the event of a recorded result of a request through a proxy, see ProxyManagerImpl.OnResult.
+type ResultEvent struct {
+	// Domain is the domain of the request.
+	Domain string
+	// Proxy is the proxy of the request.
+	Proxy *Proxy
+	// StatusCode is the status code of the response, 0 if there is no response.
+	StatusCode int
+	// Failed is true if the request failed.
+	Failed bool
+}
+
+// hooks is a list of callbacks of an event.
+type hooks[E any] struct {
+	fns []func(E)
+	mu  sync.RWMutex
+}
+
+// add registers the callback.
+func (h *hooks[E]) add(fn func(E)) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.fns = append(h.fns, fn)
+}
+
+// emit calls the callbacks with the event.
+func (h *hooks[E]) emit(event E) {
+	h.mu.RLock()
+	fns := append([]func(E){}, h.fns...)
+	h.mu.RUnlock()
+
+	for _, fn := range fns {
+		fn(event)
+	}
+}
+
+// eventHooks are the callbacks of the events of the ProxyManagerImpl.
+type eventHooks struct {
+	onSelect  hooks[SelectEvent]
+	onRotate  hooks[RotateEvent]
+	onError   hooks[ErrorEvent]
+	onResult  hooks[ResultEvent]
+	onDisable hooks[*Proxy]
+	onEnable  hooks[*Proxy]
+	disabled  map[*Proxy]bool
+	mu        sync.Mutex
+}
+
+// OnSelect registers the function called after every successful selection of GetNextProxy, GetNextProxyContext,
+// GetNextProxyRequest, Acquire and ProxyTransport, including the reuses of the last used proxy.
+//
+// All callbacks are called synchronously in the goroutine of the event, so they must be fast and must not block,
+// hand the work off to another goroutine if needed.
+func (pm *ProxyManagerImpl) OnSelect(fn func(SelectEvent)) {
+	pm.events.onSelect.add(fn)
+}
+
+// OnRotate registers the function called when the rotation strategy rotates the last used proxy
+// or the last used proxy is draining, before the new proxy is selected. See OnSelect.
+func (pm *ProxyManagerImpl) OnRotate(fn func(RotateEvent)) {
+	pm.events.onRotate.add(fn)
+}
+
+// OnError registers the function called after every failed selection. See OnSelect.
+func (pm *ProxyManagerImpl) OnError(fn func(ErrorEvent)) {
+	pm.events.onError.add(fn)
+}
+
+// OnResult registers the function called when the result of a request is recorded
+// by ProxyTransport or ProxyLease.Release, that is, when the statistics are updated. See OnSelect.
+func (pm *ProxyManagerImpl) OnResult(fn func(ResultEvent)) {
+	pm.events.onResult.add(fn)
+}
+
+// OnDisable registers the function called when a proxy of the fleet transitions from enabled to disabled
+// by Disable, DisableFor, DisableUntil or Cooldown. See OnSelect.
+func (pm *ProxyManagerImpl) OnDisable(fn func(*Proxy)) {
+	pm.events.onDisable.add(fn)
+}
+
+// OnEnable registers the function called when a disabled proxy of the fleet is enabled again
+// by Enable or when its cooldown is over. See OnSelect.
+//
+// The cooldowns are over lazily, see DisableFor, so the event is delayed until the proxy is checked.
+func (pm *ProxyManagerImpl) OnEnable(fn func(*Proxy)) {
+	pm.events.onEnable.add(fn)
+}
+
+// emitSelection calls the callbacks of the result of the selection.
+func (pm *ProxyManagerImpl) emitSelection(info *RequestInfo, proxy *Proxy, sel selection, err error) {
+	if err != nil {
+		pm.events.onError.emit(ErrorEvent{Domain: info.Host, Resource: sel.resource, Err: err})
+		return
+	}
+	pm.events.onSelect.emit(SelectEvent{
+		Domain:   info.Host,
+		Resource: sel.resource,
+		Proxy:    proxy,
+		Reused:   sel.decision == decisionReused,
+		Strategy: sel.strategy,
+	})
+}
+
+// trackDisabled sets the current disabled flags of the proxies, so their transitions are detected.
+func (e *eventHooks) trackDisabled(proxies []*Proxy) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.disabled == nil {
+		e.disabled = make(map[*Proxy]bool, len(proxies))
+	}
+	for _, p := range proxies {
+		e.disabled[p] = p.disabledFlag()
+	}
+}
+
+// untrackDisabled drops the disabled flags of the proxies that are no longer in the fleet.
+func (e *eventHooks) untrackDisabled(proxies []*Proxy) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for _, p := range proxies {
+		delete(e.disabled, p)
+	}
+}
+
+// proxyChanged calls the callbacks if the disabled flag of the proxy transitioned.
+func (e *eventHooks) proxyChanged(p *Proxy) {
+	disabled := p.disabledFlag()
+
+	e.mu.Lock()
+	previous, ok := e.disabled[p]
+	if !ok || previous == disabled {
+		e.mu.Unlock()
+		return
+	}
+	e.disabled[p] = disabled
+	e.mu.Unlock()
+
+	if disabled {
+		e.onDisable.emit(p)
+	} else {
+		e.onEnable.emit(p)
+	}
+}
@@ -0,0 +1,69 @@
+package proxym
+
+import "sort"
+
+// EvictionPolicy is a type for the policy used to evict proxies when the pool exceeds its maximum size.
+type EvictionPolicy int
+
+// EvictionPolicy constants.
+const (
+	// EvictOldestLastUsed evicts the proxies that were used the longest time ago first.
+	// Proxies that have never been used are considered the oldest.
+	EvictOldestLastUsed EvictionPolicy = iota
+	// EvictWorstSuccessRate evicts the proxies with the lowest success rate first.
+	// Proxies without requests are considered healthy, ties are broken by the oldest last used.
+	EvictWorstSuccessRate
+)
+
+// evict returns the proxies that stay in the pool and the evicted proxies.
+//
+// Only the proxies from the existing pool are evicted by the policy,
+// the added proxies are evicted only if they alone exceed the limit, in which case the last ones are kept.
+func (e EvictionPolicy) evict(existing, added []*Proxy, limit int) ([]*Proxy, []*Proxy) {
+	if limit <= 0 || len(existing)+len(added) <= limit {
+		return append(existing, added...), nil
+	}
+
+	evicted := make([]*Proxy, 0, len(existing)+len(added)-limit)
+	if len(added) >= limit {
+		evicted = append(evicted, existing...)
+		evicted = append(evicted, added[:len(added)-limit]...)
+		kept := make([]*Proxy, limit)
+		copy(kept, added[len(added)-limit:])
+		return kept, evicted
+	}
+
+	ranked := make([]*Proxy, len(existing))
+	copy(ranked, existing)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return e.less(ranked[i], ranked[j])
+	})
+
+	toEvict := make(map[*Proxy]struct{}, len(existing)+len(added)-limit)
+	for _, p := range ranked[:len(existing)+len(added)-limit] {
+		toEvict[p] = struct{}{}
+	}
+
+	kept := make([]*Proxy, 0, limit)
+	for _, p := range existing {
+		if _, ok := toEvict[p]; ok {
+			evicted = append(evicted, p)
+			continue
+		}
+		kept = append(kept, p)
+	}
+	return append(kept, added...), evicted
+}
+
+// less returns true if the proxy a should be evicted before the proxy b.
+func (e EvictionPolicy) less(a, b *Proxy) bool {
+	aStats, bStats := a.Stats(), b.Stats()
+
+	if e == EvictWorstSuccessRate {
+		aRate, bRate := aStats.SuccessRate(), bStats.SuccessRate()
+		if aRate != bRate {
+			return aRate < bRate
+		}
+	}
+	return aStats.LastUsed().Before(bStats.LastUsed())
+}
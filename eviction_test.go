@@ -0,0 +1,131 @@
+package proxym_test
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/nezbut/proxym"
+)
+
+var errRequest = errors.New("request failed")
+
+// use records the requests of the proxy, one per result, true being a success.
+//
+// The last used date of the proxy is strictly after the one of the previously used proxies.
+func use(p *proxym.Proxy, results ...bool) {
+	for _, ok := range results {
+		time.Sleep(time.Millisecond)
+		if ok {
+			p.Update(&http.Response{StatusCode: http.StatusOK}, nil)
+		} else {
+			p.Update(nil, errRequest)
+		}
+	}
+}
+
+// evictedBy adds a new proxy to the full pool of the proxies and returns the index of the evicted proxy.
+func evictedBy(t *testing.T, policy proxym.EvictionPolicy, proxies []*proxym.Proxy) int {
+	t.Helper()
+	pm := newManager(proxies, proxym.WithMaxPool(len(proxies), policy))
+	if err := pm.AddProxies(proxym.NewProxyStr("http://added:8080", nil)); err != nil {
+		t.Fatal(err)
+	}
+
+	pool := make(map[*proxym.Proxy]bool)
+	for _, p := range pm.GetProxies() {
+		pool[p] = true
+	}
+	if len(pool) != len(proxies) {
+		t.Fatalf("got %d proxies in the pool, want %d", len(pool), len(proxies))
+	}
+	evicted := -1
+	for i, p := range proxies {
+		if !pool[p] {
+			evicted = i
+		}
+	}
+	return evicted
+}
+
+func TestEvictOldestLastUsed(t *testing.T) {
+	proxies := newProxies(3)
+	use(proxies[1], true)
+	use(proxies[0], false)
+	use(proxies[2], true)
+	if evicted := evictedBy(t, proxym.EvictOldestLastUsed, proxies); evicted != 1 {
+		t.Errorf("got proxy %d evicted, want the oldest used proxy 1", evicted)
+	}
+
+	// A proxy that has never been used is the oldest.
+	proxies = newProxies(2)
+	use(proxies[0], true)
+	if evicted := evictedBy(t, proxym.EvictOldestLastUsed, proxies); evicted != 1 {
+		t.Errorf("got proxy %d evicted, want the unused proxy 1", evicted)
+	}
+}
+
+func TestEvictWorstSuccessRate(t *testing.T) {
+	proxies := newProxies(3)
+	use(proxies[0], true, true, true, false)
+	use(proxies[1], true, false, false, false)
+	if evicted := evictedBy(t, proxym.EvictWorstSuccessRate, proxies); evicted != 1 {
+		t.Errorf("got proxy %d evicted, want the proxy 1 with the worst success rate", evicted)
+	}
+
+	// Ties are broken by the oldest last used.
+	proxies = newProxies(2)
+	use(proxies[1], true, false)
+	use(proxies[0], true, false)
+	if evicted := evictedBy(t, proxym.EvictWorstSuccessRate, proxies); evicted != 1 {
+		t.Errorf("got proxy %d evicted, want the older used proxy 1 of the tie", evicted)
+	}
+}
+
+func TestEvictionClearsLastUsed(t *testing.T) {
+	proxies := newProxies(2)
+	pm := newManager(proxies[:1], proxym.WithMaxPool(1, proxym.EvictOldestLastUsed))
+	if _, err := pm.GetNextProxy("example.com"); err != nil {
+		t.Fatal(err)
+	}
+	if err := pm.AddProxies(proxies[1]); err != nil {
+		t.Fatal(err)
+	}
+	if got := pm.LastUsed(); got != nil {
+		t.Errorf("got the evicted proxy %s as the last used, want nil", got)
+	}
+}
+
+func TestRemoveClearsLastUsedAndWatchers(t *testing.T) {
+	global, resourceProxies := newProxies(2), []*proxym.Proxy{proxym.NewProxyStr("http://resource:8080", nil)}
+	rc := newResource("a.com", resourceProxies...)
+	pm := newManager(global, proxym.WithResources(rc))
+	var disabled []*proxym.Proxy
+	pm.OnDisable(func(p *proxym.Proxy) { disabled = append(disabled, p) })
+
+	used, err := pm.GetNextProxy("example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := pm.GetNextProxy("a.com"); err != nil {
+		t.Fatal(err)
+	}
+
+	pm.RemoveProxies(used)
+	if got := pm.LastUsed("example.com"); got != nil {
+		t.Errorf("got the removed proxy %s as the last used, want nil", got)
+	}
+	if err := pm.RemoveResource("a.com"); err != nil {
+		t.Fatal(err)
+	}
+	if got := pm.ResourceLastUsed(rc); got != nil {
+		t.Errorf("got the last used proxy %s of the removed resource, want nil", got)
+	}
+
+	used.Disable()
+	resourceProxies[0].Disable()
+	if len(disabled) != 0 {
+		t.Errorf("got %d OnDisable events for the removed proxies, want 0", len(disabled))
+	}
+}
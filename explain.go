@@ -0,0 +1,213 @@
+package proxym
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// DecisionTrace explains a selection of the ProxyManagerImpl: which resource matched,
+// what the rotation strategy decided, the states of the candidates and why the proxy was chosen,
+// see ProxyManagerImpl.ExplainNextProxy and WithDecisionTrace.
+type DecisionTrace struct {
+	// Domain is the domain of the selection.
+	Domain string
+	// Resource is the resource that matched the domain, nil if no resource matched.
+	Resource *ResourceConfig
+	// FellBack is true if the matched resource had no usable proxy and the global pool was used,
+	// see WithResourceFallbackToGlobal.
+	FellBack bool
+	// LastUsed is the last used proxy of the resource or of the global pool before the selection.
+	LastUsed *Proxy
+	// Decision is the rotation decision: "initial" without the last used proxy,
+	// "reused" if the last used proxy was kept and "rotated" if it was rotated.
+	Decision string
+	// RotationReason explains the rotation decision.
+	RotationReason string
+	// Strategy is the name of the select strategy, that is, its type name.
+	Strategy string
+	// Candidates are the proxies passed to the select strategy with their states,
+	// they are empty if the last used proxy was reused. The proxies banned for the resource are not passed.
+	Candidates []CandidateTrace
+	// Proxy is the selected proxy, nil if the selection failed.
+	Proxy *Proxy
+	// Reason explains why the proxy was chosen.
+	Reason string
+	// Err is the error of the failed selection.
+	Err error
+}
+
+// excludedStates are the states of the candidates that exclude them, see DecisionTrace.Excluded.
+var excludedStates = []string{"disabled", "draining", "expired", "quarantined"}
+
+// CandidateTrace is a candidate of the selection with its state at the selection time.
+type CandidateTrace struct {
+	Proxy       *Proxy
+	Disabled    bool
+	Draining    bool
+	Expired     bool
+	Quarantined bool
+	Active      bool
+}
+
+// Eligible returns true if the candidate is neither disabled, draining, expired nor quarantined.
+func (c CandidateTrace) Eligible() bool {
+	return !c.Disabled && !c.Draining && !c.Expired && !c.Quarantined
+}
+
+// Excluded returns the numbers of the candidates by the state that excludes them,
+// that is, "disabled", "draining", "expired" and "quarantined". These are the candidates removed by
+// selects.RemoveDisabledFilter, selects.RemoveDrainingFilter, selects.RemoveExpiredFilter
+// and selects.RemoveQuarantinedFilter if the select strategy uses them.
+func (t *DecisionTrace) Excluded() map[string]int {
+	excluded := make(map[string]int)
+	for _, c := range t.Candidates {
+		if c.Disabled {
+			excluded["disabled"]++
+		}
+		if c.Draining {
+			excluded["draining"]++
+		}
+		if c.Expired {
+			excluded["expired"]++
+		}
+		if c.Quarantined {
+			excluded["quarantined"]++
+		}
+	}
+	return excluded
+}
+
+// String returns the human-readable multi-line trace, the proxy urls are redacted.
+func (t *DecisionTrace) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "domain: %q\n", t.Domain)
+	switch {
+	case t.Resource == nil:
+		b.WriteString("resource: none, the global pool is used\n")
+	case t.FellBack:
+		fmt.Fprintf(&b, "resource: %q has no usable proxy, the global pool is used\n", t.Resource.Domain())
+	default:
+		fmt.Fprintf(&b, "resource: %q\n", t.Resource.Domain())
+	}
+	if t.Decision != "" {
+		fmt.Fprintf(&b, "rotation: %s, %s\n", t.Decision, t.RotationReason)
+	}
+	if len(t.Candidates) != 0 {
+		eligible := 0
+		for _, c := range t.Candidates {
+			if c.Eligible() {
+				eligible++
+			}
+		}
+		fmt.Fprintf(&b, "candidates: %d, eligible: %d", len(t.Candidates), eligible)
+		excluded := t.Excluded()
+		for _, state := range excludedStates {
+			if n := excluded[state]; n != 0 {
+				fmt.Fprintf(&b, ", %s: %d", state, n)
+			}
+		}
+		b.WriteString("\n")
+	}
+	if t.Err != nil {
+		fmt.Fprintf(&b, "error: %v\n", t.Err)
+		return b.String()
+	}
+	if t.Proxy != nil {
+		fmt.Fprintf(&b, "proxy: %s, %s\n", redactedProxy(t.Proxy), t.Reason)
+	}
+	return b.String()
+}
+
+// WithDecisionTrace enables the debug mode of the ProxyManagerImpl, the function is called
+// with the DecisionTrace of every selection, for example to log it.
+//
+// Tracing every selection is expensive, enable it for debugging only.
+func WithDecisionTrace(fn func(*DecisionTrace)) ProxyManagerImplOption {
+	return func(pm *ProxyManagerImpl) {
+		pm.traceDecision = fn
+	}
+}
+
+// ExplainNextProxy returns the next available proxy like GetNextProxyContext and the DecisionTrace
+// that explains the selection. The trace is returned even if the selection fails.
+//
+// The selection is real, so it updates the last used proxy and the counters like GetNextProxyContext.
+func (pm *ProxyManagerImpl) ExplainNextProxy(ctx context.Context, domain string) (*Proxy, *DecisionTrace, error) {
+	trace := &DecisionTrace{Domain: domain}
+	proxy, _, err := pm.selectTraced(ctx, domainInfo(domain), trace)
+	return proxy, trace, err
+}
+
+// resource records the matched resource, the trace may be nil.
+func (t *DecisionTrace) resource(resource *ResourceConfig, fellBack bool) {
+	if t == nil {
+		return
+	}
+	t.Resource = resource
+	t.FellBack = fellBack
+}
+
+// rotation records the rotation decision, the trace may be nil.
+func (t *DecisionTrace) rotation(lastUsed *Proxy, sel selection, forced string, strategy RotationStrategy) {
+	if t == nil {
+		return
+	}
+	t.LastUsed = lastUsed
+	t.Decision = sel.decision
+	t.Strategy = sel.strategy
+	switch {
+	case lastUsed == nil:
+		t.RotationReason = "no last used proxy"
+	case forced != "":
+		t.RotationReason = forced
+	case sel.decision == decisionRotated:
+		t.RotationReason = fmt.Sprintf("the rotation strategy %T decided to rotate", strategy)
+	default:
+		t.RotationReason = fmt.Sprintf("the rotation strategy %T kept the last used proxy", strategy)
+	}
+}
+
+// candidates records the states of the candidates, the trace may be nil.
+func (t *DecisionTrace) candidates(proxies []*Proxy, now time.Time) {
+	if t == nil {
+		return
+	}
+	t.Candidates = make([]CandidateTrace, 0, len(proxies))
+	for _, p := range proxies {
+		t.Candidates = append(t.Candidates, CandidateTrace{
+			Proxy:       p,
+			Disabled:    p.IsDisabled(),
+			Draining:    p.IsDraining(),
+			Expired:     p.Metadata().IsExpired(now),
+			Quarantined: p.IsQuarantined(),
+			Active:      p.IsActive(),
+		})
+	}
+}
+
+// chosen records the reason of the choice of the proxy, the trace may be nil.
+func (t *DecisionTrace) chosen(reason string) {
+	if t == nil {
+		return
+	}
+	t.Reason = reason
+}
+
+// finish records the result of the selection, the trace may be nil.
+func (t *DecisionTrace) finish(proxy *Proxy, err error) {
+	if t == nil {
+		return
+	}
+	t.Proxy = proxy
+	t.Err = err
+}
+
+// redactedProxy returns the url of the proxy with the redacted password.
+func redactedProxy(proxy *Proxy) string {
+	if u := proxy.URL(); u != nil {
+		return u.Redacted()
+	}
+	return proxy.String()
+}
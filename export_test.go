@@ -0,0 +1,8 @@
+package proxym
+
+// SOCKSTransports returns the number of the http.Transports of the SOCKS5 proxies kept by the SOCKSTransport.
+func (t *SOCKSTransport) SOCKSTransports() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return len(t.transports)
+}
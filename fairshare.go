@@ -0,0 +1,118 @@
+package proxym
+
+import "sync"
+
+// requestTracker is implemented by the managers that track the requests through the proxies.
+//
+// ProxyTransport calls trackRequest when the request is sent and the returned function when it is done.
+type requestTracker interface {
+	trackRequest(info *RequestInfo, proxy *Proxy) func()
+}
+
+// fairShare is the fair-share accounting of the proxies between the resources of the manager.
+//
+// Fairness policy: each resource, and the global pool for domains without a resource, holds the proxies
+// that have its in-flight requests. A resource is busy while it holds at least one proxy.
+// The fair limit of a resource is its share of the pool size divided by the sum of the shares of the busy resources,
+// but at least one proxy. When a resource already holds its fair limit and another resource is busy,
+// it can not take a new proxy and reuses the held proxy with the fewest of its in-flight requests instead.
+// When no other resource is busy, a resource can use the whole pool.
+type fairShare struct {
+	holdings map[*ResourceConfig]map[*Proxy]int
+	mu       sync.Mutex
+}
+
+// newFairShare returns a new fairShare.
+func newFairShare() *fairShare {
+	return &fairShare{holdings: make(map[*ResourceConfig]map[*Proxy]int)}
+}
+
+// acquire counts the in-flight request of the resource through the proxy.
+//
+// The resource is nil for the global pool.
+func (f *fairShare) acquire(resource *ResourceConfig, proxy *Proxy) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	held, ok := f.holdings[resource]
+	if !ok {
+		held = make(map[*Proxy]int)
+		f.holdings[resource] = held
+	}
+	held[proxy]++
+}
+
+// release removes the in-flight request of the resource through the proxy.
+func (f *fairShare) release(resource *ResourceConfig, proxy *Proxy) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	held := f.holdings[resource]
+	if held[proxy] > 1 {
+		held[proxy]--
+		return
+	}
+	delete(held, proxy)
+	if len(held) == 0 {
+		delete(f.holdings, resource)
+	}
+}
+
+// constrain returns the selected proxy or, if the resource holds its fair limit of the candidates
+// and the selected proxy is not held by it, the held proxy with the fewest in-flight requests of the resource.
+//
+// Only the held proxies that are still candidates and are eligible are reused, so a held proxy that has been
+// disabled, drained, quarantined, banned for the resource or has expired is never returned. If none of them is,
+// the selected proxy is returned.
+func (f *fairShare) constrain(
+	resource *ResourceConfig, candidates []*Proxy, selected *Proxy, eligible func(*Proxy) bool,
+) *Proxy {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	held := f.holdings[resource]
+	if len(held) == 0 || held[selected] > 0 {
+		return selected
+	}
+
+	totalShare := resourceShare(resource)
+	othersBusy := false
+	for other := range f.holdings {
+		if other != resource {
+			totalShare += resourceShare(other)
+			othersBusy = true
+		}
+	}
+	if !othersBusy {
+		return selected
+	}
+
+	share, total := int(resourceShare(resource)), int(totalShare) //nolint: gosec // shares are small numbers
+	limit := max(len(candidates)*share/total, 1)
+	if len(held) < limit {
+		return selected
+	}
+
+	var least *Proxy
+	for _, p := range candidates {
+		n := held[p]
+		if n == 0 || !eligible(p) {
+			continue
+		}
+		if least == nil || n < held[least] {
+			least = p
+		}
+	}
+	if least == nil {
+		return selected
+	}
+	return least
+}
+
+// resourceShare returns the share of the resource, the global pool has share 1.
+func resourceShare(resource *ResourceConfig) uint {
+	if resource == nil {
+		return 1
+	}
+	return resource.Share()
+}
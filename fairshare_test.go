@@ -0,0 +1,97 @@
+package proxym_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/nezbut/proxym"
+	"github.com/nezbut/proxym/rotations"
+	"github.com/nezbut/proxym/selects"
+)
+
+// newRoundRobinResource returns a ResourceConfig of the domain that selects a new proxy in order on each request.
+func newRoundRobinResource(
+	domain string, proxies []*proxym.Proxy, opts ...proxym.ResourceConfigOption,
+) *proxym.ResourceConfig {
+	return proxym.NewResourceConfig(true, append([]proxym.ResourceConfigOption{
+		proxym.WithDomain(domain),
+		proxym.WithResourceProxies(proxies...),
+		proxym.WithResourceRotationStrategy(rotations.RoundRobinRotation{}),
+		proxym.WithResourceSelectStrategy(selects.NewRoundRobinSelect),
+	}, opts...)...)
+}
+
+// newFairShareClient returns a client of the manager whose requests are counted for the fair-share accounting.
+func newFairShareClient(t *testing.T, pm *proxym.ProxyManagerImpl) *http.Client {
+	t.Helper()
+	base, err := proxym.CloneRoundTripperWithProxySelector(pm, http.DefaultTransport)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &http.Client{Transport: proxym.NewProxyTransport(pm, base)}
+}
+
+// inFlight sends a request to the domain and returns its response with the body left open,
+// so the request is in flight until the body is closed.
+func inFlight(t *testing.T, client *http.Client, domain string) *http.Response {
+	t.Helper()
+	resp, err := client.Get("http://" + domain + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
+
+// proxyOf returns the proxy that served the response.
+func proxyOf(resp *http.Response) *proxym.Proxy {
+	return proxym.ProxyFromContext(resp.Request.Context())
+}
+
+func TestFairShareCompetingResources(t *testing.T) {
+	proxies := newProxyServers(t, 4)
+	pm := newManager(proxies, proxym.WithFairShare(true), proxym.WithResources(
+		newRoundRobinResource("a.com", proxies),
+		newRoundRobinResource("b.com", proxies),
+	))
+	client := newFairShareClient(t, pm)
+
+	inFlight(t, client, "b.com")
+	// b.com is busy, so a.com holds at most 4 * 1/2 proxies.
+	first, second := proxyOf(inFlight(t, client, "a.com")), proxyOf(inFlight(t, client, "a.com"))
+	if first == second {
+		t.Fatal("got the same proxy within the fair limit, want distinct proxies")
+	}
+	third := inFlight(t, client, "a.com")
+	if p := proxyOf(third); p != first && p != second {
+		t.Fatalf("got %s over the fair limit, want a held proxy", p.URL())
+	}
+	third.Body.Close()
+
+	// The held proxy that is disabled is not reused.
+	first.Disable()
+	if p := proxyOf(inFlight(t, client, "a.com")); p != second {
+		t.Errorf("got %s over the fair limit, want the eligible held proxy %s", p.URL(), second.URL())
+	}
+}
+
+func TestFairShareFallbackNotCounted(t *testing.T) {
+	proxies := newProxyServers(t, 4)
+	fallback := proxym.NewProxyStr("http://fallback:8080", nil)
+	pm := newManager(proxies, proxym.WithFairShare(true), proxym.WithResources(
+		newRoundRobinResource("a.com", proxies),
+		newRoundRobinResource("c.com", []*proxym.Proxy{fallback}, proxym.WithResourceFallbackToGlobal(true)),
+	))
+	client := newFairShareClient(t, pm)
+	fallback.Disable()
+
+	inFlight(t, client, "c.com")
+	// c.com fell back to the global pool, so it does not hold a share and a.com can use the whole pool.
+	seen := make(map[*proxym.Proxy]bool)
+	for range len(proxies) {
+		seen[proxyOf(inFlight(t, client, "a.com"))] = true
+	}
+	if len(seen) != len(proxies) {
+		t.Errorf("got %d distinct proxies, want %d", len(seen), len(proxies))
+	}
+}
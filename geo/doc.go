@@ -0,0 +1,3 @@
+// Package geo fills the countries of the proxies of a proxym.ProxyManagerImpl automatically
+// by their exit IP addresses, so the country filters work even when the proxy lists come without metadata.
+package geo
@@ -0,0 +1,180 @@
+package geo
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nezbut/proxym"
+)
+
+// Default settings of the Enricher.
+const (
+	defaultInterval    = 10 * time.Minute
+	defaultConcurrency = 10
+)
+
+// FleetProvider is an interface that provides the proxies to enrich, for example proxym.ProxyManagerImpl.
+type FleetProvider interface {
+	// Fleet returns the proxies to enrich.
+	Fleet() []*proxym.Proxy
+}
+
+// Enricher resolves the countries of the proxies of the fleet with the Resolver
+// and sets them to the metadata of the proxies, see proxym.ProxyMetadata.SetCountry.
+//
+// Only the proxies without a country are resolved, unless WithOverwrite is set,
+// so the periodic runs resolve the newly added proxies only.
+type Enricher struct {
+	fleet       FleetProvider
+	resolver    Resolver
+	interval    time.Duration
+	concurrency int
+	overwrite   bool
+	onResult    func(proxy *proxym.Proxy, country string, err error)
+	cancel      context.CancelFunc
+	done        chan struct{}
+	mu          sync.Mutex
+}
+
+// Option is option for Enricher.
+type Option func(*Enricher)
+
+// WithInterval sets the interval between the runs of Start, by default 10 minutes.
+// A non-positive interval is replaced by the default.
+func WithInterval(interval time.Duration) Option {
+	return func(e *Enricher) {
+		e.interval = interval
+	}
+}
+
+// WithConcurrency sets the maximum number of concurrent resolutions, by default 10.
+func WithConcurrency(concurrency int) Option {
+	return func(e *Enricher) {
+		e.concurrency = concurrency
+	}
+}
+
+// WithOverwrite sets whether the countries already set are resolved and overwritten too, by default false.
+func WithOverwrite(overwrite bool) Option {
+	return func(e *Enricher) {
+		e.overwrite = overwrite
+	}
+}
+
+// WithOnResult sets the function called with the result of each resolution, err is nil if the country is set.
+func WithOnResult(onResult func(proxy *proxym.Proxy, country string, err error)) Option {
+	return func(e *Enricher) {
+		e.onResult = onResult
+	}
+}
+
+// NewEnricher returns a new Enricher.
+func NewEnricher(fleet FleetProvider, resolver Resolver, opts ...Option) *Enricher {
+	e := &Enricher{
+		fleet:       fleet,
+		resolver:    resolver,
+		interval:    defaultInterval,
+		concurrency: defaultConcurrency,
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	if e.concurrency <= 0 {
+		e.concurrency = 1
+	}
+	if e.interval <= 0 {
+		e.interval = defaultInterval
+	}
+	return e
+}
+
+// Start starts the periodic enrichment in the background, the first run starts immediately.
+//
+// The runs continue until the context is canceled or Stop is called.
+// Calling Start on a started Enricher does nothing.
+func (e *Enricher) Start(ctx context.Context) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.cancel != nil {
+		return
+	}
+
+	ctx, e.cancel = context.WithCancel(ctx)
+	e.done = make(chan struct{})
+	go e.run(ctx, e.done)
+}
+
+// Stop stops the periodic enrichment and waits for the running resolutions to finish.
+func (e *Enricher) Stop() {
+	e.mu.Lock()
+	cancel, done := e.cancel, e.done
+	e.cancel, e.done = nil, nil
+	e.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+		<-done
+	}
+}
+
+// EnrichAll resolves the countries of the proxies of the fleet once and waits for the resolutions to finish.
+func (e *Enricher) EnrichAll(ctx context.Context) {
+	sem := make(chan struct{}, e.concurrency)
+	var wg sync.WaitGroup
+
+	for _, p := range e.fleet.Fleet() {
+		if !e.overwrite && p.Metadata().Country() != "" {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			return
+		case sem <- struct{}{}:
+		}
+
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			e.enrich(ctx, p)
+		}()
+	}
+	wg.Wait()
+}
+
+// run runs the periodic enrichment until the context is canceled.
+func (e *Enricher) run(ctx context.Context, done chan struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+
+	for {
+		e.EnrichAll(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// enrich resolves the country of the proxy and sets it in upper case.
+func (e *Enricher) enrich(ctx context.Context, proxy *proxym.Proxy) {
+	country, err := e.resolver.Country(ctx, proxy)
+	if ctx.Err() != nil {
+		return
+	}
+	if err == nil {
+		country = strings.ToUpper(country)
+		proxy.Metadata().SetCountry(country)
+	}
+	if e.onResult != nil {
+		e.onResult(proxy, country, err)
+	}
+}
@@ -0,0 +1,52 @@
+// Package maxmind provides the lookup of the countries of the IP addresses in a local MaxMind database,
+// for example GeoLite2-Country.mmdb, for geo.IPResolver.
+package maxmind
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/oschwald/maxminddb-golang"
+
+	"github.com/nezbut/proxym/geo"
+)
+
+// record is the country record of the database.
+type record struct {
+	Country struct {
+		ISOCode string `maxminddb:"iso_code"`
+	} `maxminddb:"country"`
+}
+
+// DB is a MaxMind database of the countries of the IP addresses.
+type DB struct {
+	reader *maxminddb.Reader
+}
+
+// Open opens the MaxMind database file, for example GeoLite2-Country.mmdb or GeoIP2-City.mmdb.
+func Open(path string) (*DB, error) {
+	reader, err := maxminddb.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	return &DB{reader: reader}, nil
+}
+
+// Lookup returns the ISO 3166-1 alpha-2 code of the country of the IP address, see geo.LookupFunc.
+//
+// It returns a geo.ErrCountryNotFound error if the database has no country of the IP address.
+func (db *DB) Lookup(ip net.IP) (string, error) {
+	var r record
+	if err := db.reader.Lookup(ip, &r); err != nil {
+		return "", err
+	}
+	if r.Country.ISOCode == "" {
+		return "", fmt.Errorf("%w: %s", geo.ErrCountryNotFound, ip)
+	}
+	return r.Country.ISOCode, nil
+}
+
+// Close closes the database.
+func (db *DB) Close() error {
+	return db.reader.Close()
+}
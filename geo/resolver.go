@@ -0,0 +1,146 @@
+package geo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/nezbut/proxym"
+)
+
+// ErrCountryNotFound is returned by the resolvers when the country of the proxy is not found.
+var ErrCountryNotFound = errors.New("country not found")
+
+// Defaults of the resolvers.
+const (
+	defaultTimeout = 10 * time.Second
+	maxBodySize    = 1 << 16
+)
+
+// Resolver resolves the country of the exit IP address of a proxy.
+type Resolver interface {
+	// Country returns the ISO 3166-1 alpha-2 code of the country of the proxy.
+	Country(ctx context.Context, proxy *proxym.Proxy) (string, error)
+}
+
+// ResolverFunc is an adapter to allow the use of ordinary functions as Resolver.
+type ResolverFunc func(ctx context.Context, proxy *proxym.Proxy) (string, error)
+
+// Country calls f(ctx, proxy).
+func (f ResolverFunc) Country(ctx context.Context, proxy *proxym.Proxy) (string, error) {
+	return f(ctx, proxy)
+}
+
+// LookupFunc returns the ISO 3166-1 alpha-2 code of the country of the IP address,
+// for example a lookup in a local database, see maxmind.DB.Lookup.
+type LookupFunc func(ip net.IP) (string, error)
+
+// APIResolver is a Resolver that sends an HTTP GET request through the proxy to a geo API,
+// which sees the exit IP address of the proxy, and reads the country from the field of the JSON response,
+// for example "country" of https://ipinfo.io/json or "countryCode" of http://ip-api.com/json.
+type APIResolver struct {
+	endpoint string
+	field    string
+	timeout  time.Duration
+}
+
+// NewAPIResolver returns a new APIResolver of the geo API endpoint with the country field of the JSON response.
+//
+// If the timeout is less than or equal to 0, the default timeout of 10 seconds is used.
+func NewAPIResolver(endpoint, field string, timeout time.Duration) *APIResolver {
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	return &APIResolver{endpoint: endpoint, field: field, timeout: timeout}
+}
+
+// Country returns the country of the proxy reported by the geo API.
+func (r *APIResolver) Country(ctx context.Context, proxy *proxym.Proxy) (string, error) {
+	body, err := get(ctx, proxy, r.endpoint, r.timeout)
+	if err != nil {
+		return "", err
+	}
+	var fields map[string]any
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return "", err
+	}
+	country, _ := fields[r.field].(string)
+	if country == "" {
+		return "", fmt.Errorf("%w: no %q in the response", ErrCountryNotFound, r.field)
+	}
+	return country, nil
+}
+
+// IPResolver is a Resolver that gets the exit IP address of the proxy from an IP echo service,
+// which returns the IP address of the client as plain text, for example https://api.ipify.org,
+// and looks up the country of the IP address with the LookupFunc, for example in a local MaxMind database.
+type IPResolver struct {
+	echo    string
+	lookup  LookupFunc
+	timeout time.Duration
+}
+
+// NewIPResolver returns a new IPResolver of the IP echo service url with the lookup function.
+//
+// If the timeout is less than or equal to 0, the default timeout of 10 seconds is used.
+func NewIPResolver(echo string, lookup LookupFunc, timeout time.Duration) *IPResolver {
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	return &IPResolver{echo: echo, lookup: lookup, timeout: timeout}
+}
+
+// Country returns the country of the exit IP address of the proxy.
+func (r *IPResolver) Country(ctx context.Context, proxy *proxym.Proxy) (string, error) {
+	body, err := get(ctx, proxy, r.echo, r.timeout)
+	if err != nil {
+		return "", err
+	}
+	ip := net.ParseIP(strings.TrimSpace(string(body)))
+	if ip == nil {
+		return "", fmt.Errorf("invalid exit ip address: %q", body)
+	}
+	return r.lookup(ip)
+}
+
+// get sends an HTTP GET request to the target through the proxy and returns the body of the response.
+func get(ctx context.Context, proxy *proxym.Proxy, target string, timeout time.Duration) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	client := &http.Client{Transport: newProxyTransport(proxy)}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, maxBodySize))
+}
+
+// newProxyTransport returns a new http.Transport that always uses the proxy.
+func newProxyTransport(proxy *proxym.Proxy) *http.Transport {
+	proxyURL := proxy.URL()
+	return &http.Transport{
+		Proxy: func(*http.Request) (*url.URL, error) {
+			return proxyURL, nil
+		},
+		ProxyConnectHeader: proxy.Metadata().Headers(),
+		DisableKeepAlives:  true,
+	}
+}
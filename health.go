@@ -0,0 +1,71 @@
+package proxym
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// defaultHealthHalfLife is the half-life of the recency weight of DefaultHealthScore.
+const defaultHealthHalfLife = time.Hour
+
+// neutralHealth is the health score of a proxy without recent statistics.
+const neutralHealth = 0.5
+
+// ProxyView is a point-in-time view of a proxy with its statistics and health score.
+type ProxyView struct {
+	Proxy *Proxy
+	Stats StatsSnapshot
+	Score float64
+}
+
+// HealthScoreFunc is a function that returns the health score of the proxy, the higher the healthier.
+type HealthScoreFunc func(proxy *Proxy, stats StatsSnapshot, now time.Time) float64
+
+// DefaultHealthScore returns the health score of the proxy in [0, 1].
+//
+// The formula is:
+//
+//	raw = SuccessRate / (1 + ConsecutiveErrors)
+//	recency = 0.5 ^ (time since LastUsed / 1h)
+//	score = recency * raw + (1 - recency) * 0.5
+//
+// So the consecutive errors quickly lower the score, and the statistics of a proxy that has not been used
+// for a long time are trusted less and its score tends to the neutral 0.5. A proxy that has never been used scores 0.5.
+func DefaultHealthScore(_ *Proxy, stats StatsSnapshot, now time.Time) float64 {
+	if stats.TotalRequests == 0 {
+		return neutralHealth
+	}
+	raw := stats.SuccessRate() / float64(1+stats.ConsecutiveErrors)
+	age := max(now.Sub(stats.LastUsed), 0)
+	recency := math.Pow(0.5, float64(age)/float64(defaultHealthHalfLife)) //nolint: mnd // half of the weight
+	return recency*raw + (1-recency)*neutralHealth
+}
+
+// RankByHealth returns the full fleet, that is, the global proxies and the proxies of all resources,
+// sorted by the health score, the worst first.
+//
+// The score is computed by DefaultHealthScore or by the function set by WithHealthScore.
+// Proxies with equal scores are ordered by url.
+func (pm *ProxyManagerImpl) RankByHealth() []ProxyView {
+	score := pm.healthScore
+	if score == nil {
+		score = DefaultHealthScore
+	}
+
+	now := pm.clock.Now()
+	fleet := pm.fleet()
+	views := make([]ProxyView, 0, len(fleet))
+	for _, p := range fleet {
+		stats := p.Stats().Snapshot()
+		views = append(views, ProxyView{Proxy: p, Stats: stats, Score: score(p, stats, now)})
+	}
+
+	sort.SliceStable(views, func(i, j int) bool {
+		if views[i].Score != views[j].Score {
+			return views[i].Score < views[j].Score
+		}
+		return views[i].Proxy.String() < views[j].Proxy.String()
+	})
+	return views
+}
@@ -0,0 +1,52 @@
+package proxym_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nezbut/proxym"
+	"github.com/nezbut/proxym/selects"
+)
+
+func TestRankByHealth(t *testing.T) {
+	clock := newFakeClock()
+	now := clock.Now()
+	healthy := selects.NewSeededProxy("http://healthy:8080", proxym.StatsSnapshot{
+		TotalRequests: 10, SuccessCount: 10, LastUsed: now,
+	})
+	flaky := selects.NewSeededProxy("http://flaky:8080", proxym.StatsSnapshot{
+		TotalRequests: 10, SuccessCount: 8, ErrorCount: 2, LastUsed: now,
+	})
+	failing := selects.NewSeededProxy("http://failing:8080", proxym.StatsSnapshot{
+		TotalRequests: 10, SuccessCount: 5, ErrorCount: 5, ConsecutiveErrors: 3, LastUsed: now,
+	})
+	// The errors of a proxy unused for long are trusted less, its score is near the neutral 0.5.
+	stale := selects.NewSeededProxy("http://stale:8080", proxym.StatsSnapshot{
+		TotalRequests: 10, ErrorCount: 10, LastUsed: now.Add(-10 * time.Hour),
+	})
+	unused := proxym.NewProxyStr("http://unused:8080", nil)
+	fleet := []*proxym.Proxy{healthy, flaky, failing, stale, unused}
+
+	pm := newManager(fleet, proxym.WithClock(clock))
+	assertRanking(t, pm.RankByHealth(), failing, stale, unused, flaky, healthy)
+
+	// The custom score ranks the busiest proxies first.
+	pm = newManager(fleet, proxym.WithClock(clock),
+		proxym.WithHealthScore(func(_ *proxym.Proxy, stats proxym.StatsSnapshot, _ time.Time) float64 {
+			return -float64(stats.TotalRequests)
+		}))
+	// The ties are ordered by url.
+	assertRanking(t, pm.RankByHealth(), failing, flaky, healthy, stale, unused)
+}
+
+func assertRanking(t *testing.T, views []proxym.ProxyView, want ...*proxym.Proxy) {
+	t.Helper()
+	if len(views) != len(want) {
+		t.Fatalf("got %d proxies ranked, want %d", len(views), len(want))
+	}
+	for i, p := range want {
+		if views[i].Proxy != p {
+			t.Errorf("rank %d: got %s with the score %.3f, want %s", i, views[i].Proxy.URL(), views[i].Score, p.URL())
+		}
+	}
+}
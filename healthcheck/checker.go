@@ -0,0 +1,94 @@
+package healthcheck
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/nezbut/proxym"
+)
+
+// ErrUnhealthy is returned by the checkers when the proxy is unhealthy.
+var ErrUnhealthy = errors.New("proxy is unhealthy")
+
+// defaultCheckTimeout is the default timeout of a check.
+const defaultCheckTimeout = 10 * time.Second
+
+// HealthChecker is an interface for proxy health checkers.
+type HealthChecker interface {
+	// Check returns nil if the proxy is healthy.
+	Check(ctx context.Context, proxy *proxym.Proxy) error
+}
+
+// CheckerFunc is an adapter to allow the use of ordinary functions as HealthChecker.
+type CheckerFunc func(ctx context.Context, proxy *proxym.Proxy) error
+
+// Check calls f(ctx, proxy).
+func (f CheckerFunc) Check(ctx context.Context, proxy *proxym.Proxy) error {
+	return f(ctx, proxy)
+}
+
+// HTTPChecker is a HealthChecker that sends an HTTP GET request to the target through the proxy.
+//
+// The proxy is healthy if the response status code is 2xx or 3xx.
+type HTTPChecker struct {
+	target  string
+	timeout time.Duration
+}
+
+// NewHTTPChecker returns a new HTTPChecker.
+//
+// If the timeout is less than or equal to 0, the default timeout of 10 seconds is used.
+func NewHTTPChecker(target string, timeout time.Duration) *HTTPChecker {
+	if timeout <= 0 {
+		timeout = defaultCheckTimeout
+	}
+	return &HTTPChecker{target: target, timeout: timeout}
+}
+
+// Check sends an HTTP GET request to the target through the proxy.
+func (c *HTTPChecker) Check(ctx context.Context, proxy *proxym.Proxy) error {
+	_, err := c.CheckProxy(ctx, proxy)
+	return err
+}
+
+// CheckProxy sends an HTTP GET request to the target through the proxy like Check
+// and returns the status code of the response, zero if there is none, see proxym.ProxyChecker.
+func (c *HTTPChecker) CheckProxy(ctx context.Context, proxy *proxym.Proxy) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.target, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	client := &http.Client{Transport: newProxyTransport(proxy)}
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %w", ErrUnhealthy, err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return resp.StatusCode, fmt.Errorf("%w: status code %d", ErrUnhealthy, resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// newProxyTransport returns a new http.Transport that always uses the proxy.
+func newProxyTransport(proxy *proxym.Proxy) *http.Transport {
+	proxyURL := proxy.URL()
+	return &http.Transport{
+		Proxy: func(*http.Request) (*url.URL, error) {
+			return proxyURL, nil
+		},
+		ProxyConnectHeader: proxy.Metadata().Headers(),
+		DisableKeepAlives:  true,
+	}
+}
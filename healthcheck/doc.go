@@ -0,0 +1,2 @@
+// Package healthcheck provides periodic health checks of the proxies of a proxym.ProxyManagerImpl.
+package healthcheck
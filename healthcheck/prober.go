@@ -0,0 +1,194 @@
+package healthcheck
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+	"time"
+
+	"github.com/nezbut/proxym"
+)
+
+// LatencyProber periodically measures the connection duration and the time to the first byte
+// of a request to the reference target through each proxy of the fleet and stores them
+// to the statistics of the proxy, see proxym.ProxyStats.ObserveProbe.
+//
+// The probes are independent of the real traffic, so the latency-based selection works for the idle proxies too,
+// see selects.NewProbedFastestSelectFactory and proxym.ProbeStats.SpeedScore.
+// The proxies are not disabled or enabled by the probes.
+type LatencyProber struct {
+	fleet       FleetProvider
+	target      string
+	interval    time.Duration
+	concurrency int
+	timeout     time.Duration
+	cancel      context.CancelFunc
+	done        chan struct{}
+	mu          sync.Mutex
+}
+
+// ProberOption is option for LatencyProber.
+type ProberOption func(*LatencyProber)
+
+// WithProbeInterval sets the interval between the probes of the fleet, by default 1 minute.
+// A non-positive interval is replaced by the default.
+func WithProbeInterval(interval time.Duration) ProberOption {
+	return func(p *LatencyProber) {
+		p.interval = interval
+	}
+}
+
+// WithProbeConcurrency sets the maximum number of concurrent probes, by default 10.
+func WithProbeConcurrency(concurrency int) ProberOption {
+	return func(p *LatencyProber) {
+		p.concurrency = concurrency
+	}
+}
+
+// WithProbeTimeout sets the timeout of a probe, by default 10 seconds.
+func WithProbeTimeout(timeout time.Duration) ProberOption {
+	return func(p *LatencyProber) {
+		p.timeout = timeout
+	}
+}
+
+// NewLatencyProber returns a new LatencyProber of the fleet with the reference target url.
+func NewLatencyProber(fleet FleetProvider, target string, opts ...ProberOption) *LatencyProber {
+	p := &LatencyProber{
+		fleet:       fleet,
+		target:      target,
+		interval:    defaultInterval,
+		concurrency: defaultConcurrency,
+		timeout:     defaultCheckTimeout,
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	if p.concurrency <= 0 {
+		p.concurrency = 1
+	}
+	if p.interval <= 0 {
+		p.interval = defaultInterval
+	}
+	return p
+}
+
+// Start starts the periodic probes in the background, the first probe runs immediately.
+//
+// The probes run until the context is canceled or Stop is called.
+// Calling Start on a started LatencyProber does nothing.
+func (p *LatencyProber) Start(ctx context.Context) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.cancel != nil {
+		return
+	}
+
+	ctx, p.cancel = context.WithCancel(ctx)
+	p.done = make(chan struct{})
+	go p.run(ctx, p.done)
+}
+
+// Stop stops the periodic probes and waits for the running probes to finish.
+func (p *LatencyProber) Stop() {
+	p.mu.Lock()
+	cancel, done := p.cancel, p.done
+	p.cancel, p.done = nil, nil
+	p.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+		<-done
+	}
+}
+
+// ProbeAll probes each proxy of the fleet once and waits for the probes to finish.
+func (p *LatencyProber) ProbeAll(ctx context.Context) {
+	sem := make(chan struct{}, p.concurrency)
+	var wg sync.WaitGroup
+
+	for _, proxy := range p.fleet.Fleet() {
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			return
+		case sem <- struct{}{}:
+		}
+
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			p.probe(ctx, proxy)
+		}()
+	}
+	wg.Wait()
+}
+
+// Probe measures the connection duration and the time to the first byte of the request to the target
+// through the proxy. It returns an error wrapping ErrUnhealthy if the request fails
+// or the response status code is 4xx or 5xx.
+func (p *LatencyProber) Probe(ctx context.Context, proxy *proxym.Proxy) (time.Duration, time.Duration, error) {
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	var start, connectStart, connectDone, firstByte time.Time
+	trace := &httptrace.ClientTrace{
+		ConnectStart:         func(string, string) { connectStart = time.Now() },
+		ConnectDone:          func(string, string, error) { connectDone = time.Now() },
+		GotFirstResponseByte: func() { firstByte = time.Now() },
+	}
+	req, err := http.NewRequestWithContext(httptrace.WithClientTrace(ctx, trace), http.MethodGet, p.target, nil)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	client := &http.Client{Transport: newProxyTransport(proxy)}
+	start = time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, 0, fmt.Errorf("%w: %w", ErrUnhealthy, err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return 0, 0, fmt.Errorf("%w: status code %d", ErrUnhealthy, resp.StatusCode)
+	}
+	return connectDone.Sub(connectStart), firstByte.Sub(start), nil
+}
+
+// run runs the periodic probes until the context is canceled.
+func (p *LatencyProber) run(ctx context.Context, done chan struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		p.ProbeAll(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// probe probes the proxy and stores the latencies to its statistics.
+func (p *LatencyProber) probe(ctx context.Context, proxy *proxym.Proxy) {
+	connect, ttfb, err := p.Probe(ctx, proxy)
+	if ctx.Err() != nil {
+		return
+	}
+	if err != nil {
+		proxy.Stats().ObserveProbeFailure()
+		return
+	}
+	proxy.Stats().ObserveProbe(connect, ttfb)
+}
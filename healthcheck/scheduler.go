@@ -0,0 +1,172 @@
+package healthcheck
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/nezbut/proxym"
+)
+
+// Default settings of the Scheduler.
+const (
+	defaultInterval    = time.Minute
+	defaultConcurrency = 10
+)
+
+// FleetProvider is an interface that provides the proxies to check, for example proxym.ProxyManagerImpl.
+type FleetProvider interface {
+	// Fleet returns the proxies to check.
+	Fleet() []*proxym.Proxy
+}
+
+// Scheduler periodically checks each proxy of the fleet with the HealthChecker
+// and disables the unhealthy proxies and enables the healthy ones.
+//
+// Note that a proxy disabled manually is enabled by the Scheduler when it becomes healthy.
+type Scheduler struct {
+	fleet       FleetProvider
+	checker     HealthChecker
+	interval    time.Duration
+	concurrency int
+	onResult    func(proxy *proxym.Proxy, err error)
+	cancel      context.CancelFunc
+	done        chan struct{}
+	mu          sync.Mutex
+}
+
+// Option is option for Scheduler.
+type Option func(*Scheduler)
+
+// WithInterval sets the interval between the checks of the fleet, by default 1 minute.
+// A non-positive interval is replaced by the default.
+func WithInterval(interval time.Duration) Option {
+	return func(s *Scheduler) {
+		s.interval = interval
+	}
+}
+
+// WithConcurrency sets the maximum number of concurrent checks, by default 10.
+func WithConcurrency(concurrency int) Option {
+	return func(s *Scheduler) {
+		s.concurrency = concurrency
+	}
+}
+
+// WithOnResult sets the function called with the result of each check, err is nil if the proxy is healthy.
+func WithOnResult(onResult func(proxy *proxym.Proxy, err error)) Option {
+	return func(s *Scheduler) {
+		s.onResult = onResult
+	}
+}
+
+// NewScheduler returns a new Scheduler.
+func NewScheduler(fleet FleetProvider, checker HealthChecker, opts ...Option) *Scheduler {
+	s := &Scheduler{
+		fleet:       fleet,
+		checker:     checker,
+		interval:    defaultInterval,
+		concurrency: defaultConcurrency,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if s.concurrency <= 0 {
+		s.concurrency = 1
+	}
+	if s.interval <= 0 {
+		s.interval = defaultInterval
+	}
+	return s
+}
+
+// Start starts the periodic checks in the background, the first check runs immediately.
+//
+// The checks run until the context is canceled or Stop is called.
+// Calling Start on a started Scheduler does nothing.
+func (s *Scheduler) Start(ctx context.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cancel != nil {
+		return
+	}
+
+	ctx, s.cancel = context.WithCancel(ctx)
+	s.done = make(chan struct{})
+	go s.run(ctx, s.done)
+}
+
+// Stop stops the periodic checks and waits for the running checks to finish.
+func (s *Scheduler) Stop() {
+	s.mu.Lock()
+	cancel, done := s.cancel, s.done
+	s.cancel, s.done = nil, nil
+	s.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+		<-done
+	}
+}
+
+// CheckAll checks each proxy of the fleet once and waits for the checks to finish.
+func (s *Scheduler) CheckAll(ctx context.Context) {
+	sem := make(chan struct{}, s.concurrency)
+	var wg sync.WaitGroup
+
+	for _, p := range s.fleet.Fleet() {
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			return
+		case sem <- struct{}{}:
+		}
+
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			s.check(ctx, p)
+		}()
+	}
+	wg.Wait()
+}
+
+// run runs the periodic checks until the context is canceled.
+func (s *Scheduler) run(ctx context.Context, done chan struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		s.CheckAll(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// check checks the proxy and disables or enables it by the result.
+func (s *Scheduler) check(ctx context.Context, proxy *proxym.Proxy) {
+	err := s.checker.Check(ctx, proxy)
+	if ctx.Err() != nil {
+		return
+	}
+
+	if err != nil {
+		if !proxy.IsDisabled() {
+			proxy.Disable()
+		}
+	} else if proxy.IsDisabled() {
+		proxy.Enable()
+	}
+
+	if s.onResult != nil {
+		s.onResult(proxy, err)
+	}
+}
@@ -0,0 +1,61 @@
+package proxym_test
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/nezbut/proxym"
+	"github.com/nezbut/proxym/rotations"
+	"github.com/nezbut/proxym/selects"
+)
+
+// newManager returns a ProxyManagerImpl with the proxies, the default strategies and the options.
+func newManager(proxies []*proxym.Proxy, opts ...proxym.ProxyManagerImplOption) *proxym.ProxyManagerImpl {
+	return proxym.NewProxyManager(append([]proxym.ProxyManagerImplOption{
+		proxym.WithProxies(proxies...),
+		proxym.WithRotationStrategy(rotations.DefaultRotationStrategy()),
+		proxym.WithSelectStrategy(selects.DefaultSelectStrategy()),
+	}, opts...)...)
+}
+
+// newProxies returns n proxies with distinct urls.
+func newProxies(n int) []*proxym.Proxy {
+	proxies := make([]*proxym.Proxy, 0, n)
+	for i := range n {
+		proxies = append(proxies, proxym.NewProxyStr(fmt.Sprintf("http://proxy%d:8080", i), nil))
+	}
+	return proxies
+}
+
+// fakeClock is a proxym.Clock with the manually set time.
+type fakeClock struct {
+	now time.Time
+	mu  sync.Mutex
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Unix(1_000_000, 0)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// newResource returns a ResourceConfig of the domain with the proxies and the default strategies.
+func newResource(domain string, proxies ...*proxym.Proxy) *proxym.ResourceConfig {
+	return proxym.NewResourceConfig(true,
+		proxym.WithDomain(domain),
+		proxym.WithResourceProxies(proxies...),
+		proxym.WithResourceRotationStrategy(rotations.DefaultRotationStrategy()),
+		proxym.WithResourceSelectStrategy(selects.DefaultSelectStrategy()),
+	)
+}
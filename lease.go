@@ -0,0 +1,67 @@
+package proxym
+
+import (
+	"context"
+	"sync"
+)
+
+// ProxyLease is a proxy checked out from the ProxyManagerImpl by Acquire.
+//
+// It lets the users that do not send the requests through ProxyTransport, for example non-HTTP clients,
+// use the proxy explicitly and return it with the outcome that feeds the proxy statistics.
+// The lease counts as an in-flight request through the proxy until it is released, see Proxy.InFlight.
+type ProxyLease struct {
+	pm      *ProxyManagerImpl
+	info    *RequestInfo
+	proxy   *Proxy
+	release func()
+	once    sync.Once
+}
+
+// Acquire checks out the next available proxy by domain like GetNextProxy and returns its lease.
+//
+// The lease must be released by ProxyLease.Release when the proxy is no longer used.
+func (pm *ProxyManagerImpl) Acquire(domain string) (*ProxyLease, error) {
+	return pm.AcquireContext(context.Background(), domain)
+}
+
+// AcquireContext checks out the next available proxy by domain like GetNextProxyContext and returns its lease,
+// the selection is aborted when the context is done.
+func (pm *ProxyManagerImpl) AcquireContext(ctx context.Context, domain string) (*ProxyLease, error) {
+	proxy, err := pm.GetNextProxyContext(ctx, domain)
+	if err != nil {
+		return nil, err
+	}
+
+	proxy.acquire()
+	info := domainInfo(domain)
+	done := pm.trackRequest(info, proxy)
+	return &ProxyLease{
+		pm:    pm,
+		info:  info,
+		proxy: proxy,
+		release: func() {
+			done()
+			proxy.release()
+		},
+	}, nil
+}
+
+// Proxy returns the leased proxy.
+func (l *ProxyLease) Proxy() *Proxy {
+	return l.proxy
+}
+
+// Release returns the proxy with the outcome of its use, nil means success, any error means failure.
+//
+// The outcome is recorded to the statistics of the proxy and of the resource by domain, see ResourceConfig.Stats,
+// like a request without a status code,
+// a success also resets the consecutive cooldowns of the proxy, see Proxy.Cooldown.
+// Only the first call has effect.
+func (l *ProxyLease) Release(result error) {
+	l.once.Do(func() {
+		l.proxy.record(0, result != nil)
+		l.pm.recordResult(l.info, l.proxy, 0, result != nil)
+		l.release()
+	})
+}
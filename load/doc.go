@@ -0,0 +1,2 @@
+// Package load provides loading of the proxy lists from the text files.
+package load
@@ -0,0 +1,62 @@
+package load
+
+import (
+	"context"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/nezbut/proxym"
+)
+
+// FileSource is a proxym.ProxySource that reads the proxy list from the file,
+// the list has the format of LoadFromReader.
+//
+// Unlike LoadFromFile, Fetch fails if any line is invalid, so a file saved halfway
+// does not remove the proxies from the pool, see proxym.ProxyRefresher.
+//
+// The file is read again only when its modification time or size changes, otherwise Fetch returns
+// the proxies of the previous read, so the file can be watched by polling with a short refresh interval:
+//
+//	refresher := proxym.NewProxyRefresher(pm, load.NewFileSource("proxies.txt"),
+//	    proxym.WithRefreshInterval(time.Second),
+//	)
+type FileSource struct {
+	path    string
+	opts    []Option
+	modTime time.Time
+	size    int64
+	proxies []*proxym.Proxy
+	mu      sync.Mutex
+}
+
+// NewFileSource returns a new FileSource with the options of the loading.
+func NewFileSource(path string, opts ...Option) *FileSource {
+	return &FileSource{path: path, opts: opts}
+}
+
+// Fetch reads and returns the proxies of the list if the file has changed since the previous read.
+func (s *FileSource) Fetch(ctx context.Context) ([]*proxym.Proxy, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(s.path)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.proxies != nil && info.ModTime().Equal(s.modTime) && info.Size() == s.size {
+		return s.proxies, nil
+	}
+	proxies, err := LoadFromFile(s.path, s.opts...)
+	if err != nil {
+		return nil, err
+	}
+	if proxies == nil {
+		proxies = []*proxym.Proxy{}
+	}
+	s.modTime, s.size, s.proxies = info.ModTime(), info.Size(), proxies
+	return proxies, nil
+}
@@ -0,0 +1,67 @@
+package load
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/nezbut/proxym"
+)
+
+// ErrUnexpectedStatus is the error of the response of the proxy list with a non-2xx status code.
+var ErrUnexpectedStatus = errors.New("unexpected status")
+
+// HTTPSource is a proxym.ProxySource that downloads the proxy list from the url,
+// the list has the format of LoadFromReader.
+//
+// Unlike LoadFromReader, Fetch fails if any line is invalid, so a broken or truncated download
+// does not remove the proxies from the pool, see proxym.ProxyRefresher.
+type HTTPSource struct {
+	url    string
+	client *http.Client
+	opts   []Option
+}
+
+// NewHTTPSource returns a new HTTPSource with the options of the loading.
+//
+// If client is nil, http.DefaultClient is used.
+func NewHTTPSource(url string, client *http.Client, opts ...Option) *HTTPSource {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &HTTPSource{url: url, client: client, opts: opts}
+}
+
+// Fetch downloads and returns the proxies of the list.
+func (s *HTTPSource) Fetch(ctx context.Context) ([]*proxym.Proxy, error) {
+	body, err := download(ctx, s.client, s.url)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	proxies, err := LoadFromReader(body, s.opts...)
+	if err != nil {
+		return nil, err
+	}
+	return proxies, nil
+}
+
+// download returns the body of the response to the GET request to the url, it fails on a non-2xx status code.
+func download(ctx context.Context, client *http.Client, url string) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, http.NoBody)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		_ = resp.Body.Close()
+		return nil, fmt.Errorf("%w: %s", ErrUnexpectedStatus, resp.Status)
+	}
+	return resp.Body, nil
+}
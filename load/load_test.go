@@ -0,0 +1,62 @@
+package load_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/nezbut/proxym/load"
+)
+
+func TestLoadFromReader(t *testing.T) {
+	cases := []struct {
+		name     string
+		line     string
+		host     string
+		port     string
+		user     string
+		password string
+	}{
+		{name: "host:port", line: "proxy.example.com:8080", host: "proxy.example.com", port: "8080"},
+		{name: "ip:port:user:pass", line: "1.2.3.4:8080:user:pass", host: "1.2.3.4", port: "8080",
+			user: "user", password: "pass"},
+		{name: "password with colon and at", line: "1.2.3.4:8080:user:p:a@ss", host: "1.2.3.4", port: "8080",
+			user: "user", password: "p:a@ss"},
+		{name: "user:pass@host:port", line: "user:pass@proxy.example.com:3128", host: "proxy.example.com",
+			port: "3128", user: "user", password: "pass"},
+		{name: "trailing comment", line: "proxy.example.com:8080 # office", host: "proxy.example.com", port: "8080"},
+		{name: "ipv6", line: "[2001:db8::1]:8080", host: "2001:db8::1", port: "8080"},
+	}
+	for _, tc := range cases {
+		proxies, err := load.LoadFromReader(strings.NewReader(tc.line))
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		if len(proxies) != 1 {
+			t.Fatalf("%s: got %d proxies, want 1", tc.name, len(proxies))
+		}
+		u := proxies[0].URL()
+		if u.Scheme != "http" || u.Hostname() != tc.host || u.Port() != tc.port {
+			t.Errorf("%s: got %s://%s:%s, want http://%s:%s", tc.name, u.Scheme, u.Hostname(), u.Port(), tc.host, tc.port)
+		}
+		password, _ := u.User.Password()
+		if u.User.Username() != tc.user || password != tc.password {
+			t.Errorf("%s: got credentials %q:%q, want %q:%q", tc.name, u.User.Username(), password, tc.user, tc.password)
+		}
+	}
+}
+
+func TestLoadFromReaderInvalidLine(t *testing.T) {
+	list := "# the office proxies\nproxy.example.com:8080\nproxy.example.com:99999\n"
+	proxies, err := load.LoadFromReader(strings.NewReader(list))
+	if len(proxies) != 1 {
+		t.Errorf("got %d proxies, want the valid line loaded", len(proxies))
+	}
+	var lineErr *load.LineError
+	if !errors.As(err, &lineErr) {
+		t.Fatalf("got error %v, want *LineError", err)
+	}
+	if lineErr.Line != 3 || !errors.Is(err, load.ErrInvalidProxy) {
+		t.Errorf("got error %v on line %d, want ErrInvalidProxy on line 3", lineErr.Err, lineErr.Line)
+	}
+}
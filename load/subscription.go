@@ -0,0 +1,90 @@
+package load
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/nezbut/proxym"
+)
+
+// ErrInvalidSubscription is the error of a subscription whose payload is not base64.
+var ErrInvalidSubscription = errors.New("invalid subscription")
+
+// subscriptionSchemes are the schemes of the proxies loaded from the subscriptions by default,
+// the links of other protocols, like vmess:// or trojan://, are skipped.
+var subscriptionSchemes = []string{"http", "https", "socks4", "socks4a", "socks5", "socks5h"}
+
+// subscriptionEncodings are the base64 encodings of the subscription payloads.
+var subscriptionEncodings = []*base64.Encoding{
+	base64.StdEncoding,
+	base64.RawStdEncoding,
+	base64.URLEncoding,
+	base64.RawURLEncoding,
+}
+
+// DecodeSubscription decodes the base64 payload of the subscription into the list of the proxy urls,
+// the standard and url-safe alphabets with and without padding are accepted, the line breaks are ignored.
+func DecodeSubscription(payload []byte) ([]byte, error) {
+	payload = bytes.Join(bytes.Fields(payload), nil)
+	for _, encoding := range subscriptionEncodings {
+		if decoded, err := encoding.DecodeString(string(payload)); err == nil {
+			return decoded, nil
+		}
+	}
+	return nil, ErrInvalidSubscription
+}
+
+// LoadSubscription loads the proxies from the base64 subscription, see DecodeSubscription and LoadFromReader.
+//
+// Only the http, https, socks4 and socks5 proxies are loaded, the links of other protocols are skipped,
+// use WithSchemes to change it.
+func LoadSubscription(r io.Reader, opts ...Option) ([]*proxym.Proxy, error) {
+	payload, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	decoded, err := DecodeSubscription(payload)
+	if err != nil {
+		return nil, err
+	}
+	return LoadFromReader(bytes.NewReader(decoded), append([]Option{WithSchemes(subscriptionSchemes...)}, opts...)...)
+}
+
+// SubscriptionSource is a proxym.ProxySource that downloads the base64 subscription from the url,
+// see LoadSubscription.
+//
+// Like HTTPSource, Fetch fails if any loaded line is invalid.
+type SubscriptionSource struct {
+	url    string
+	client *http.Client
+	opts   []Option
+}
+
+// NewSubscriptionSource returns a new SubscriptionSource with the options of the loading.
+//
+// If client is nil, http.DefaultClient is used.
+func NewSubscriptionSource(url string, client *http.Client, opts ...Option) *SubscriptionSource {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &SubscriptionSource{url: url, client: client, opts: opts}
+}
+
+// Fetch downloads and returns the proxies of the subscription.
+func (s *SubscriptionSource) Fetch(ctx context.Context) ([]*proxym.Proxy, error) {
+	body, err := download(ctx, s.client, s.url)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	proxies, err := LoadSubscription(body, s.opts...)
+	if err != nil {
+		return nil, err
+	}
+	return proxies, nil
+}
@@ -0,0 +1,40 @@
+package load_test
+
+import (
+	"encoding/base64"
+	"errors"
+	"testing"
+
+	"github.com/nezbut/proxym/load"
+)
+
+func TestDecodeSubscription(t *testing.T) {
+	// The comment makes the standard encoding contain "+", "/" and the padding.
+	list := "http://a.example.com:8080 # ~?~?>\nsocks5://b.example.com:1080\n"
+	wrapped := base64.StdEncoding.EncodeToString([]byte(list))
+	wrapped = wrapped[:20] + "\r\n" + wrapped[20:40] + "\n" + wrapped[40:]
+
+	cases := []struct {
+		name    string
+		payload string
+	}{
+		{name: "std", payload: base64.StdEncoding.EncodeToString([]byte(list))},
+		{name: "url-safe", payload: base64.URLEncoding.EncodeToString([]byte(list))},
+		{name: "unpadded", payload: base64.RawStdEncoding.EncodeToString([]byte(list))},
+		{name: "url-safe unpadded", payload: base64.RawURLEncoding.EncodeToString([]byte(list))},
+		{name: "line-wrapped", payload: wrapped},
+	}
+	for _, tc := range cases {
+		decoded, err := load.DecodeSubscription([]byte(tc.payload))
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		if string(decoded) != list {
+			t.Errorf("%s: got %q, want %q", tc.name, decoded, list)
+		}
+	}
+
+	if _, err := load.DecodeSubscription([]byte("not base64!")); !errors.Is(err, load.ErrInvalidSubscription) {
+		t.Errorf("got error %v, want ErrInvalidSubscription", err)
+	}
+}
@@ -0,0 +1,149 @@
+package proxym
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// Logging attribute keys.
+const (
+	logKeyProxy    = "proxy"
+	logKeyDomain   = "domain"
+	logKeyStrategy = "strategy"
+)
+
+// LogLevels are the levels of the log records of the ProxyManagerImpl and ProxyTransport,
+// see WithLogger and WithTransportLogger.
+type LogLevels struct {
+	// Select is the level of the selections.
+	Select slog.Level
+	// Rotate is the level of the rotations.
+	Rotate slog.Level
+	// Error is the level of the failed selections.
+	Error slog.Level
+	// Pool is the level of the pool changes: the added and removed proxies, the disabled and enabled proxies,
+	// the exhausted and recovered pool.
+	Pool slog.Level
+	// Request is the level of the failed requests of ProxyTransport.
+	Request slog.Level
+}
+
+// DefaultLogLevels returns the default LogLevels: the selections and the rotations are logged at the debug level,
+// the pool changes at the info level, the errors and the failed requests at the warn level.
+func DefaultLogLevels() LogLevels {
+	return LogLevels{
+		Select:  slog.LevelDebug,
+		Rotate:  slog.LevelDebug,
+		Error:   slog.LevelWarn,
+		Pool:    slog.LevelInfo,
+		Request: slog.LevelWarn,
+	}
+}
+
+// WithLogger sets the logger of the ProxyManagerImpl, the selections, the rotations, the failed selections
+// and the pool changes are logged with the proxy, domain and strategy attributes.
+// The proxy urls are logged with the redacted passwords.
+//
+// The levels are set by WithLogLevels, by default DefaultLogLevels.
+func WithLogger(logger *slog.Logger) ProxyManagerImplOption {
+	return func(pm *ProxyManagerImpl) {
+		pm.logger = logger
+	}
+}
+
+// WithLogLevels sets the levels of the log records of the ProxyManagerImpl, see WithLogger.
+func WithLogLevels(levels LogLevels) ProxyManagerImplOption {
+	return func(pm *ProxyManagerImpl) {
+		pm.logLevels = levels
+	}
+}
+
+// WithTransportLogger sets the logger of ProxyTransport, the failed requests and the failure cooldowns
+// are logged with the proxy and domain attributes at the levels, see DefaultLogLevels.
+func WithTransportLogger(logger *slog.Logger, levels LogLevels) ProxyTransportOption {
+	return func(pt *ProxyTransport) {
+		pt.logger = logger
+		pt.logLevels = levels
+	}
+}
+
+// watchLogs registers the callbacks that log the events of the ProxyManagerImpl to its logger.
+func (pm *ProxyManagerImpl) watchLogs() {
+	if pm.logger == nil {
+		return
+	}
+	logger, levels := pm.logger, pm.logLevels
+	ctx := context.Background()
+
+	pm.OnSelect(func(e SelectEvent) {
+		logger.LogAttrs(ctx, levels.Select, "proxy selected",
+			proxyLogAttr(e.Proxy), slog.String(logKeyDomain, e.Domain),
+			slog.String(logKeyStrategy, e.Strategy), slog.Bool("reused", e.Reused),
+		)
+	})
+	pm.OnRotate(func(e RotateEvent) {
+		logger.LogAttrs(ctx, levels.Rotate, "proxy rotated", proxyLogAttr(e.Previous), slog.String(logKeyDomain, e.Domain))
+	})
+	pm.OnError(func(e ErrorEvent) {
+		logger.LogAttrs(ctx, levels.Error, "proxy selection failed",
+			slog.String(logKeyDomain, e.Domain), slog.Any("error", e.Err),
+		)
+	})
+	pm.OnDisable(func(p *Proxy) {
+		attrs := []slog.Attr{proxyLogAttr(p)}
+		if until := p.DisabledUntil(); !until.IsZero() {
+			attrs = append(attrs, slog.Time("until", until))
+		}
+		logger.LogAttrs(ctx, levels.Pool, "proxy disabled", attrs...)
+	})
+	pm.OnEnable(func(p *Proxy) {
+		logger.LogAttrs(ctx, levels.Pool, "proxy enabled", proxyLogAttr(p))
+	})
+	pm.OnPoolExhausted(func() {
+		logger.LogAttrs(ctx, levels.Pool, "proxy pool exhausted")
+	})
+	pm.OnPoolRecovered(func() {
+		logger.LogAttrs(ctx, levels.Pool, "proxy pool recovered")
+	})
+}
+
+// logPoolChange logs the numbers of the added and removed proxies of the global pool if any.
+func (pm *ProxyManagerImpl) logPoolChange(added, removed int) {
+	if pm.logger == nil || (added == 0 && removed == 0) {
+		return
+	}
+	pm.logger.LogAttrs(context.Background(), pm.logLevels.Pool, "proxy pool changed",
+		slog.Int("added", added), slog.Int("removed", removed),
+	)
+}
+
+// logFailedRequest logs the failed request through the proxy.
+func (pt *ProxyTransport) logFailedRequest(
+	ctx context.Context, info *RequestInfo, proxy *Proxy, status int, err error,
+) {
+	if pt.logger == nil {
+		return
+	}
+	attrs := []slog.Attr{proxyLogAttr(proxy), slog.String(logKeyDomain, info.Host)}
+	if status != 0 {
+		attrs = append(attrs, slog.Int("status", status))
+	}
+	if err != nil {
+		attrs = append(attrs, slog.Any("error", err))
+	}
+	pt.logger.LogAttrs(ctx, pt.logLevels.Request, "proxy request failed", attrs...)
+}
+
+// logCooldown logs the failure cooldown of the proxy.
+func (pt *ProxyTransport) logCooldown(ctx context.Context, proxy *Proxy, duration time.Duration) {
+	if pt.logger == nil {
+		return
+	}
+	pt.logger.LogAttrs(ctx, pt.logLevels.Pool, "proxy cooled down", proxyLogAttr(proxy), slog.Duration("for", duration))
+}
+
+// proxyLogAttr returns the log attribute of the proxy url with the redacted password.
+func proxyLogAttr(proxy *Proxy) slog.Attr {
+	return slog.String(logKeyProxy, redactedProxy(proxy))
+}
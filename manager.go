@@ -1,9 +1,13 @@
 package proxym
 
 import (
+	"context"
 	"errors"
-	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
 	"sync"
+	"time"
 )
 
 // ProxyManager is a manager for proxies.
@@ -13,9 +17,13 @@ import (
 type ProxyManager interface {
 	// GetNextProxy returns the next available proxy by domain.
 	GetNextProxy(domain string) (*Proxy, error)
+	// GetNextProxyContext returns the next available proxy by domain like GetNextProxy,
+	// the selection is aborted when the context is done.
+	GetNextProxyContext(ctx context.Context, domain string) (*Proxy, error)
 	// LastUsed Returns the last used proxy.
+	// If the domain is passed, it returns the last used proxy of the resource by domain or of the global pool.
 	// This method may return nil in *Proxy if no proxy has been used.
-	LastUsed() *Proxy
+	LastUsed(domain ...string) *Proxy
 	// GetProxies returns the copied list of proxies.
 	GetProxies() []*Proxy
 }
@@ -26,9 +34,31 @@ type ProxyManagerImpl struct {
 	pMu              sync.RWMutex
 	resources        []*ResourceConfig
 	rMu              sync.RWMutex
-	lastUsed         *Proxy
+	lastUsed         map[*ResourceConfig]*Proxy
+	latest           *Proxy
 	rotationStrategy RotationStrategy
 	selectStrategy   SelectStrategy
+	sMu              sync.RWMutex
+	maxPool          int
+	evictionPolicy   EvictionPolicy
+	defaultMeta      *ProxyMetadata
+	fairShare        *fairShare
+	pool             *poolMonitor
+	priorityTimeouts map[ProxyPriority]time.Duration
+	disablePolicy    DisablePolicy
+	quarantine       quarantine
+	healthScore      HealthScoreFunc
+	clock            Clock
+	counters         selectionCounters
+	refresher        *ProxyRefresher
+	credentials      *credentialRefresher
+	events           eventHooks
+	logger           *slog.Logger
+	logLevels        LogLevels
+	traceDecision    func(*DecisionTrace)
+	statsHalfLife    time.Duration
+	validSchemes     []string
+	duplicatePolicy  DuplicatePolicy
 	mu               sync.RWMutex
 }
 
@@ -43,6 +73,10 @@ type ProxyManagerImpl struct {
 //   - WithRotationStrategy() option during initialization
 //   - WithSelectStrategy() option during initialization
 //   - If you don't set strategies, the constructor will panic
+//   - If the strict validation is enabled and a proxy is invalid, the constructor will panic,
+//     see WithStrictValidation
+//   - If the duplicates are rejected and a proxy is a duplicate, the constructor will panic,
+//     see WithDuplicatePolicy
 //
 // Example minimum working setup:
 //
@@ -63,77 +97,327 @@ func NewProxyManager(opts ...ProxyManagerImplOption) *ProxyManagerImpl {
 	pm := &ProxyManagerImpl{
 		proxies:   make([]*Proxy, 0),
 		resources: make([]*ResourceConfig, 0),
+		lastUsed:  make(map[*ResourceConfig]*Proxy),
+		pool:      &poolMonitor{},
+		clock:     SystemClock{},
+		logLevels: DefaultLogLevels(),
 	}
 	for _, opt := range opts {
 		opt(pm)
 	}
+	pm.watchLogs()
 	if pm.rotationStrategy == nil || pm.selectStrategy == nil {
 		panic("rotationStrategy and selectStrategy must be set")
 	}
+	if err := pm.ValidateProxies(pm.proxies...); err != nil {
+		panic(err)
+	}
+	var err error
+	if pm.proxies, err = pm.duplicatePolicy.dedupe(nil, pm.proxies); err != nil {
+		panic(err)
+	}
+	pm.applyDefaultMetadata(pm.proxies)
+	if pm.maxPool > 0 {
+		pm.proxies, _ = pm.evictionPolicy.evict(nil, pm.proxies, pm.maxPool)
+	}
+	pm.watchProxies(pm.proxies)
+	pm.watchResources(pm.resources)
+	pm.pool.usable = pm.HasUsableProxy()
 	return pm
 }
 
 // GetNextProxy returns the next available proxy.
 // If the resource by domain is not found global is returned.
+// If the resource has no usable proxy and falls back to the global pool, see WithResourceFallbackToGlobal,
+// global is returned too.
 //
 // If SelectStrategy returns nil and err is nil, then there will be an error ErrProxyNotAvailable.
+// The errors are *SelectionError with the domain, use errors.As to get it.
+//
+// A draining, quarantined or banned for the resource last used proxy is always rotated,
+// see Proxy.Drain, Proxy.Quarantine and ResourceConfig.Ban.
 func (pm *ProxyManagerImpl) GetNextProxy(domain string) (*Proxy, error) {
-	if len(pm.proxies) == 0 && len(pm.resources) == 0 {
-		return nil, pm.proxyNotAvailable(ErrEmptyProxyList)
+	return pm.GetNextProxyContext(context.Background(), domain)
+}
+
+// GetNextProxyContext returns the next available proxy like GetNextProxy,
+// the selection is aborted when the context is done.
+//
+// The context is passed to the strategies that implement ContextRotationStrategy and ContextSelectStrategy,
+// for other strategies it is checked before the selection. The domain is passed to the select strategies
+// that implement DomainSelectStrategy. If the context is done,
+// the error is *SelectionError with the error of the context as the cause, so errors.Is(err, context.Canceled) works.
+func (pm *ProxyManagerImpl) GetNextProxyContext(ctx context.Context, domain string) (*Proxy, error) {
+	proxy, _, err := pm.selectProxy(ctx, domainInfo(domain))
+	return proxy, err
+}
+
+// GetNextProxyRequest returns the next available proxy for the request like GetNextProxyContext
+// with the request context, the resource is matched by the whole request, see ResourceConfig.Match.
+func (pm *ProxyManagerImpl) GetNextProxyRequest(req *http.Request) (*Proxy, error) {
+	proxy, _, err := pm.selectProxy(req.Context(), NewRequestInfo(req))
+	return proxy, err
+}
+
+// selectProxy returns the next available proxy for the request like GetNextProxyContext and how it was selected.
+func (pm *ProxyManagerImpl) selectProxy(ctx context.Context, info *RequestInfo) (*Proxy, selection, error) {
+	if pm.traceDecision == nil {
+		return pm.selectTraced(ctx, info, nil)
 	}
-	resource, err := pm.getResourceByDomain(domain)
+	trace := &DecisionTrace{Domain: info.Host}
+	proxy, sel, err := pm.selectTraced(ctx, info, trace)
+	pm.traceDecision(trace)
+	return proxy, sel, err
+}
+
+// selectTraced selects the next available proxy for the request, records the selection to the trace if it is not nil
+// and calls the callbacks of the events.
+func (pm *ProxyManagerImpl) selectTraced(
+	ctx context.Context, info *RequestInfo, trace *DecisionTrace,
+) (*Proxy, selection, error) {
+	proxy, sel, err := pm.selectNext(ctx, info, trace)
+	trace.finish(proxy, err)
+	pm.emitSelection(info, proxy, sel, err)
+	return proxy, sel, err
+}
+
+// selectNext selects the next available proxy for the request, see selectProxy.
+func (pm *ProxyManagerImpl) selectNext(
+	ctx context.Context, info *RequestInfo, trace *DecisionTrace,
+) (*Proxy, selection, error) {
+	domain := info.Host
+	if err := ctx.Err(); err != nil {
+		pm.counters.failures.Add(1)
+		return nil, selection{}, pm.proxyNotAvailable(domain, false, err)
+	}
+	if pm.isEmpty() {
+		pm.counters.failures.Add(1)
+		return nil, selection{}, pm.proxyNotAvailable(domain, false, ErrEmptyProxyList)
+	}
+	resource, err := pm.getResource(info)
 	isNotFound := errors.Is(err, ErrResourceNotFound)
 	if err != nil && !isNotFound {
-		return nil, pm.proxyNotAvailable(err)
+		pm.counters.failures.Add(1)
+		return nil, selection{}, pm.proxyNotAvailable(domain, false, err)
+	}
+	rotationStrategy, selectStrategy := pm.strategies()
+	var provider SelectStrategyProxyProvider = pm
+	counters := &pm.counters
+	matched := resource
+	if !isNotFound && pm.fallsBack(resource) {
+		resource = nil
+	}
+	trace.resource(matched, matched != nil && resource == nil)
+	if resource != nil {
+		rotationStrategy, selectStrategy = resource.rotationStrategy, resource.selectStrategy
+		provider = unbannedProvider{rc: resource}
+		counters = &resource.counters
 	}
-	lastUsed := pm.LastUsed()
-	var current *Proxy
-
-	if isNotFound { //nolint:nestif // don't
-		if lastUsed != nil && !pm.rotationStrategy.ShouldRotate(lastUsed) {
-			return lastUsed, nil
-		}
 
-		currentProxy, errSelect := pm.selectStrategy.Select()
-		if errSelect != nil {
-			return nil, pm.proxyNotAvailable(errSelect)
+	sel := selection{decision: decisionInitial, strategy: strategyName(selectStrategy), resource: resource}
+	lastUsed := pm.lastUsedOf(resource)
+	if lastUsed != nil {
+		forced := forcedRotation(resource, lastUsed)
+		if forced == "" && !ShouldRotateContext(ctx, rotationStrategy, lastUsed) {
+			sel.decision = decisionReused
+			trace.rotation(lastUsed, sel, "", rotationStrategy)
+			if err := pm.refreshCredentials(ctx, lastUsed); err != nil {
+				counters.failures.Add(1)
+				return nil, sel, pm.proxyNotAvailable(domain, !isNotFound, err)
+			}
+			trace.chosen("the last used proxy is reused")
+			pm.setLastUsed(resource, lastUsed)
+			return lastUsed, sel, nil
 		}
+		sel.decision = decisionRotated
+		trace.rotation(lastUsed, sel, forced, rotationStrategy)
+		counters.rotations.Add(1)
+		pm.events.onRotate.emit(RotateEvent{Domain: domain, Resource: resource, Previous: lastUsed})
+	} else {
+		trace.rotation(nil, sel, "", rotationStrategy)
+	}
+	if trace != nil {
+		trace.candidates(provider.GetProxies(), pm.clock.Now())
+	}
 
-		current = currentProxy
+	current := pm.quarantine.probe(provider.GetProxies(), pm.clock.Now())
+	if current != nil {
+		trace.chosen("the quarantined proxy is probed, see WithQuarantine")
 	} else {
-		if lastUsed != nil && !resource.rotationStrategy.ShouldRotate(lastUsed) {
-			return lastUsed, nil
+		current, err = SelectDomain(ctx, domain, selectStrategy)
+		if err != nil {
+			counters.failures.Add(1)
+			if ctx.Err() == nil {
+				pm.checkPool()
+			}
+			return nil, sel, pm.proxyNotAvailable(domain, !isNotFound, err)
 		}
 
-		currentProxy, errSelect := resource.selectStrategy.Select()
-		if errSelect != nil {
-			return nil, pm.proxyNotAvailable(errSelect)
+		if current == nil {
+			counters.failures.Add(1)
+			return nil, sel, pm.proxyNotAvailable(domain, !isNotFound, nil)
+		}
+		trace.chosen("selected by the select strategy " + sel.strategy)
+	}
+	if pm.fairShare != nil {
+		selected := current
+		current = pm.fairShare.constrain(resource, provider.GetProxies(), current, pm.isEligible)
+		if current != selected {
+			trace.chosen("replaced by the fair share of the resource, see WithResourceShare")
 		}
-
-		current = currentProxy
 	}
+	if err := pm.refreshCredentials(ctx, current); err != nil {
+		counters.failures.Add(1)
+		return nil, sel, pm.proxyNotAvailable(domain, !isNotFound, err)
+	}
+
+	counters.selections.Add(1)
+	pm.setLastUsed(resource, current)
+	return current, sel, nil
+}
+
+// fallsBack returns true if the selection for the resource falls back to the global pool,
+// that is, the resource has no usable proxy that is not banned, see WithResourceFallbackToGlobal.
+func (pm *ProxyManagerImpl) fallsBack(resource *ResourceConfig) bool {
+	return resource != nil && resource.fallsBackToGlobal() && !pm.hasUsableProxy(resource.unbannedProxies())
+}
+
+// isEligible returns true if the proxy can be selected, that is, it is not disabled, draining,
+// quarantined or expired.
+func (pm *ProxyManagerImpl) isEligible(proxy *Proxy) bool {
+	return !proxy.IsDisabled() && !proxy.IsDraining() && !proxy.IsQuarantined() &&
+		!proxy.Metadata().IsExpired(pm.clock.Now())
+}
 
-	if current == nil {
-		return nil, ErrProxyNotAvailable
+// forcedRotation returns the reason why the last used proxy must be rotated whatever the rotation strategy decides,
+// that is, it is draining, quarantined or banned for the resource,
+// see Proxy.Drain, Proxy.Quarantine and ResourceConfig.Ban.
+// It returns an empty string if the rotation strategy decides.
+func forcedRotation(resource *ResourceConfig, lastUsed *Proxy) string {
+	switch {
+	case lastUsed.IsDraining():
+		return "the last used proxy is draining"
+	case lastUsed.IsQuarantined():
+		return "the last used proxy is quarantined"
+	case resource != nil && resource.IsBanned(lastUsed):
+		return "the last used proxy is banned for the resource"
+	default:
+		return ""
 	}
+}
 
-	if lastUsed != nil {
-		lastUsed.deactivate()
+// refreshCredentials refreshes the credentials of the selected proxy if they are near expiry,
+// see WithCredentialProvider.
+func (pm *ProxyManagerImpl) refreshCredentials(ctx context.Context, proxy *Proxy) error {
+	if pm.credentials == nil {
+		return nil
 	}
-	current.activate()
+	return pm.credentials.refresh(ctx, proxy, pm.clock)
+}
 
-	pm.mu.Lock()
-	defer pm.mu.Unlock()
-	pm.lastUsed = current
-	return current, nil
+// SelectionCounters returns the selection counters of the global pool,
+// the counters of the resources are returned by ResourceConfig.SelectionCounters.
+func (pm *ProxyManagerImpl) SelectionCounters() SelectionCounters {
+	return pm.counters.snapshot()
 }
 
 // LastUsed Returns the last used proxy.
 // This method may return nil in *Proxy if no proxy has been used.
-func (pm *ProxyManagerImpl) LastUsed() *Proxy {
+//
+// The last used proxy is tracked separately for each resource and for the global pool,
+// so the selections for the different resources do not rotate each other's proxy.
+// Without the domain it returns the proxy of the latest selection,
+// with the domain it returns the last used proxy of the resource by domain, see GetResource,
+// or of the global pool if no resource matches the domain.
+// Use ResourceLastUsed for a resource split by the matchers.
+func (pm *ProxyManagerImpl) LastUsed(domain ...string) *Proxy {
+	if len(domain) == 0 {
+		pm.mu.RLock()
+		defer pm.mu.RUnlock()
+		return pm.latest
+	}
+	resource, _ := pm.getResourceByDomain(domain[0])
+	return pm.lastUsedOf(resource)
+}
+
+// ResourceLastUsed returns the last used proxy of the ResourceConfig, nil is the global pool, see LastUsed.
+func (pm *ProxyManagerImpl) ResourceLastUsed(resource *ResourceConfig) *Proxy {
+	return pm.lastUsedOf(resource)
+}
+
+// lastUsedOf returns the last used proxy of the resource, nil is the global pool.
+func (pm *ProxyManagerImpl) lastUsedOf(resource *ResourceConfig) *Proxy {
 	pm.mu.RLock()
 	defer pm.mu.RUnlock()
-	return pm.lastUsed
+	return pm.lastUsed[resource]
+}
+
+// ProxyTimeout returns the timeout of the requests through the proxy applied by ProxyTransport.
+//
+// The timeout from the metadata of the proxy takes precedence,
+// if it is not set, the timeout of the priority tier of the proxy is used, see WithPriorityTimeouts.
+// Zero means no timeout.
+func (pm *ProxyManagerImpl) ProxyTimeout(proxy *Proxy) time.Duration {
+	meta := proxy.Metadata()
+	if timeout := meta.Timeout(); timeout > 0 {
+		return timeout
+	}
+	return pm.priorityTimeouts[meta.Priority()]
+}
+
+// SetRotationStrategy replaces the global rotation strategy at runtime.
+//
+// GetNextProxy calls running concurrently use either the old or the new strategy.
+func (pm *ProxyManagerImpl) SetRotationStrategy(strategy RotationStrategy) {
+	pm.sMu.Lock()
+	defer pm.sMu.Unlock()
+	pm.rotationStrategy = strategy
+}
+
+// SetSelectStrategy replaces the global select strategy at runtime
+// with the one created by the factory with the ProxyManagerImpl as provider.
+//
+// GetNextProxy calls running concurrently use either the old or the new strategy.
+func (pm *ProxyManagerImpl) SetSelectStrategy(factory SelectStrategyFactory) {
+	strategy := factory(pm)
+
+	pm.sMu.Lock()
+	defer pm.sMu.Unlock()
+	pm.selectStrategy = strategy
+}
+
+// trackRequest counts the in-flight request through the proxy for the fair-share accounting.
+//
+// The request of a resource that falls back to the global pool is not counted,
+// as its proxy was not selected from the proxies of the resource.
+func (pm *ProxyManagerImpl) trackRequest(info *RequestInfo, proxy *Proxy) func() {
+	if pm.fairShare == nil {
+		return func() {}
+	}
+	resource, _ := pm.getResource(info)
+	if pm.fallsBack(resource) {
+		return func() {}
+	}
+	pm.fairShare.acquire(resource, proxy)
+	return func() {
+		pm.fairShare.release(resource, proxy)
+	}
+}
+
+// isEmpty returns true if the ProxyManagerImpl has neither proxies nor resources.
+func (pm *ProxyManagerImpl) isEmpty() bool {
+	pm.pMu.RLock()
+	defer pm.pMu.RUnlock()
+	pm.rMu.RLock()
+	defer pm.rMu.RUnlock()
+	return len(pm.proxies) == 0 && len(pm.resources) == 0
+}
+
+// strategies returns the global rotation and select strategies.
+func (pm *ProxyManagerImpl) strategies() (RotationStrategy, SelectStrategy) {
+	pm.sMu.RLock()
+	defer pm.sMu.RUnlock()
+	return pm.rotationStrategy, pm.selectStrategy
 }
 
 // GetProxies returns the copied list of proxies.
@@ -147,44 +431,539 @@ func (pm *ProxyManagerImpl) GetProxies() []*Proxy {
 	return proxies
 }
 
+// StatsByCountry returns the statistics of the proxies summed by Metadata().Country().
+//
+// The full fleet is used, that is, the global proxies and the proxies of all resources,
+// each proxy is counted once. Proxies with an empty country are grouped under the "" key.
+func (pm *ProxyManagerImpl) StatsByCountry() map[string]StatsSnapshot {
+	stats := make(map[string]StatsSnapshot)
+	for _, p := range pm.fleet() {
+		country := p.Metadata().Country()
+		stats[country] = stats[country].Add(p.Stats().Snapshot())
+	}
+	return stats
+}
+
+// RecycleWhere recycles the proxies of the full fleet for which the predicate returns true
+// and returns the number of recycled proxies.
+//
+// If the last used proxy is recycled, it is cleared from the last used, which releases its active reference.
+// See Proxy.Recycle.
+func (pm *ProxyManagerImpl) RecycleWhere(pred func(*Proxy) bool) int {
+	recycled := make([]*Proxy, 0)
+	for _, p := range pm.fleet() {
+		if pred(p) {
+			recycled = append(recycled, p)
+		}
+	}
+
+	pm.clearLastUsed(recycled...)
+	for _, p := range recycled {
+		p.Recycle()
+	}
+	return len(recycled)
+}
+
+// rotate clears the failed proxy from the last used, so the next selection selects a new proxy.
+func (pm *ProxyManagerImpl) rotate(proxy *Proxy) {
+	pm.clearLastUsed(proxy)
+}
+
+// Resources returns the copied list of resources.
+func (pm *ProxyManagerImpl) Resources() []*ResourceConfig {
+	pm.rMu.RLock()
+	defer pm.rMu.RUnlock()
+
+	resources := make([]*ResourceConfig, len(pm.resources))
+	copy(resources, pm.resources)
+
+	return resources
+}
+
+// GetResource returns the ResourceConfig that matches the domain.
+//
+// Only the domains of the resources are compared, the matchers are not checked, see WithResourceMatcher,
+// so of the resources that share the domain and are split by the matchers the most specific one is returned.
+// Use GetResourceForRequest to resolve the resource like GetNextProxy does.
+//
+// It returns ErrResourceNotFound if no resource matches the domain.
+func (pm *ProxyManagerImpl) GetResource(domain string) (*ResourceConfig, error) {
+	return pm.getResourceByDomain(domain)
+}
+
+// GetResourceForRequest returns the ResourceConfig that matches the request like GetNextProxy does,
+// that is, by the domain and the matchers of the resources, see WithResourceMatcher.
+//
+// It returns ErrResourceNotFound if no resource matches the request.
+func (pm *ProxyManagerImpl) GetResourceForRequest(info *RequestInfo) (*ResourceConfig, error) {
+	return pm.getResource(info)
+}
+
+// RemoveResource removes the ResourceConfig that matches the domain like GetResource does,
+// after that the domain uses the global pool.
+//
+// Its last used proxy is cleared. It returns ErrResourceNotFound if no resource matches the domain.
+// Use RemoveResourceConfig to remove a resource split by the matchers.
+func (pm *ProxyManagerImpl) RemoveResource(domain string) error {
+	return pm.removeResource(func(resources []*ResourceConfig) (int, *ResourceConfig) {
+		return resolveResource(resources, func(rc *ResourceConfig) resourceMatch {
+			return rc.compareDomain(domain)
+		})
+	})
+}
+
+// RemoveResourceConfig removes the ResourceConfig from the ProxyManagerImpl, see RemoveResource.
+//
+// It returns ErrResourceNotFound if the resource is not added to the ProxyManagerImpl.
+func (pm *ProxyManagerImpl) RemoveResourceConfig(resource *ResourceConfig) error {
+	return pm.removeResource(func(resources []*ResourceConfig) (int, *ResourceConfig) {
+		return indexResource(resources, resource)
+	})
+}
+
+// removeResource removes the ResourceConfig found by find and clears its last used proxy and its watchers.
+func (pm *ProxyManagerImpl) removeResource(find func([]*ResourceConfig) (int, *ResourceConfig)) error {
+	pm.rMu.Lock()
+	i, removed := find(pm.resources)
+	if removed != nil {
+		pm.resources = append(pm.resources[:i:i], pm.resources[i+1:]...)
+	}
+	pm.rMu.Unlock()
+	if removed == nil {
+		return ErrResourceNotFound
+	}
+
+	removed.setOnAdd(nil)
+	removed.setOnRemove(nil)
+	pm.mu.Lock()
+	if lastUsed := pm.lastUsed[removed]; lastUsed != nil {
+		lastUsed.deactivate()
+		delete(pm.lastUsed, removed)
+	}
+	pm.mu.Unlock()
+
+	pm.unwatchProxies(removed.GetProxies())
+	pm.checkPool()
+	return nil
+}
+
+// indexResource returns the index of the resource in the resources and the resource,
+// or -1 and nil if the resources do not have it.
+func indexResource(resources []*ResourceConfig, resource *ResourceConfig) (int, *ResourceConfig) {
+	for i, rc := range resources {
+		if rc == resource {
+			return i, rc
+		}
+	}
+	return -1, nil
+}
+
+// hasResource returns true if the resource is added to the ProxyManagerImpl.
+func (pm *ProxyManagerImpl) hasResource(resource *ResourceConfig) bool {
+	pm.rMu.RLock()
+	defer pm.rMu.RUnlock()
+	_, rc := indexResource(pm.resources, resource)
+	return rc != nil
+}
+
 // AddResources adds resources to the ProxyManagerImpl.
 func (pm *ProxyManagerImpl) AddResources(resources ...*ResourceConfig) {
+	pm.watchResources(resources)
+
 	pm.rMu.Lock()
-	defer pm.rMu.Unlock()
 	pm.resources = append(pm.resources, resources...)
+	pm.rMu.Unlock()
+
+	pm.checkPool()
 }
 
 // AddProxies adds proxies to the ProxyManagerImpl.
-func (pm *ProxyManagerImpl) AddProxies(proxies ...*Proxy) {
+//
+// If the maximum pool size is set by WithMaxPool and exceeded,
+// the proxies are evicted by the EvictionPolicy.
+// An evicted proxy is cleared from the last used if it is currently selected.
+//
+// If the strict validation is enabled and any proxy is invalid, no proxies are added
+// and the joined *ProxyURLError errors are returned, see WithStrictValidation.
+// The proxies whose normalized urls are already in the pool are handled by the DuplicatePolicy,
+// see WithDuplicatePolicy.
+func (pm *ProxyManagerImpl) AddProxies(proxies ...*Proxy) error {
+	if err := pm.ValidateProxies(proxies...); err != nil {
+		return err
+	}
+
 	pm.pMu.Lock()
-	defer pm.pMu.Unlock()
-	pm.proxies = append(pm.proxies, proxies...)
+	proxies, err := pm.duplicatePolicy.dedupe(pm.proxies, proxies)
+	if err != nil {
+		pm.pMu.Unlock()
+		return err
+	}
+	pm.applyDefaultMetadata(proxies)
+
+	var evicted []*Proxy
+	pm.proxies, evicted = pm.evictionPolicy.evict(pm.proxies, proxies, pm.maxPool)
+	pm.watchProxies(survivingProxies(proxies, evicted))
+	pm.pMu.Unlock()
+
+	pm.logPoolChange(len(proxies), len(evicted))
+	if len(evicted) != 0 {
+		pm.onProxiesRemoved(evicted)
+	} else {
+		pm.checkPool()
+	}
+	return nil
 }
 
-// AddResourceProxies adds proxies to the ResourceConfig by domain.
-func (pm *ProxyManagerImpl) AddResourceProxies(domain string, proxies ...*Proxy) error {
+// survivingProxies returns the added proxies that were not evicted.
+func survivingProxies(added, evicted []*Proxy) []*Proxy {
+	if len(evicted) == 0 {
+		return added
+	}
+	gone := make(map[*Proxy]struct{}, len(evicted))
+	for _, p := range evicted {
+		gone[p] = struct{}{}
+	}
+	surviving := make([]*Proxy, 0, len(added))
+	for _, p := range added {
+		if _, ok := gone[p]; !ok {
+			surviving = append(surviving, p)
+		}
+	}
+	return surviving
+}
+
+// RemoveProxies removes the proxies from the global pool and returns the number of removed proxies.
+//
+// A removed proxy is cleared from the last used if it is currently selected.
+func (pm *ProxyManagerImpl) RemoveProxies(proxies ...*Proxy) int {
+	return pm.removeGlobal(matchProxies(proxies))
+}
+
+// RemoveProxiesByURL removes the proxies with the urls from the global pool
+// and returns the number of removed proxies.
+//
+// A removed proxy is cleared from the last used if it is currently selected.
+func (pm *ProxyManagerImpl) RemoveProxiesByURL(urls ...string) int {
+	return pm.removeGlobal(matchProxyURLs(urls))
+}
+
+// RemoveWhere removes the proxies of the full fleet, that is, of the global pool and of all resources,
+// for which the predicate returns true and returns the number of removed proxies.
+//
+// A proxy that is in several pools is counted once.
+// A removed proxy is cleared from the last used if it is currently selected.
+func (pm *ProxyManagerImpl) RemoveWhere(pred func(*Proxy) bool) int {
+	removed := make(map[*Proxy]struct{})
+	match := func(p *Proxy) bool {
+		if pred(p) {
+			removed[p] = struct{}{}
+			return true
+		}
+		return false
+	}
+
+	pm.removeGlobal(match)
+	for _, resource := range pm.Resources() {
+		resource.RemoveWhere(match)
+	}
+	return len(removed)
+}
+
+// SyncProxies replaces the global pool with the proxies in one step and returns the number of added
+// and removed proxies.
+//
+// The proxies are matched by url: a proxy whose url is already in the pool is not replaced,
+// so it keeps its statistics, metadata and state, the proxies with the new urls are added,
+// and the proxies whose urls are missing are removed. The duplicate urls are added once.
+//
+// If the maximum pool size is set by WithMaxPool and exceeded, the proxies are evicted by the EvictionPolicy.
+// A removed or evicted proxy is cleared from the last used if it is currently selected.
+//
+// The proxies are not validated, call ValidateProxies before, like ProxyRefresher does.
+func (pm *ProxyManagerImpl) SyncProxies(proxies ...*Proxy) (int, int) {
+	pm.pMu.Lock()
+	existing := make(map[string]*Proxy, len(pm.proxies))
+	for _, p := range pm.proxies {
+		existing[p.String()] = p
+	}
+
+	seen := make(map[string]struct{}, len(proxies))
+	kept := make([]*Proxy, 0, len(pm.proxies))
+	var added []*Proxy
+	for _, p := range proxies {
+		key := p.String()
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		if current, ok := existing[key]; ok {
+			kept = append(kept, current)
+			continue
+		}
+		added = append(added, p)
+	}
+
+	var removed []*Proxy
+	for _, p := range pm.proxies {
+		if _, ok := seen[p.String()]; !ok {
+			removed = append(removed, p)
+		}
+	}
+
+	pm.applyDefaultMetadata(added)
+	var evicted []*Proxy
+	pm.proxies, evicted = pm.evictionPolicy.evict(kept, added, pm.maxPool)
+	pm.watchProxies(survivingProxies(added, evicted))
+	pm.pMu.Unlock()
+
+	removedCount := len(removed)
+	pm.logPoolChange(len(added), removedCount+len(evicted))
+	if removed = append(removed, evicted...); len(removed) != 0 {
+		pm.onProxiesRemoved(removed)
+	} else {
+		pm.checkPool()
+	}
+	return len(added), removedCount
+}
+
+// RemoveResourceProxies removes the proxies from the ResourceConfig by domain
+// and returns the number of removed proxies.
+func (pm *ProxyManagerImpl) RemoveResourceProxies(domain string, proxies ...*Proxy) (int, error) {
 	resource, err := pm.getResourceByDomain(domain)
+	if err != nil {
+		return 0, err
+	}
+	return resource.RemoveProxies(proxies...), nil
+}
+
+// removeGlobal removes the proxies for which the predicate returns true from the global pool.
+func (pm *ProxyManagerImpl) removeGlobal(pred func(*Proxy) bool) int {
+	pm.pMu.Lock()
+	var removed []*Proxy
+	pm.proxies, removed = partitionProxies(pm.proxies, pred)
+	pm.pMu.Unlock()
 
+	pm.logPoolChange(0, len(removed))
+	if len(removed) != 0 {
+		pm.onProxiesRemoved(removed)
+	}
+	return len(removed)
+}
+
+// AddResourceProxies adds proxies to the ResourceConfig by domain, see GetResource.
+//
+// If the strict validation is enabled and any proxy is invalid, no proxies are added, see AddProxies.
+// Use AddProxiesToResource to add proxies to a resource split by the matchers.
+func (pm *ProxyManagerImpl) AddResourceProxies(domain string, proxies ...*Proxy) error {
+	resource, err := pm.getResourceByDomain(domain)
 	if err != nil {
 		return err
 	}
+	return pm.addResourceProxies(resource, proxies)
+}
+
+// AddProxiesToResource adds proxies to the ResourceConfig of the ProxyManagerImpl, see AddResourceProxies.
+//
+// It returns ErrResourceNotFound if the resource is not added to the ProxyManagerImpl.
+func (pm *ProxyManagerImpl) AddProxiesToResource(resource *ResourceConfig, proxies ...*Proxy) error {
+	if !pm.hasResource(resource) {
+		return ErrResourceNotFound
+	}
+	return pm.addResourceProxies(resource, proxies)
+}
+
+// addResourceProxies validates the proxies and adds them to the resource.
+func (pm *ProxyManagerImpl) addResourceProxies(resource *ResourceConfig, proxies []*Proxy) error {
+	if err := pm.ValidateProxies(proxies...); err != nil {
+		return err
+	}
 
+	pm.applyDefaultMetadata(proxies)
 	resource.AddProxies(proxies...)
 	return nil
 }
 
+// applyDefaultMetadata sets the default metadata to the proxies created without metadata.
+func (pm *ProxyManagerImpl) applyDefaultMetadata(proxies []*Proxy) {
+	if pm.defaultMeta == nil {
+		return
+	}
+	for _, p := range proxies {
+		p.applyDefaultMetadata(pm.defaultMeta)
+	}
+}
+
+// Fleet returns the full fleet, that is, the global proxies and the proxies of all resources without duplicates.
+func (pm *ProxyManagerImpl) Fleet() []*Proxy {
+	return pm.fleet()
+}
+
+// Clock returns the clock of the ProxyManagerImpl, see WithClock.
+func (pm *ProxyManagerImpl) Clock() Clock {
+	return pm.clock
+}
+
+// GetProxyByID returns the proxy of the full fleet by the id, see Proxy.ID.
+//
+// If several proxies have the same id, the first one of the global pool or of the resources is returned.
+// It returns ErrProxyNotFound if no proxy has the id.
+func (pm *ProxyManagerImpl) GetProxyByID(id string) (*Proxy, error) {
+	return pm.findProxy(func(p *Proxy) bool {
+		return p.ID() == id
+	})
+}
+
+// GetProxyByURL returns the proxy of the full fleet by the url like RemoveProxiesByURL matches it.
+//
+// It returns ErrProxyNotFound if no proxy has the url.
+func (pm *ProxyManagerImpl) GetProxyByURL(rawURL string) (*Proxy, error) {
+	return pm.findProxy(matchProxyURLs([]string{rawURL}))
+}
+
+// findProxy returns the first proxy of the full fleet for which the predicate returns true.
+func (pm *ProxyManagerImpl) findProxy(pred func(*Proxy) bool) (*Proxy, error) {
+	for _, p := range pm.fleet() {
+		if pred(p) {
+			return p, nil
+		}
+	}
+	return nil, ErrProxyNotFound
+}
+
+// fleet returns the global proxies and the proxies of all resources without duplicates.
+func (pm *ProxyManagerImpl) fleet() []*Proxy {
+	proxies := pm.GetProxies()
+
+	pm.rMu.RLock()
+	for _, resource := range pm.resources {
+		proxies = append(proxies, resource.GetProxies()...)
+	}
+	pm.rMu.RUnlock()
+
+	seen := make(map[*Proxy]struct{}, len(proxies))
+	fleet := proxies[:0]
+	for _, p := range proxies {
+		if _, ok := seen[p]; ok {
+			continue
+		}
+		seen[p] = struct{}{}
+		fleet = append(fleet, p)
+	}
+	return fleet
+}
+
+// setLastUsed sets the last used proxy of the resource, nil is the global pool,
+// and moves the active reference from the previous one.
+func (pm *ProxyManagerImpl) setLastUsed(resource *ResourceConfig, proxy *Proxy) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	pm.latest = proxy
+	previous := pm.lastUsed[resource]
+	if previous == proxy {
+		return
+	}
+	if previous != nil {
+		previous.deactivate()
+	}
+	proxy.activate()
+	pm.lastUsed[resource] = proxy
+}
+
+// clearLastUsed clears the proxies from the last used of the resources and of the global pool.
+func (pm *ProxyManagerImpl) clearLastUsed(proxies ...*Proxy) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	match := matchProxies(proxies)
+	for resource, p := range pm.lastUsed {
+		if match(p) {
+			p.deactivate()
+			delete(pm.lastUsed, resource)
+		}
+	}
+	if pm.latest != nil && match(pm.latest) {
+		pm.latest = nil
+	}
+}
+
+// partitionProxies returns the proxies for which the predicate returns false and the removed ones.
+//
+// The kept proxies are a new slice, so the previous slice returned by GetProxies is not changed.
+func partitionProxies(proxies []*Proxy, pred func(*Proxy) bool) ([]*Proxy, []*Proxy) {
+	kept := make([]*Proxy, 0, len(proxies))
+	var removed []*Proxy
+	for _, p := range proxies {
+		if pred(p) {
+			removed = append(removed, p)
+			continue
+		}
+		kept = append(kept, p)
+	}
+	return kept, removed
+}
+
+// matchProxies returns the predicate that matches the proxies by pointer.
+func matchProxies(proxies []*Proxy) func(*Proxy) bool {
+	set := make(map[*Proxy]struct{}, len(proxies))
+	for _, p := range proxies {
+		set[p] = struct{}{}
+	}
+	return func(p *Proxy) bool {
+		_, ok := set[p]
+		return ok
+	}
+}
+
+// matchProxyURLs returns the predicate that matches the proxies by url.
+//
+// The urls are compared after parsing, so the equivalent forms of the url match.
+func matchProxyURLs(urls []string) func(*Proxy) bool {
+	set := make(map[string]struct{}, len(urls))
+	for _, raw := range urls {
+		if u, err := url.Parse(raw); err == nil {
+			raw = u.String()
+		}
+		set[raw] = struct{}{}
+	}
+	return func(p *Proxy) bool {
+		_, ok := set[p.String()]
+		return ok
+	}
+}
+
+// getResource returns the ResourceConfig that matches the request.
+//
+// If several resources match, the most specific one wins, see resolveResource.
+func (pm *ProxyManagerImpl) getResource(info *RequestInfo) (*ResourceConfig, error) {
+	pm.rMu.RLock()
+	defer pm.rMu.RUnlock()
+
+	_, resource := resolveResource(pm.resources, func(rc *ResourceConfig) resourceMatch {
+		return rc.match(info)
+	})
+	if resource == nil {
+		return nil, ErrResourceNotFound
+	}
+	return resource, nil
+}
+
+// getResourceByDomain returns the ResourceConfig that matches the domain, the matchers are not checked.
+//
+// If several resources match, the most specific one wins, see resolveResource.
 func (pm *ProxyManagerImpl) getResourceByDomain(domain string) (*ResourceConfig, error) {
 	pm.rMu.RLock()
 	defer pm.rMu.RUnlock()
 
-	for _, resource := range pm.resources {
-		if resource.CompareDomain(domain) {
-			return resource, nil
-		}
+	_, resource := resolveResource(pm.resources, func(rc *ResourceConfig) resourceMatch {
+		return rc.compareDomain(domain)
+	})
+	if resource == nil {
+		return nil, ErrResourceNotFound
 	}
-	return nil, ErrResourceNotFound
+	return resource, nil
 }
 
-func (pm *ProxyManagerImpl) proxyNotAvailable(err error) error {
-	return fmt.Errorf("%w: %w", ErrProxyNotAvailable, err)
+func (pm *ProxyManagerImpl) proxyNotAvailable(domain string, resourceMatched bool, err error) error {
+	return &SelectionError{Domain: domain, ResourceMatched: resourceMatched, Cause: err}
 }
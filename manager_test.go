@@ -0,0 +1,158 @@
+package proxym_test
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/nezbut/proxym"
+	"github.com/nezbut/proxym/rotations"
+	"github.com/nezbut/proxym/selects"
+)
+
+func TestResourcesSplitByMatchers(t *testing.T) {
+	httpProxies, httpsProxies := newProxies(2), []*proxym.Proxy{proxym.NewProxyStr("http://secure:8080", nil)}
+	plain := newRoundRobinResource("a.com", httpProxies, proxym.WithResourceMatcher(proxym.SchemeMatcher("http")))
+	secure := newRoundRobinResource("a.com", httpsProxies, proxym.WithResourceMatcher(proxym.SchemeMatcher("https")))
+	pm := newManager(newProxies(1), proxym.WithResources(plain, secure))
+
+	req, err := http.NewRequest(http.MethodGet, "https://a.com/", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rc, err := pm.GetResourceForRequest(proxym.NewRequestInfo(req))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rc != secure {
+		t.Fatal("got the resource of another scheme, want the resource of https")
+	}
+
+	p, err := pm.GetNextProxyRequest(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := pm.ResourceLastUsed(secure); got != p {
+		t.Errorf("got the last used proxy %v, want %s", got, p.URL())
+	}
+
+	added := proxym.NewProxyStr("http://secure-added:8080", nil)
+	if err := pm.AddProxiesToResource(secure, added); err != nil {
+		t.Fatal(err)
+	}
+	if got := len(secure.GetProxies()); got != 2 {
+		t.Errorf("got %d proxies of the resource, want 2", got)
+	}
+
+	if err := pm.RemoveResourceConfig(secure); err != nil {
+		t.Fatal(err)
+	}
+	if got := pm.ResourceLastUsed(secure); got != nil {
+		t.Errorf("got the last used proxy %s of the removed resource, want nil", got.URL())
+	}
+	if got := pm.Resources(); len(got) != 1 || got[0] != plain {
+		t.Errorf("got %d resources, want only the resource of http", len(got))
+	}
+	if err := pm.RemoveResourceConfig(secure); !errors.Is(err, proxym.ErrResourceNotFound) {
+		t.Errorf("got error %v removing the resource twice, want ErrResourceNotFound", err)
+	}
+	if err := pm.AddProxiesToResource(secure, added); !errors.Is(err, proxym.ErrResourceNotFound) {
+		t.Errorf("got error %v adding to the removed resource, want ErrResourceNotFound", err)
+	}
+}
+
+func TestStatsByCountry(t *testing.T) {
+	used := func(url, country string, results ...bool) *proxym.Proxy {
+		p := proxym.NewProxyStr(url, nil)
+		p.Metadata().SetCountry(country)
+		use(p, results...)
+		return p
+	}
+	us1, us2 := used("http://us1:8080", "US", true, true, true, false), used("http://us2:8080", "US", true, true)
+	de := used("http://de:8080", "DE", true, false)
+	unknown := used("http://unknown:8080", "", true, true)
+	// us2 is in both the global pool and the resource, it is counted once.
+	pm := newManager([]*proxym.Proxy{us1, us2, unknown}, proxym.WithResources(newResource("a.com", us2, de)))
+
+	want := map[string]proxym.StatsSnapshot{
+		"US": {TotalRequests: 6, SuccessCount: 5, ErrorCount: 1},
+		"DE": {TotalRequests: 2, SuccessCount: 1, ErrorCount: 1},
+		"":   {TotalRequests: 2, SuccessCount: 2},
+	}
+	got := pm.StatsByCountry()
+	if len(got) != len(want) {
+		t.Fatalf("got %d countries, want %d", len(got), len(want))
+	}
+	for country, w := range want {
+		g := got[country]
+		if g.TotalRequests != w.TotalRequests || g.SuccessCount != w.SuccessCount || g.ErrorCount != w.ErrorCount {
+			t.Errorf("country %q: got %d requests, %d successes and %d errors, want %d, %d and %d", country,
+				g.TotalRequests, g.SuccessCount, g.ErrorCount, w.TotalRequests, w.SuccessCount, w.ErrorCount)
+		}
+	}
+}
+
+func TestSetStrategiesConcurrently(t *testing.T) {
+	proxies := newProxies(3)
+	pm := newManager(proxies)
+	pool := make(map[*proxym.Proxy]bool)
+	for _, p := range proxies {
+		pool[p] = true
+	}
+
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				p, err := pm.GetNextProxy("example.com")
+				if err != nil && !errors.Is(err, proxym.ErrProxyNotAvailable) {
+					t.Error(err)
+					return
+				}
+				if err == nil && !pool[p] {
+					t.Errorf("got %s, want a proxy of the pool", p.URL())
+					return
+				}
+			}
+		}()
+	}
+	for i := range 200 {
+		if i%2 == 0 {
+			pm.SetRotationStrategy(rotations.RoundRobinRotation{})
+			pm.SetSelectStrategy(selects.NewRoundRobinSelect)
+		} else {
+			pm.SetRotationStrategy(rotations.DefaultRotationStrategy())
+			pm.SetSelectStrategy(selects.NewRandomSelect)
+		}
+	}
+	close(done)
+	wg.Wait()
+
+	pm.SetRotationStrategy(rotations.RoundRobinRotation{})
+	pm.SetSelectStrategy(func(proxym.SelectStrategyProxyProvider) proxym.SelectStrategy {
+		return fixedSelect{proxy: proxies[2]}
+	})
+	for range 3 {
+		if p, err := pm.GetNextProxy("example.com"); err != nil || p != proxies[2] {
+			t.Fatalf("got %v, %v after the swap, want %s of the new strategy", p, err, proxies[2].URL())
+		}
+	}
+}
+
+// fixedSelect is a proxym.SelectStrategy that always returns the proxy.
+type fixedSelect struct {
+	proxy *proxym.Proxy
+}
+
+func (s fixedSelect) Select() (*proxym.Proxy, error) {
+	return s.proxy, nil
+}
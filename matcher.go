@@ -0,0 +1,120 @@
+package proxym
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// RequestInfo is the information about a request by which the ResourceConfig is matched, see ResourceMatcher.
+type RequestInfo struct {
+	// Host is the host of the request without the port.
+	Host string
+	// Port is the port of the request, the default port of the scheme if it is not set in the url.
+	Port string
+	// Scheme is the lowercase scheme of the request.
+	Scheme string
+	// Path is the path of the request.
+	Path string
+	// Method is the uppercase method of the request.
+	Method string
+}
+
+// NewRequestInfo returns the RequestInfo of the request.
+func NewRequestInfo(req *http.Request) *RequestInfo {
+	info := &RequestInfo{
+		Host:   req.URL.Hostname(),
+		Port:   req.URL.Port(),
+		Scheme: strings.ToLower(req.URL.Scheme),
+		Path:   req.URL.Path,
+		Method: strings.ToUpper(req.Method),
+	}
+	if info.Method == "" {
+		info.Method = http.MethodGet
+	}
+	if info.Port == "" {
+		info.Port = defaultPorts[info.Scheme]
+	}
+	return info
+}
+
+// defaultPorts are the default ports of the schemes.
+var defaultPorts = map[string]string{
+	"http":  "80",
+	"https": "443",
+	"ws":    "80",
+	"wss":   "443",
+}
+
+// domainInfo returns the RequestInfo with only the domain, it is used by the selections by domain.
+func domainInfo(domain string) *RequestInfo {
+	return &RequestInfo{Host: domain}
+}
+
+// ResourceMatcher is an interface for matching the requests to the ResourceConfig, see WithResourceMatcher.
+type ResourceMatcher interface {
+	// Match returns true if the request matches.
+	Match(info *RequestInfo) bool
+}
+
+// ResourceMatcherFunc is an adapter to use a function as ResourceMatcher.
+type ResourceMatcherFunc func(info *RequestInfo) bool
+
+// Match calls f(info).
+func (f ResourceMatcherFunc) Match(info *RequestInfo) bool {
+	return f(info)
+}
+
+// PathPrefixMatcher returns the ResourceMatcher that matches the requests whose path has any of the prefixes,
+// for example "/api/".
+func PathPrefixMatcher(prefixes ...string) ResourceMatcher {
+	return ResourceMatcherFunc(func(info *RequestInfo) bool {
+		for _, prefix := range prefixes {
+			if strings.HasPrefix(info.Path, prefix) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// MethodMatcher returns the ResourceMatcher that matches the requests with any of the methods, for example "POST".
+//
+// The methods are compared case-insensitively.
+func MethodMatcher(methods ...string) ResourceMatcher {
+	return ResourceMatcherFunc(func(info *RequestInfo) bool {
+		for _, method := range methods {
+			if strings.EqualFold(method, info.Method) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// SchemeMatcher returns the ResourceMatcher that matches the requests with any of the schemes, for example "https".
+//
+// The schemes are compared case-insensitively.
+func SchemeMatcher(schemes ...string) ResourceMatcher {
+	return ResourceMatcherFunc(func(info *RequestInfo) bool {
+		for _, scheme := range schemes {
+			if strings.EqualFold(scheme, info.Scheme) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// PortMatcher returns the ResourceMatcher that matches the requests to any of the ports,
+// the default port of the scheme is used if the url has no port, for example 443 for https.
+func PortMatcher(ports ...int) ResourceMatcher {
+	return ResourceMatcherFunc(func(info *RequestInfo) bool {
+		for _, port := range ports {
+			if strconv.Itoa(port) == info.Port {
+				return true
+			}
+		}
+		return false
+	})
+}
@@ -0,0 +1,3 @@
+// Package expvar publishes the metrics of a proxym.ProxyManagerImpl through the expvar package,
+// so the existing /debug/vars tooling picks them up without extra dependencies.
+package expvar
@@ -0,0 +1,110 @@
+package expvar
+
+import (
+	"expvar"
+
+	"github.com/nezbut/proxym"
+)
+
+// DefaultName is the default name of the published variable.
+const DefaultName = "proxym"
+
+// Pool is the metrics of the global pool or of a resource.
+//
+// The domain and the match priority are set for a resource only, the domain is empty for a resource
+// without the domain, see proxym.WithDomainPattern.
+type Pool struct {
+	Domain        string           `json:"domain,omitempty"`
+	MatchPriority int              `json:"match_priority,omitempty"`
+	Size          int              `json:"size"`
+	Selections    uint64           `json:"selections"`
+	Rotations     uint64           `json:"rotations"`
+	Failures      uint64           `json:"failures"`
+	Proxies       map[string]Proxy `json:"proxies"`
+}
+
+// Proxy is the metrics of a proxy, the proxies are keyed by the URL with the password redacted.
+type Proxy struct {
+	Requests      uint   `json:"requests"`
+	Successes     uint   `json:"successes"`
+	Errors        uint   `json:"errors"`
+	BytesSent     uint64 `json:"bytes_sent"`
+	BytesReceived uint64 `json:"bytes_received"`
+	Active        int    `json:"active"`
+	Disabled      bool   `json:"disabled"`
+}
+
+// Metrics is the value of the published variable.
+//
+// The resources are in the order of proxym.ProxyManagerImpl.Resources, so the resources that share the domain,
+// for example the resources split by the matchers, and the resources without the domain are kept apart.
+type Metrics struct {
+	Global    Pool   `json:"global"`
+	Resources []Pool `json:"resources"`
+}
+
+// NewVar returns the expvar.Var with the metrics of the manager, see Metrics.
+//
+// The metrics are read from the manager each time the variable is read.
+func NewVar(pm *proxym.ProxyManagerImpl) expvar.Var {
+	return expvar.Func(func() any {
+		return Collect(pm)
+	})
+}
+
+// Publish publishes the metrics of the manager under the name, DefaultName if the name is empty.
+//
+// Like expvar.Publish, it panics if the name is already published.
+func Publish(pm *proxym.ProxyManagerImpl, name string) {
+	if name == "" {
+		name = DefaultName
+	}
+	expvar.Publish(name, NewVar(pm))
+}
+
+// Collect returns the current metrics of the global pool and of each resource of the manager.
+func Collect(pm *proxym.ProxyManagerImpl) Metrics {
+	resources := pm.Resources()
+	metrics := Metrics{
+		Global:    collectPool(pm.GetProxies(), pm.SelectionCounters()),
+		Resources: make([]Pool, 0, len(resources)),
+	}
+	for _, resource := range resources {
+		pool := collectPool(resource.GetProxies(), resource.SelectionCounters())
+		pool.Domain, pool.MatchPriority = resource.Domain(), resource.MatchPriority()
+		metrics.Resources = append(metrics.Resources, pool)
+	}
+	return metrics
+}
+
+// collectPool returns the metrics of the pool.
+func collectPool(proxies []*proxym.Proxy, counters proxym.SelectionCounters) Pool {
+	pool := Pool{
+		Size:       len(proxies),
+		Selections: counters.Selections,
+		Rotations:  counters.Rotations,
+		Failures:   counters.Failures,
+		Proxies:    make(map[string]Proxy, len(proxies)),
+	}
+	for _, proxy := range proxies {
+		stats := proxy.Stats().Snapshot()
+		pool.Proxies[proxyKey(proxy)] = Proxy{
+			Requests:      stats.TotalRequests,
+			Successes:     stats.SuccessCount,
+			Errors:        stats.ErrorCount,
+			BytesSent:     stats.BytesSent,
+			BytesReceived: stats.BytesReceived,
+			Active:        proxy.ActiveCount(),
+			Disabled:      proxy.IsDisabled(),
+		}
+	}
+	return pool
+}
+
+// proxyKey returns the key of the proxy, the URL of the proxy with the password redacted.
+func proxyKey(proxy *proxym.Proxy) string {
+	if u := proxy.URL(); u != nil {
+		return u.Redacted()
+	}
+	return proxy.String()
+}
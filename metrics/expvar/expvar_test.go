@@ -0,0 +1,49 @@
+package expvar_test
+
+import (
+	"testing"
+
+	"github.com/nezbut/proxym"
+	proxymexpvar "github.com/nezbut/proxym/metrics/expvar"
+	"github.com/nezbut/proxym/rotations"
+	"github.com/nezbut/proxym/selects"
+)
+
+func TestCollectResourcesWithoutUniqueDomain(t *testing.T) {
+	proxy := proxym.NewProxyStr("http://proxy:8080", nil)
+	resource := func(opts ...proxym.ResourceConfigOption) *proxym.ResourceConfig {
+		return proxym.NewResourceConfig(true, append([]proxym.ResourceConfigOption{
+			proxym.WithResourceProxies(proxy),
+			proxym.WithResourceRotationStrategy(rotations.DefaultRotationStrategy()),
+			proxym.WithResourceSelectStrategy(selects.DefaultSelectStrategy()),
+		}, opts...)...)
+	}
+	pm := proxym.NewProxyManager(
+		proxym.WithProxies(proxy),
+		proxym.WithRotationStrategy(rotations.DefaultRotationStrategy()),
+		proxym.WithSelectStrategy(selects.DefaultSelectStrategy()),
+		proxym.WithResources(
+			resource(proxym.WithDomainPattern(`^api\d+\.example\.com$`)),
+			resource(proxym.WithDomainPattern(`^cdn\d+\.example\.com$`)),
+			resource(proxym.WithDomain("a.com"), proxym.WithResourceMatcher(proxym.SchemeMatcher("http"))),
+			resource(proxym.WithDomain("a.com"), proxym.WithMatchPriority(1),
+				proxym.WithResourceMatcher(proxym.SchemeMatcher("https"))),
+		),
+	)
+
+	metrics := proxymexpvar.Collect(pm)
+	want := []struct {
+		domain   string
+		priority int
+	}{{"", 0}, {"", 0}, {"a.com", 0}, {"a.com", 1}}
+	if len(metrics.Resources) != len(want) {
+		t.Fatalf("got %d resources, want %d", len(metrics.Resources), len(want))
+	}
+	for i, w := range want {
+		got := metrics.Resources[i]
+		if got.Domain != w.domain || got.MatchPriority != w.priority || got.Size != 1 {
+			t.Errorf("resource %d: got domain %q, priority %d and size %d, want %q, %d and 1",
+				i, got.Domain, got.MatchPriority, got.Size, w.domain, w.priority)
+		}
+	}
+}
@@ -0,0 +1,169 @@
+package prometheus
+
+import (
+	"strconv"
+
+	"github.com/nezbut/proxym"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Label names of the metrics.
+const (
+	labelProxy  = "proxy"
+	labelPool   = "pool"
+	labelDomain = "domain"
+)
+
+// globalPool is the pool label value of the global pool of the manager.
+const globalPool = "global"
+
+// defaultNamespace is the default namespace of the metrics.
+const defaultNamespace = "proxym"
+
+// Collector is a prometheus.Collector that exports the metrics of a proxym.ProxyManagerImpl.
+//
+// The per-proxy metrics are labeled by the proxy URL with the password redacted, by the pool and by the domain,
+// the pool and selection metrics are labeled by the pool and by the domain.
+// The pool label is "global" for the global pool of the manager and the index of the resource
+// in ProxyManagerImpl.Resources for a resource, so the resources that share the domain, for example the resources
+// split by the matchers, and the resources without the domain have their own series.
+// The series of a resource move to the lower index when a resource before it is removed.
+// The domain label is the domain of the resource, empty for the global pool.
+//
+// The metrics are read from the manager on each scrape, so the collector has no state of its own.
+type Collector struct {
+	pm *proxym.ProxyManagerImpl
+
+	requests   *prometheus.Desc
+	successes  *prometheus.Desc
+	errors     *prometheus.Desc
+	sent       *prometheus.Desc
+	received   *prometheus.Desc
+	active     *prometheus.Desc
+	disabled   *prometheus.Desc
+	poolSize   *prometheus.Desc
+	selections *prometheus.Desc
+	rotations  *prometheus.Desc
+	failures   *prometheus.Desc
+}
+
+// Option is option for Collector.
+type Option func(*collectorConfig)
+
+// collectorConfig is the configuration of the Collector.
+type collectorConfig struct {
+	namespace   string
+	constLabels prometheus.Labels
+}
+
+// WithNamespace sets the namespace of the metrics, by default "proxym".
+func WithNamespace(namespace string) Option {
+	return func(c *collectorConfig) {
+		c.namespace = namespace
+	}
+}
+
+// WithConstLabels sets the labels added to all metrics.
+func WithConstLabels(labels prometheus.Labels) Option {
+	return func(c *collectorConfig) {
+		c.constLabels = labels
+	}
+}
+
+// NewCollector returns a new Collector for the manager.
+//
+// Register it with prometheus.MustRegister(collector).
+func NewCollector(pm *proxym.ProxyManagerImpl, opts ...Option) *Collector {
+	cfg := &collectorConfig{namespace: defaultNamespace}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	desc := func(name, help string, labels []string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(cfg.namespace, "", name), help, labels, cfg.constLabels)
+	}
+	proxyLabels, poolLabels := []string{labelProxy, labelPool, labelDomain}, []string{labelPool, labelDomain}
+	return &Collector{
+		pm:         pm,
+		requests:   desc("proxy_requests_total", "Total number of requests through the proxy.", proxyLabels),
+		successes:  desc("proxy_success_total", "Number of successful requests through the proxy.", proxyLabels),
+		errors:     desc("proxy_errors_total", "Number of failed requests through the proxy.", proxyLabels),
+		sent:       desc("proxy_sent_bytes_total", "Bytes of the request bodies sent.", proxyLabels),
+		received:   desc("proxy_received_bytes_total", "Bytes of the response bodies received.", proxyLabels),
+		active:     desc("proxy_active", "Number of pools the proxy is the last used proxy of.", proxyLabels),
+		disabled:   desc("proxy_disabled", "Whether the proxy is disabled.", proxyLabels),
+		poolSize:   desc("pool_size", "Number of proxies in the pool.", poolLabels),
+		selections: desc("selections_total", "Number of proxies selected by the select strategy.", poolLabels),
+		rotations:  desc("rotations_total", "Number of rotations of the last used proxy.", poolLabels),
+		failures:   desc("selection_failures_total", "Number of times no proxy was available.", poolLabels),
+	}
+}
+
+// Describe sends the descriptors of the metrics to the channel.
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.requests
+	ch <- c.successes
+	ch <- c.errors
+	ch <- c.sent
+	ch <- c.received
+	ch <- c.active
+	ch <- c.disabled
+	ch <- c.poolSize
+	ch <- c.selections
+	ch <- c.rotations
+	ch <- c.failures
+}
+
+// Collect sends the metrics of the global pool and of each resource to the channel.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	c.collectPool(ch, globalPool, "", c.pm.GetProxies(), c.pm.SelectionCounters())
+	for i, resource := range c.pm.Resources() {
+		c.collectPool(ch, strconv.Itoa(i), resource.Domain(), resource.GetProxies(), resource.SelectionCounters())
+	}
+}
+
+// collectPool sends the metrics of the pool to the channel.
+func (c *Collector) collectPool(
+	ch chan<- prometheus.Metric, pool, domain string, proxies []*proxym.Proxy, counters proxym.SelectionCounters,
+) {
+	poolMetric := func(desc *prometheus.Desc, valueType prometheus.ValueType, value float64) {
+		ch <- prometheus.MustNewConstMetric(desc, valueType, value, pool, domain)
+	}
+	poolMetric(c.poolSize, prometheus.GaugeValue, float64(len(proxies)))
+	poolMetric(c.selections, prometheus.CounterValue, float64(counters.Selections))
+	poolMetric(c.rotations, prometheus.CounterValue, float64(counters.Rotations))
+	poolMetric(c.failures, prometheus.CounterValue, float64(counters.Failures))
+
+	seen := make(map[string]struct{}, len(proxies))
+	for _, proxy := range proxies {
+		label := proxyLabel(proxy)
+		if _, ok := seen[label]; ok {
+			continue
+		}
+		seen[label] = struct{}{}
+
+		stats := proxy.Stats().Snapshot()
+		disabled := 0.0
+		if proxy.IsDisabled() {
+			disabled = 1
+		}
+		metric := func(desc *prometheus.Desc, valueType prometheus.ValueType, value float64) {
+			ch <- prometheus.MustNewConstMetric(desc, valueType, value, label, pool, domain)
+		}
+		metric(c.requests, prometheus.CounterValue, float64(stats.TotalRequests))
+		metric(c.successes, prometheus.CounterValue, float64(stats.SuccessCount))
+		metric(c.errors, prometheus.CounterValue, float64(stats.ErrorCount))
+		metric(c.sent, prometheus.CounterValue, float64(stats.BytesSent))
+		metric(c.received, prometheus.CounterValue, float64(stats.BytesReceived))
+		metric(c.active, prometheus.GaugeValue, float64(proxy.ActiveCount()))
+		metric(c.disabled, prometheus.GaugeValue, disabled)
+	}
+}
+
+// proxyLabel returns the proxy label value, the URL of the proxy with the password redacted.
+func proxyLabel(proxy *proxym.Proxy) string {
+	if u := proxy.URL(); u != nil {
+		return u.Redacted()
+	}
+	return proxy.String()
+}
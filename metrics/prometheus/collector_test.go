@@ -0,0 +1,63 @@
+package prometheus_test
+
+import (
+	"testing"
+
+	"github.com/nezbut/proxym"
+	proxymprom "github.com/nezbut/proxym/metrics/prometheus"
+	"github.com/nezbut/proxym/rotations"
+	"github.com/nezbut/proxym/selects"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func newResource(proxy *proxym.Proxy, opts ...proxym.ResourceConfigOption) *proxym.ResourceConfig {
+	return proxym.NewResourceConfig(true, append([]proxym.ResourceConfigOption{
+		proxym.WithResourceProxies(proxy),
+		proxym.WithResourceRotationStrategy(rotations.DefaultRotationStrategy()),
+		proxym.WithResourceSelectStrategy(selects.DefaultSelectStrategy()),
+	}, opts...)...)
+}
+
+func TestCollectorGatherResourcesWithoutUniqueDomain(t *testing.T) {
+	proxy := proxym.NewProxyStr("http://proxy:8080", nil)
+	pm := proxym.NewProxyManager(
+		proxym.WithProxies(proxy),
+		proxym.WithRotationStrategy(rotations.DefaultRotationStrategy()),
+		proxym.WithSelectStrategy(selects.DefaultSelectStrategy()),
+		proxym.WithResources(
+			newResource(proxy, proxym.WithDomainPattern(`^api\d+\.example\.com$`)),
+			newResource(proxy, proxym.WithDomainPattern(`^cdn\d+\.example\.com$`)),
+			newResource(proxy, proxym.WithDomain("a.com"), proxym.WithResourceMatcher(proxym.SchemeMatcher("http"))),
+			newResource(proxy, proxym.WithDomain("a.com"), proxym.WithResourceMatcher(proxym.SchemeMatcher("https"))),
+		),
+	)
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(proxymprom.NewCollector(pm))
+
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatalf("got error %v gathering the metrics, want the series of each pool", err)
+	}
+	pools := make(map[string]string)
+	for _, family := range families {
+		if family.GetName() != "proxym_pool_size" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			labels := make(map[string]string)
+			for _, label := range metric.GetLabel() {
+				labels[label.GetName()] = label.GetValue()
+			}
+			pools[labels["pool"]] = labels["domain"]
+		}
+	}
+	want := map[string]string{"global": "", "0": "", "1": "", "2": "a.com", "3": "a.com"}
+	if len(pools) != len(want) {
+		t.Fatalf("got %d pool_size series, want %d", len(pools), len(want))
+	}
+	for pool, domain := range want {
+		if got, ok := pools[pool]; !ok || got != domain {
+			t.Errorf("pool %q: got domain %q (present %t), want %q", pool, got, ok, domain)
+		}
+	}
+}
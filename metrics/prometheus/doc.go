@@ -0,0 +1,2 @@
+// Package prometheus provides a prometheus.Collector that exports the metrics of a proxym.ProxyManagerImpl.
+package prometheus
@@ -1,5 +1,11 @@
 package proxym
 
+import (
+	"net/http"
+	"regexp"
+	"time"
+)
+
 // ProxyManagerImplOption is option for ProxyManagerImpl.
 type ProxyManagerImplOption func(*ProxyManagerImpl)
 
@@ -10,6 +16,147 @@ func WithProxies(proxies ...*Proxy) ProxyManagerImplOption {
 	}
 }
 
+// WithEnvironmentProxies adds the proxies and the resources configured by the HTTP_PROXY, HTTPS_PROXY
+// and NO_PROXY environment variables to the ProxyManagerImpl, see EnvironmentProxies.Resources,
+// so the manager routes the requests like http.ProxyFromEnvironment with the rotation of the global pool on top.
+//
+// The proxies are added to the proxies set by WithProxies, if it goes before.
+// Invalid variables are ignored, use NewProxyFromEnvironment to check them.
+func WithEnvironmentProxies() ProxyManagerImplOption {
+	return func(pm *ProxyManagerImpl) {
+		env, err := NewProxyFromEnvironment()
+		if err != nil {
+			return
+		}
+		pm.proxies = append(pm.proxies, env.Proxies()...)
+		pm.resources = append(pm.resources, env.Resources()...)
+	}
+}
+
+// WithMaxPool sets the maximum size of the proxy pool and the policy used to evict proxies when it is exceeded.
+//
+// If n is less than or equal to 0, the pool size is unlimited.
+func WithMaxPool(n int, policy EvictionPolicy) ProxyManagerImplOption {
+	return func(pm *ProxyManagerImpl) {
+		pm.maxPool = n
+		pm.evictionPolicy = policy
+	}
+}
+
+// WithDefaultMetadata sets the default metadata to the ProxyManagerImpl.
+//
+// A copy of the default metadata is set to every proxy created with nil metadata
+// that is added to the manager by WithProxies, AddProxies or SyncProxies, or to its resources
+// by WithResources, AddResources, AddResourceProxies or ResourceConfig.AddProxies.
+// Explicit metadata of the proxy always wins.
+func WithDefaultMetadata(meta *ProxyMetadata) ProxyManagerImplOption {
+	return func(pm *ProxyManagerImpl) {
+		pm.defaultMeta = meta
+	}
+}
+
+// WithFairShare enables the fair-share accounting of the proxies between the resources of the ProxyManagerImpl.
+//
+// When resources draw from the same proxies, a chatty resource can not hold more than its share
+// of the proxies with in-flight requests while other resources need them, see WithResourceShare.
+// The requests are tracked by ProxyTransport.
+func WithFairShare(enabled bool) ProxyManagerImplOption {
+	return func(pm *ProxyManagerImpl) {
+		if enabled {
+			pm.fairShare = newFairShare()
+		} else {
+			pm.fairShare = nil
+		}
+	}
+}
+
+// WithPoolDebounce sets the debounce duration of the OnPoolExhausted and OnPoolRecovered callbacks.
+//
+// After a change the pool is checked again when the duration passes,
+// so rapid flapping within the duration does not call the callbacks.
+func WithPoolDebounce(debounce time.Duration) ProxyManagerImplOption {
+	return func(pm *ProxyManagerImpl) {
+		pm.pool.debounce = debounce
+	}
+}
+
+// WithPriorityTimeouts sets the timeouts of the requests by the priority tier of the proxy to the ProxyManagerImpl,
+// for example generous timeouts for reliable high-priority proxies and short ones for low-priority proxies.
+//
+// The timeout from the metadata of the proxy takes precedence, see ProxyManagerImpl.ProxyTimeout.
+func WithPriorityTimeouts(timeouts map[ProxyPriority]time.Duration) ProxyManagerImplOption {
+	return func(pm *ProxyManagerImpl) {
+		pm.priorityTimeouts = make(map[ProxyPriority]time.Duration, len(timeouts))
+		for priority, timeout := range timeouts {
+			pm.priorityTimeouts[priority] = timeout
+		}
+	}
+}
+
+// WithHealthScore sets the health score function used by ProxyManagerImpl.RankByHealth.
+func WithHealthScore(score HealthScoreFunc) ProxyManagerImplOption {
+	return func(pm *ProxyManagerImpl) {
+		pm.healthScore = score
+	}
+}
+
+// WithClock sets the clock used by the ProxyManagerImpl, by default SystemClock.
+//
+// The clock is also set to the proxies and the resources of the manager, so the cooldowns of the proxies,
+// see Proxy.DisableFor, the bans, see ResourceConfig.Ban, and the times of the statistics follow it.
+func WithClock(clock Clock) ProxyManagerImplOption {
+	return func(pm *ProxyManagerImpl) {
+		pm.clock = clock
+	}
+}
+
+// WithProxySource sets the source of the global pool to the ProxyManagerImpl,
+// the pool is refreshed from it by ProxyManagerImpl.StartRefresh, see ProxyRefresher.
+//
+// Use MergeSources to refresh the pool from several sources.
+func WithProxySource(source ProxySource, opts ...ProxyRefresherOption) ProxyManagerImplOption {
+	return func(pm *ProxyManagerImpl) {
+		pm.refresher = NewProxyRefresher(pm, source, opts...)
+	}
+}
+
+// WithCredentialProvider sets the provider of the short-lived proxy credentials to the ProxyManagerImpl.
+//
+// Before the selected proxy is used, the provider is called if the credentials of the proxy expire within before,
+// the new credentials are set by Proxy.SetCredentials. The proxies without the expiration time
+// of the credentials are not refreshed, see Proxy.SetCredentialsExpiresAt.
+// If the provider fails, the selection fails with *SelectionError with the error of the provider as the cause.
+func WithCredentialProvider(provider CredentialProvider, before time.Duration) ProxyManagerImplOption {
+	return func(pm *ProxyManagerImpl) {
+		pm.credentials = &credentialRefresher{provider: provider, before: before}
+	}
+}
+
+// WithStrictValidation enables the strict validation of the proxy urls added to the ProxyManagerImpl
+// by WithProxies, AddProxies, AddResourceProxies and the refreshes of the proxy source.
+//
+// The urls with a scheme out of the schemes, without a host or with an invalid port are rejected
+// with *ProxyURLError, see ValidateProxy. If schemes is empty, DefaultProxySchemes are allowed.
+func WithStrictValidation(schemes ...string) ProxyManagerImplOption {
+	return func(pm *ProxyManagerImpl) {
+		if len(schemes) == 0 {
+			schemes = DefaultProxySchemes
+		}
+		pm.validSchemes = schemes
+	}
+}
+
+// WithDuplicatePolicy sets the policy of the proxies added by WithProxies and AddProxies
+// whose normalized urls are already in the global pool, by default AllowDuplicates.
+//
+// Skipping the duplicates prevents double-weighting the same exit when the lists are merged from several sources,
+// see NormalizeProxyURL.
+func WithDuplicatePolicy(policy DuplicatePolicy) ProxyManagerImplOption {
+	return func(pm *ProxyManagerImpl) {
+		pm.duplicatePolicy = policy
+	}
+}
+
 // WithResources sets resources to the ProxyManagerImpl.
 func WithResources(resources ...*ResourceConfig) ProxyManagerImplOption {
 	return func(pm *ProxyManagerImpl) {
@@ -44,7 +191,7 @@ func WithResourceProxies(proxies ...*Proxy) ResourceConfigOption {
 // WithResourceSelectStrategy sets select strategy from factory to the ResourceConfig.
 func WithResourceSelectStrategy(factory SelectStrategyFactory) ResourceConfigOption {
 	return func(rc *ResourceConfig) {
-		rc.selectStrategy = factory(rc)
+		rc.selectStrategy = factory(unbannedProvider{rc: rc})
 	}
 }
 
@@ -55,6 +202,26 @@ func WithResourceRotationStrategy(strategy RotationStrategy) ResourceConfigOptio
 	}
 }
 
+// WithResourceShare sets the fair share of the ResourceConfig, the global pool has share 1.
+//
+// It is used only if the fair-share accounting is enabled by WithFairShare.
+func WithResourceShare(share uint) ResourceConfigOption {
+	return func(rc *ResourceConfig) {
+		rc.share = share
+	}
+}
+
+// WithResourceFallbackToGlobal sets the fallback of the ResourceConfig to the global pool.
+//
+// If fallback is true and the ResourceConfig has no usable proxy, that is, its proxy list is empty
+// or all its proxies are disabled, draining or expired, the domains of the ResourceConfig use the global proxies
+// and strategies instead of failing. The selection errors still report that the resource matched the domain.
+func WithResourceFallbackToGlobal(fallback bool) ResourceConfigOption {
+	return func(rc *ResourceConfig) {
+		rc.fallbackToGlobal = fallback
+	}
+}
+
 // WithDomain sets domain to the ResourceConfig.
 func WithDomain(domain string) ResourceConfigOption {
 	return func(rc *ResourceConfig) {
@@ -62,6 +229,75 @@ func WithDomain(domain string) ResourceConfigOption {
 	}
 }
 
+// WithDomainPattern adds the glob patterns of the domains to the ResourceConfig,
+// so one ResourceConfig can cover a family of hosts, for example "*.shop-*.example.com".
+//
+// The "*" matches any sequence of characters within a label of the domain, the "?" matches one such character.
+// The patterns are case-insensitive and are matched against the normalized domain, without the port and "www.".
+// If the ResourceConfig has only the patterns and no domain, the domain matches only the patterns.
+func WithDomainPattern(patterns ...string) ResourceConfigOption {
+	return func(rc *ResourceConfig) {
+		for _, pattern := range patterns {
+			rc.patterns = append(rc.patterns, globToRegexp(pattern))
+		}
+	}
+}
+
+// WithDomainRegexp adds the regular expressions of the domains to the ResourceConfig like WithDomainPattern.
+//
+// The expressions are matched against the normalized lowercase domain and are not anchored,
+// use "^" and "$" to match the whole domain, for example regexp.MustCompile(`^api\d+\.example\.com$`).
+func WithDomainRegexp(patterns ...*regexp.Regexp) ResourceConfigOption {
+	return func(rc *ResourceConfig) {
+		rc.patterns = append(rc.patterns, patterns...)
+	}
+}
+
+// WithETLDPlusOne sets the comparison of the domains by the registrable domain, eTLD+1, to the ResourceConfig.
+//
+// If compare is true, the domain matches the ResourceConfig if their registrable domains by the public suffix list
+// are equal, for example "shop.example.co.uk" matches a ResourceConfig for "api.example.co.uk",
+// but "foo.co.uk" does not match a ResourceConfig for "co.uk", which is a public suffix and matches only itself.
+func WithETLDPlusOne(compare bool) ResourceConfigOption {
+	return func(rc *ResourceConfig) {
+		rc.compareETLDPlusOne = compare
+	}
+}
+
+// WithResourceMatcher adds the matchers of the requests to the ResourceConfig,
+// so the ResourceConfig can be scoped to the requests by the port, the scheme, the path or the method,
+// for example proxym.PathPrefixMatcher("/api/") or proxym.MethodMatcher(http.MethodPost).
+//
+// A request matches the ResourceConfig if it matches the domain and all matchers, see ResourceConfig.Match.
+// The selections by domain, like GetNextProxy, have only the host, so they do not match the path or method matchers.
+func WithResourceMatcher(matchers ...ResourceMatcher) ResourceConfigOption {
+	return func(rc *ResourceConfig) {
+		rc.matchers = append(rc.matchers, matchers...)
+	}
+}
+
+// WithSchemes scopes the ResourceConfig to the requests with any of the schemes, see SchemeMatcher,
+// so the http and https traffic to the same host can use different proxy pools.
+func WithSchemes(schemes ...string) ResourceConfigOption {
+	return WithResourceMatcher(SchemeMatcher(schemes...))
+}
+
+// WithPorts scopes the ResourceConfig to the requests to any of the ports, see PortMatcher.
+func WithPorts(ports ...int) ResourceConfigOption {
+	return WithResourceMatcher(PortMatcher(ports...))
+}
+
+// WithMatchPriority sets the match priority to the ResourceConfig, by default 0.
+//
+// If several resources match a request, the resource with the highest priority wins,
+// among the resources with equal priority the most specific match wins, for example "api.example.com"
+// wins over "example.com" for api.example.com.
+func WithMatchPriority(priority int) ResourceConfigOption {
+	return func(rc *ResourceConfig) {
+		rc.matchPriority = priority
+	}
+}
+
 // WithIgnoreSubdomains sets ignore subdomains to the ResourceConfig.
 //
 // If ignore is true, then it will ignore subdomains in the comparison of the domain.
@@ -70,3 +306,89 @@ func WithIgnoreSubdomains(ignore bool) ResourceConfigOption {
 		rc.notIgnoreSubdomains = !ignore
 	}
 }
+
+// ProxyTransportOption is option for ProxyTransport.
+type ProxyTransportOption func(*ProxyTransport)
+
+// WithProxyHeader sets the name of the response header to which ProxyTransport adds the url of the proxy
+// that served the request, so downstream middleware and logs can attribute requests.
+func WithProxyHeader(name string) ProxyTransportOption {
+	return func(pt *ProxyTransport) {
+		pt.proxyHeader = http.CanonicalHeaderKey(name)
+	}
+}
+
+// WithFailureCooldown makes ProxyTransport disable the proxy for the cooldown by the policy, see Proxy.Cooldown,
+// when its consecutive errors reach the threshold. It applies the DisablePolicy with the threshold and the policy
+// after every request through the transport, see WithDisablePolicy for all requests of the ProxyManagerImpl.
+//
+// The consecutive errors are not reset by the end of the cooldown, so if the first request after it fails,
+// the proxy is disabled again for the doubled duration, until a successful response resets the backoff.
+func WithFailureCooldown(threshold uint, policy Cooldown) ProxyTransportOption {
+	return func(pt *ProxyTransport) {
+		pt.disablePolicy = DisablePolicy{Threshold: threshold, Backoff: policy}
+	}
+}
+
+// WithRateLimitWait makes ProxyTransport wait for a token of the rate limiter of the proxy, see Proxy.SetRateLimiter,
+// until the request context is done, instead of failing the request with ErrProxyRateLimited.
+func WithRateLimitWait(wait bool) ProxyTransportOption {
+	return func(pt *ProxyTransport) {
+		pt.waitRateLimit = wait
+	}
+}
+
+// WithSuccessPredicate sets the SuccessPredicate by which ProxyTransport classifies the results of the requests,
+// by default DefaultSuccessPredicate. The results are recorded to the statistics of the proxy and of the resource,
+// and the failures count for WithFailureCooldown.
+func WithSuccessPredicate(predicate SuccessPredicate) ProxyTransportOption {
+	return func(pt *ProxyTransport) {
+		pt.isSuccess = predicate
+	}
+}
+
+// WithResponseValidator sets the ResponseValidator that ProxyTransport calls with the responses
+// and up to peekSize bytes of their bodies to detect the blocked responses, for example the captcha pages.
+// The body is not peeked if peekSize is zero.
+//
+// A blocked response is recorded as an error of the proxy and of the resource whatever the SuccessPredicate is,
+// it is still returned to the caller. See WithBanBlocked.
+func WithResponseValidator(validator ResponseValidator, peekSize int) ProxyTransportOption {
+	return func(pt *ProxyTransport) {
+		pt.validator = validator
+		pt.peekSize = peekSize
+	}
+}
+
+// WithBanBlocked makes ProxyTransport ban the proxy of a blocked response for the resource of the request domain
+// for the ttl, or until it is unbanned if the ttl is zero, so the proxy keeps serving the other domains,
+// see WithResponseValidator and ResourceConfig.Ban.
+//
+// It has effect with ProxyManagerImpl only, the requests without a resource are not affected.
+func WithBanBlocked(ttl time.Duration) ProxyTransportOption {
+	return func(pt *ProxyTransport) {
+		pt.banBlocked = true
+		pt.banTTL = ttl
+	}
+}
+
+// RetryTransportOption is option for RetryTransport.
+type RetryTransportOption func(*RetryTransport)
+
+// WithMaxRetries sets the maximum number of retries of the failed request, by default 3.
+func WithMaxRetries(maxRetries int) RetryTransportOption {
+	return func(rt *RetryTransport) {
+		rt.maxRetries = maxRetries
+	}
+}
+
+// WithRetryStatusCodes sets the response status codes on which the request is retried,
+// by default 429, 502, 503 and 504.
+func WithRetryStatusCodes(codes ...int) RetryTransportOption {
+	return func(rt *RetryTransport) {
+		rt.statusCodes = make(map[int]struct{}, len(codes))
+		for _, code := range codes {
+			rt.statusCodes[code] = struct{}{}
+		}
+	}
+}
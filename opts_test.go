@@ -0,0 +1,44 @@
+package proxym_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nezbut/proxym"
+)
+
+func TestWithDefaultMetadata(t *testing.T) {
+	defaults := proxym.NewProxyMetadata("DE", proxym.ProxyPriorityMedium, time.Time{})
+	explicit := proxym.NewProxyMetadata("US", proxym.ProxyPriorityMedium, time.Time{})
+
+	global := proxym.NewProxyStr("http://global:8080", nil)
+	globalExplicit := proxym.NewProxyStr("http://global-explicit:8080", explicit)
+	resource := proxym.NewProxyStr("http://resource:8080", nil)
+	rc := newResource("a.com", resource)
+	pm := newManager([]*proxym.Proxy{global, globalExplicit},
+		proxym.WithDefaultMetadata(defaults), proxym.WithResources(rc))
+
+	added := proxym.NewProxyStr("http://added:8080", nil)
+	rc.AddProxies(added)
+	later := proxym.NewProxyStr("http://later:8080", nil)
+	laterExplicit := proxym.NewProxyStr("http://later-explicit:8080", explicit)
+	pm.AddResources(newResource("b.com", later, laterExplicit))
+	viaManager := proxym.NewProxyStr("http://via-manager:8080", nil)
+	if err := pm.AddResourceProxies("a.com", viaManager); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, p := range []*proxym.Proxy{global, resource, added, later, viaManager} {
+		if got := p.Metadata().Country(); got != "DE" {
+			t.Errorf("%s: got country %q, want the default %q", p.URL(), got, "DE")
+		}
+		if p.Metadata() == defaults {
+			t.Errorf("%s: got the default metadata itself, want a copy", p.URL())
+		}
+	}
+	for _, p := range []*proxym.Proxy{globalExplicit, laterExplicit} {
+		if p.Metadata() != explicit {
+			t.Errorf("%s: explicit metadata was replaced by the default", p.URL())
+		}
+	}
+}
@@ -0,0 +1,190 @@
+package pac
+
+import (
+	"context"
+	"net"
+	"strings"
+
+	"github.com/robertkrimen/otto"
+)
+
+// builtins are the standard PAC functions implemented in JavaScript.
+const builtins = `
+function dnsDomainIs(host, domain) {
+	return host.length >= domain.length && host.substring(host.length - domain.length) == domain;
+}
+
+function dnsDomainLevels(host) {
+	return host.split('.').length - 1;
+}
+
+function isPlainHostName(host) {
+	return host.indexOf('.') == -1;
+}
+
+function localHostOrDomainIs(host, hostdom) {
+	return host == hostdom || hostdom.lastIndexOf(host + '.', 0) == 0;
+}
+
+function isResolvable(host) {
+	return dnsResolve(host) != null;
+}
+
+function shExpMatch(str, pattern) {
+	pattern = pattern.replace(/[.+^${}()|[\]\\]/g, '\\$&').replace(/\*/g, '.*').replace(/\?/g, '.');
+	return new RegExp('^' + pattern + '$').test(str);
+}
+
+var __pacDays = ['SUN', 'MON', 'TUE', 'WED', 'THU', 'FRI', 'SAT'];
+var __pacMonths = ['JAN', 'FEB', 'MAR', 'APR', 'MAY', 'JUN', 'JUL', 'AUG', 'SEP', 'OCT', 'NOV', 'DEC'];
+
+function __pacArgs(args) {
+	var list = Array.prototype.slice.call(args);
+	var gmt = list.length > 0 && list[list.length - 1] == 'GMT';
+	if (gmt) {
+		list.pop();
+	}
+	return {list: list, gmt: gmt};
+}
+
+function __pacInRange(value, from, to) {
+	return from <= to ? value >= from && value <= to : value >= from || value <= to;
+}
+
+function weekdayRange() {
+	var args = __pacArgs(arguments);
+	var now = new Date();
+	var day = args.gmt ? now.getUTCDay() : now.getDay();
+	var from = __pacDays.indexOf(args.list[0]);
+	var to = args.list.length > 1 ? __pacDays.indexOf(args.list[1]) : from;
+	return from >= 0 && to >= 0 && __pacInRange(day, from, to);
+}
+
+function timeRange() {
+	var args = __pacArgs(arguments);
+	var list = args.list;
+	var now = new Date();
+	var hour = args.gmt ? now.getUTCHours() : now.getHours();
+	var minute = args.gmt ? now.getUTCMinutes() : now.getMinutes();
+	var second = args.gmt ? now.getUTCSeconds() : now.getSeconds();
+	var current = hour * 3600 + minute * 60 + second;
+	switch (list.length) {
+	case 1:
+		return hour == list[0];
+	case 2:
+		return __pacInRange(current, list[0] * 3600, list[1] * 3600 - 1);
+	case 4:
+		return __pacInRange(current, list[0] * 3600 + list[1] * 60, list[2] * 3600 + list[3] * 60 - 1);
+	case 6:
+		return __pacInRange(current, list[0] * 3600 + list[1] * 60 + list[2], list[3] * 3600 + list[4] * 60 + list[5]);
+	default:
+		return false;
+	}
+}
+
+function dateRange() {
+	var args = __pacArgs(arguments);
+	var list = args.list;
+	if (list.length == 0 || list.length > 6) {
+		return false;
+	}
+	var now = new Date();
+	var today = {
+		d: args.gmt ? now.getUTCDate() : now.getDate(),
+		m: args.gmt ? now.getUTCMonth() : now.getMonth(),
+		y: args.gmt ? now.getUTCFullYear() : now.getFullYear()
+	};
+	function parse(values) {
+		var spec = {};
+		for (var i = 0; i < values.length; i++) {
+			if (typeof values[i] == 'string') {
+				spec.m = __pacMonths.indexOf(values[i].toUpperCase());
+				if (spec.m < 0) {
+					return null;
+				}
+			} else if (values[i] > 31) {
+				spec.y = values[i];
+			} else {
+				spec.d = values[i];
+			}
+		}
+		return spec;
+	}
+	function key(spec, date) {
+		return (spec.y !== undefined ? date.y * 10000 : 0) +
+			(spec.m !== undefined ? (date.m + 1) * 100 : 0) +
+			(spec.d !== undefined ? date.d : 0);
+	}
+	if (list.length % 2 == 1) {
+		var one = parse(list);
+		return one != null && key(one, today) == key(one, one);
+	}
+	var from = parse(list.slice(0, list.length / 2));
+	var to = parse(list.slice(list.length / 2));
+	return from != null && to != null && __pacInRange(key(from, today), key(from, from), key(to, to));
+}
+`
+
+// defineBuiltins defines the standard PAC functions in the runtime.
+//
+// The DNS functions resolve the hosts with the resolver and the context of the current evaluation.
+func (s *Script) defineBuiltins(vm *otto.Otto) error {
+	if err := vm.Set("dnsResolve", func(host string) otto.Value {
+		if ip := s.resolve(host); ip != nil {
+			value, _ := otto.ToValue(ip.String())
+			return value
+		}
+		return otto.NullValue()
+	}); err != nil {
+		return err
+	}
+	if err := vm.Set("myIpAddress", func() string {
+		return myIPAddress()
+	}); err != nil {
+		return err
+	}
+	if err := vm.Set("isInNet", func(host, pattern, mask string) bool {
+		ip, network, netMask := s.resolve(host), net.ParseIP(pattern).To4(), net.ParseIP(mask).To4()
+		if ip == nil || network == nil || netMask == nil {
+			return false
+		}
+		return ip.Mask(net.IPMask(netMask)).Equal(network.Mask(net.IPMask(netMask)))
+	}); err != nil {
+		return err
+	}
+	_, err := vm.Run(builtins)
+	return err
+}
+
+// resolve returns the IPv4 address of the host, the host may be an IPv4 address itself.
+//
+// It returns nil if the host can not be resolved.
+func (s *Script) resolve(host string) net.IP {
+	if ip := net.ParseIP(host); ip != nil {
+		return ip.To4()
+	}
+	ctx := s.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ips, err := s.resolver.LookupIP(ctx, "ip4", strings.TrimSuffix(host, "."))
+	if err != nil || len(ips) == 0 {
+		return nil
+	}
+	return ips[0].To4()
+}
+
+// myIPAddress returns the IPv4 address of the host through which the outbound traffic goes.
+//
+// No packets are sent, the UDP socket only selects the route.
+func myIPAddress() string {
+	conn, err := net.Dial("udp4", "192.0.2.1:80")
+	if err != nil {
+		return "127.0.0.1"
+	}
+	defer conn.Close()
+	if addr, ok := conn.LocalAddr().(*net.UDPAddr); ok {
+		return addr.IP.String()
+	}
+	return "127.0.0.1"
+}
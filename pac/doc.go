@@ -0,0 +1,12 @@
+// Package pac provides the evaluation of the proxy auto-config (PAC) files.
+//
+// The PAC script is evaluated per request host by the JavaScript interpreter with the standard PAC functions,
+// like isInNet, shExpMatch and dnsDomainIs. NewResource feeds the proxies returned by FindProxyForURL
+// into the proxym.ProxyManagerImpl as a dynamic resource:
+//
+//	script, err := pac.LoadFile("proxy.pac")
+//	if err != nil {
+//	    return err
+//	}
+//	pm.AddResources(pac.NewResource(script))
+package pac
@@ -0,0 +1,170 @@
+package pac
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/robertkrimen/otto"
+)
+
+// Errors of the PAC evaluation.
+var (
+	ErrInvalidScript = errors.New("invalid PAC script")
+	ErrInvalidResult = errors.New("invalid PAC result")
+	ErrInterrupted   = errors.New("PAC evaluation interrupted")
+)
+
+// Script is the compiled PAC script.
+//
+// It is safe for concurrent use, the evaluations are serialized as the interpreter is single-threaded.
+type Script struct {
+	vm       *otto.Otto
+	resolver *net.Resolver
+	ctx      context.Context //nolint: containedctx // the context of the current evaluation for the DNS functions
+	mu       sync.Mutex
+}
+
+// Option is option for Script.
+type Option func(*Script)
+
+// WithResolver sets the resolver of the DNS functions of the script, like dnsResolve and isInNet,
+// by default net.DefaultResolver.
+func WithResolver(resolver *net.Resolver) Option {
+	return func(s *Script) {
+		s.resolver = resolver
+	}
+}
+
+// LoadFile reads and compiles the PAC script from the file, see Parse.
+func LoadFile(path string, opts ...Option) (*Script, error) {
+	src, err := os.ReadFile(path) //nolint: gosec // the path of the PAC file is provided by the user
+	if err != nil {
+		return nil, err
+	}
+	return Parse(src, opts...)
+}
+
+// Parse compiles the PAC script, the script must define the FindProxyForURL(url, host) function.
+func Parse(src []byte, opts ...Option) (*Script, error) {
+	s := &Script{vm: otto.New(), resolver: net.DefaultResolver}
+	for _, opt := range opts {
+		opt(s)
+	}
+	s.vm.Interrupt = make(chan func(), 1)
+
+	if err := s.defineBuiltins(s.vm); err != nil {
+		return nil, err
+	}
+	if _, err := s.vm.Run(string(src)); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidScript, err)
+	}
+	if fn, err := s.vm.Get("FindProxyForURL"); err != nil || !fn.IsFunction() {
+		return nil, fmt.Errorf("%w: FindProxyForURL is not defined", ErrInvalidScript)
+	}
+	return s, nil
+}
+
+// FindProxyForURL evaluates the FindProxyForURL function of the script and returns its result,
+// for example "PROXY proxy1:8080; DIRECT", see ParseResult.
+//
+// The evaluation is interrupted with ErrInterrupted when the context is done.
+func (s *Script) FindProxyForURL(ctx context.Context, rawURL, host string) (result string, err error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ctx = ctx
+	defer func() { s.ctx = nil }()
+
+	interrupted := make(chan struct{})
+	stop := context.AfterFunc(ctx, func() {
+		defer close(interrupted)
+		s.vm.Interrupt <- func() {
+			panic(ErrInterrupted)
+		}
+	})
+	defer func() {
+		if !stop() {
+			<-interrupted
+			select {
+			case <-s.vm.Interrupt:
+			default:
+			}
+		}
+		if caught := recover(); caught != nil {
+			if caught != ErrInterrupted { //nolint: errorlint // the panic value is the sentinel itself
+				panic(caught)
+			}
+			result, err = "", fmt.Errorf("%w: %w", ErrInterrupted, ctx.Err())
+		}
+	}()
+
+	value, err := s.vm.Call("FindProxyForURL", nil, rawURL, host)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrInvalidScript, err)
+	}
+	return value.String(), nil
+}
+
+// FindProxies evaluates the script like FindProxyForURL and parses the result, see ParseResult.
+func (s *Script) FindProxies(ctx context.Context, rawURL, host string) ([]*url.URL, error) {
+	result, err := s.FindProxyForURL(ctx, rawURL, host)
+	if err != nil {
+		return nil, err
+	}
+	return ParseResult(result)
+}
+
+// ParseResult parses the result of FindProxyForURL into the proxy urls in order of preference,
+// a nil url is the direct connection.
+//
+// The PROXY and HTTP directives are http proxies, HTTPS are https proxies,
+// SOCKS and SOCKS5 are socks5 proxies and SOCKS4 are socks4 proxies.
+// An empty result is the direct connection.
+func ParseResult(result string) ([]*url.URL, error) {
+	var proxies []*url.URL
+	for _, directive := range strings.Split(result, ";") {
+		fields := strings.Fields(directive)
+		if len(fields) == 0 {
+			continue
+		}
+		kind := strings.ToUpper(fields[0])
+		if kind == "DIRECT" {
+			proxies = append(proxies, nil)
+			continue
+		}
+		if len(fields) != 2 { //nolint: mnd // the type and the address of the directive
+			return nil, fmt.Errorf("%w: %q", ErrInvalidResult, directive)
+		}
+		scheme, ok := directiveSchemes[kind]
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown type %q", ErrInvalidResult, fields[0])
+		}
+		if _, _, err := net.SplitHostPort(fields[1]); err != nil {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidResult, directive)
+		}
+		proxies = append(proxies, &url.URL{Scheme: scheme, Host: fields[1]})
+	}
+	if len(proxies) == 0 {
+		proxies = append(proxies, nil)
+	}
+	return proxies, nil
+}
+
+// directiveSchemes are the schemes of the proxies by the type of the directive.
+var directiveSchemes = map[string]string{
+	"PROXY":  "http",
+	"HTTP":   "http",
+	"HTTPS":  "https",
+	"SOCKS":  "socks5",
+	"SOCKS5": "socks5",
+	"SOCKS4": "socks4",
+}
@@ -0,0 +1,104 @@
+package pac
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"slices"
+	"sync"
+
+	"github.com/nezbut/proxym"
+	"github.com/nezbut/proxym/rotations"
+)
+
+// directKey is the key of the direct connection in the proxies of the Select.
+const directKey = "DIRECT"
+
+// proxyAdder is implemented by the providers to which the selected proxies are added, like proxym.ResourceConfig.
+type proxyAdder interface {
+	AddProxies(proxies ...*proxym.Proxy)
+}
+
+// Select is a proxy selection strategy that returns the first usable proxy returned by the PAC script for the domain.
+//
+// The script is evaluated with the "http://domain/" url. The proxies are created once per url, so they keep
+// their statistics and state between the selections, and are added to the provider if it is proxym.ResourceConfig,
+// so the manager tracks them. The disabled and draining proxies are skipped.
+type Select struct {
+	script   *Script
+	provider proxym.SelectStrategyProxyProvider
+	proxies  map[string]*proxym.Proxy
+	mu       sync.Mutex
+}
+
+// NewSelectFactory returns a new proxym.SelectStrategyFactory for Select with the script.
+func NewSelectFactory(script *Script) proxym.SelectStrategyFactory {
+	return func(provider proxym.SelectStrategyProxyProvider) proxym.SelectStrategy {
+		return &Select{
+			script:   script,
+			provider: provider,
+			proxies:  make(map[string]*proxym.Proxy),
+		}
+	}
+}
+
+// NewResource returns the proxym.ResourceConfig that matches any request and selects the proxy by the script,
+// see Select. It rotates on every request, as the proxy depends on the host of the request.
+//
+// The options are applied after the defaults, for example proxym.WithDomain scopes the resource to a domain.
+// As it matches any host, the resources with a domain or a pattern win over it.
+func NewResource(script *Script, opts ...proxym.ResourceConfigOption) *proxym.ResourceConfig {
+	return proxym.NewResourceConfig(true, append([]proxym.ResourceConfigOption{
+		proxym.WithResourceMatcher(proxym.ResourceMatcherFunc(func(*proxym.RequestInfo) bool {
+			return true
+		})),
+		proxym.WithResourceSelectStrategy(NewSelectFactory(script)),
+		proxym.WithResourceRotationStrategy(rotations.NewStickyRoundRobinRotation(1)),
+	}, opts...)...)
+}
+
+// Select returns the proxy for the empty domain.
+func (s *Select) Select() (*proxym.Proxy, error) {
+	return s.SelectDomain(context.Background(), "")
+}
+
+// SelectDomain returns the first usable proxy returned by the script for the domain.
+func (s *Select) SelectDomain(ctx context.Context, domain string) (*proxym.Proxy, error) {
+	urls, err := s.script.FindProxies(ctx, (&url.URL{Scheme: "http", Host: domain, Path: "/"}).String(), domain)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", proxym.ErrFailedSelectProxy, err)
+	}
+
+	for _, u := range urls {
+		proxy := s.proxy(u)
+		if !proxy.IsDisabled() && !proxy.IsDraining() {
+			return proxy, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: no usable proxy returned by the PAC script", proxym.ErrFailedSelectProxy)
+}
+
+// proxy returns the proxy of the url, nil is the direct connection, and adds it to the provider.
+func (s *Select) proxy(u *url.URL) *proxym.Proxy {
+	key := directKey
+	if u != nil {
+		key = u.String()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	proxy, ok := s.proxies[key]
+	if !ok {
+		if u == nil {
+			proxy = proxym.NewDirectConnection()
+		} else {
+			proxy = proxym.NewProxy(u, nil)
+		}
+		s.proxies[key] = proxy
+	}
+
+	if adder, ok := s.provider.(proxyAdder); ok && !slices.Contains(s.provider.GetProxies(), proxy) {
+		adder.AddProxies(proxy)
+	}
+	return proxy
+}
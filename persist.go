@@ -0,0 +1,174 @@
+package proxym
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+)
+
+// ErrNoSnapshot is the error of a Store that has no saved snapshot yet.
+var ErrNoSnapshot = errors.New("no snapshot")
+
+// Store is a persistence store of the state of the proxies, so the statistics, the disabled flags
+// and the metadata survive the process restarts, see ProxyManagerImpl.SaveState and ProxyManagerImpl.LoadState.
+type Store interface {
+	// Save saves the snapshot replacing the saved one.
+	Save(ctx context.Context, snapshot *FleetSnapshot) error
+	// Load returns the saved snapshot, ErrNoSnapshot if nothing is saved.
+	Load(ctx context.Context) (*FleetSnapshot, error)
+}
+
+// FleetSnapshot is a point-in-time copy of the state of the proxies of the full fleet.
+type FleetSnapshot struct {
+	// SavedAt is the time when the snapshot was taken.
+	SavedAt time.Time `json:"saved_at"`
+	// Proxies are the states of the proxies.
+	Proxies []ProxyState `json:"proxies"`
+}
+
+// ProxyState is a point-in-time copy of the state of a proxy, the proxy is identified by its id, see Proxy.ID.
+type ProxyState struct {
+	ID            string           `json:"id"`
+	Disabled      bool             `json:"disabled"`
+	DisabledUntil time.Time        `json:"disabled_until"`
+	Cooldowns     uint             `json:"cooldowns"`
+	Stats         StatsSnapshot    `json:"stats"`
+	Metadata      MetadataSnapshot `json:"metadata"`
+}
+
+// MetadataSnapshot is a point-in-time copy of the proxy metadata.
+type MetadataSnapshot struct {
+	Country   string        `json:"country"`
+	Priority  ProxyPriority `json:"priority"`
+	ExpiresAt time.Time     `json:"expires_at"`
+	Weight    uint          `json:"weight"`
+	Timeout   time.Duration `json:"timeout"`
+	Headers   http.Header   `json:"headers,omitempty"`
+	Tags      []string      `json:"tags,omitempty"`
+}
+
+// Snapshot returns a consistent copy of the metadata.
+func (m *ProxyMetadata) Snapshot() MetadataSnapshot {
+	tags := m.Tags()
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return MetadataSnapshot{
+		Country:   m.country,
+		Priority:  m.priority,
+		ExpiresAt: m.expiresAt,
+		Weight:    m.weight,
+		Timeout:   m.timeout,
+		Headers:   m.headers.Clone(),
+		Tags:      tags,
+	}
+}
+
+// Restore sets the metadata from the snapshot.
+func (m *ProxyMetadata) Restore(snapshot MetadataSnapshot) {
+	var tags map[string]struct{}
+	if len(snapshot.Tags) != 0 {
+		tags = make(map[string]struct{}, len(snapshot.Tags))
+		for _, tag := range snapshot.Tags {
+			tags[tag] = struct{}{}
+		}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.country = snapshot.Country
+	m.priority = snapshot.Priority
+	m.expiresAt = snapshot.ExpiresAt
+	m.weight = snapshot.Weight
+	m.timeout = snapshot.Timeout
+	m.headers = snapshot.Headers.Clone()
+	m.tags = tags
+}
+
+// State returns a consistent copy of the state of the proxy.
+func (p *Proxy) State() ProxyState {
+	p.mu.RLock()
+	state := ProxyState{
+		ID:            p.id,
+		Disabled:      p.isDisabled,
+		DisabledUntil: p.disabledTill,
+		Cooldowns:     p.cooldowns,
+	}
+	p.mu.RUnlock()
+	state.Stats = p.Stats().Snapshot()
+	state.Metadata = p.Metadata().Snapshot()
+	return state
+}
+
+// RestoreState sets the state of the proxy from the state saved by State, the id of the state is ignored.
+//
+// A proxy whose cooldown is over by now is enabled.
+func (p *Proxy) RestoreState(state ProxyState) {
+	disabled := state.Disabled && (state.DisabledUntil.IsZero() || time.Now().Before(state.DisabledUntil))
+
+	p.mu.Lock()
+	p.isDisabled = disabled
+	p.disabledTill = time.Time{}
+	if disabled {
+		p.disabledTill = state.DisabledUntil
+	}
+	p.cooldowns = state.Cooldowns
+	p.implicitMeta = false
+	p.mu.Unlock()
+
+	p.Stats().Restore(state.Stats)
+	p.Metadata().Restore(state.Metadata)
+	p.notify()
+}
+
+// FleetSnapshot returns the state of the proxies of the full fleet.
+func (pm *ProxyManagerImpl) FleetSnapshot() *FleetSnapshot {
+	fleet := pm.fleet()
+	snapshot := &FleetSnapshot{
+		SavedAt: pm.clock.Now(),
+		Proxies: make([]ProxyState, 0, len(fleet)),
+	}
+	for _, p := range fleet {
+		snapshot.Proxies = append(snapshot.Proxies, p.State())
+	}
+	return snapshot
+}
+
+// SaveState saves the state of the proxies of the full fleet to the store.
+func (pm *ProxyManagerImpl) SaveState(ctx context.Context, store Store) error {
+	return store.Save(ctx, pm.FleetSnapshot())
+}
+
+// LoadState loads the saved state from the store and restores the state of the proxies of the full fleet
+// with the same ids, see Proxy.RestoreState. It returns the number of the restored proxies.
+//
+// The saved states of the proxies that are not in the fleet are ignored.
+// If the store has no saved snapshot, it returns 0 and nil.
+func (pm *ProxyManagerImpl) LoadState(ctx context.Context, store Store) (int, error) {
+	snapshot, err := store.Load(ctx)
+	if errors.Is(err, ErrNoSnapshot) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return pm.RestoreFleet(snapshot), nil
+}
+
+// RestoreFleet restores the state of the proxies of the full fleet with the same ids from the snapshot
+// and returns the number of the restored proxies, see LoadState.
+func (pm *ProxyManagerImpl) RestoreFleet(snapshot *FleetSnapshot) int {
+	states := make(map[string]ProxyState, len(snapshot.Proxies))
+	for _, state := range snapshot.Proxies {
+		states[state.ID] = state
+	}
+
+	restored := 0
+	for _, p := range pm.fleet() {
+		if state, ok := states[p.ID()]; ok {
+			p.RestoreState(state)
+			restored++
+		}
+	}
+	return restored
+}
@@ -0,0 +1,159 @@
+package proxym
+
+import (
+	"sync"
+	"time"
+)
+
+// poolMonitor fires the callbacks when the pool of the manager transitions to or from fully-unavailable.
+type poolMonitor struct {
+	onExhausted []func()
+	onRecovered []func()
+	debounce    time.Duration
+	usable      bool
+	timer       *time.Timer
+	mu          sync.Mutex
+}
+
+// HasUsableProxy returns true if the full fleet, that is, the global proxies and the proxies of all resources,
+// has at least one proxy that is not disabled, draining or expired.
+func (pm *ProxyManagerImpl) HasUsableProxy() bool {
+	return pm.hasUsableProxy(pm.fleet())
+}
+
+// hasUsableProxy returns true if at least one of the proxies is not disabled, draining or expired.
+func (pm *ProxyManagerImpl) hasUsableProxy(proxies []*Proxy) bool {
+	now := pm.clock.Now()
+	for _, p := range proxies {
+		if !p.IsDisabled() && !p.IsDraining() && !p.Metadata().IsExpired(now) {
+			return true
+		}
+	}
+	return false
+}
+
+// OnPoolExhausted registers the function called when the pool transitions to having no usable proxy.
+//
+// The pool is checked by HasUsableProxy when a proxy of the fleet is disabled, enabled, drained or recycled,
+// when proxies or resources are added and when GetNextProxy fails.
+// The callbacks are edge-triggered: they are called once per transition, not per failed request.
+// See WithPoolDebounce to suppress rapid flapping.
+func (pm *ProxyManagerImpl) OnPoolExhausted(fn func()) {
+	pm.pool.mu.Lock()
+	defer pm.pool.mu.Unlock()
+	pm.pool.onExhausted = append(pm.pool.onExhausted, fn)
+}
+
+// OnPoolRecovered registers the function called when the pool transitions from having no usable proxy
+// to having at least one. See OnPoolExhausted.
+func (pm *ProxyManagerImpl) OnPoolRecovered(fn func()) {
+	pm.pool.mu.Lock()
+	defer pm.pool.mu.Unlock()
+	pm.pool.onRecovered = append(pm.pool.onRecovered, fn)
+}
+
+// checkPool checks the pool and calls the callbacks on the transition.
+//
+// With the debounce the pool is checked again after the debounce duration
+// and the callbacks are called only if the pool is still in the new state.
+func (pm *ProxyManagerImpl) checkPool() {
+	pm.pool.mu.Lock()
+	if len(pm.pool.onExhausted) == 0 && len(pm.pool.onRecovered) == 0 {
+		pm.pool.mu.Unlock()
+		return
+	}
+	if pm.pool.debounce > 0 {
+		if pm.pool.timer == nil {
+			pm.pool.timer = time.AfterFunc(pm.pool.debounce, pm.transitionPool)
+		}
+		pm.pool.mu.Unlock()
+		return
+	}
+	pm.pool.mu.Unlock()
+	pm.transitionPool()
+}
+
+// transitionPool calls the callbacks if the pool transitioned since the last call.
+func (pm *ProxyManagerImpl) transitionPool() {
+	usable := pm.HasUsableProxy()
+
+	pm.pool.mu.Lock()
+	pm.pool.timer = nil
+	if usable == pm.pool.usable {
+		pm.pool.mu.Unlock()
+		return
+	}
+	pm.pool.usable = usable
+	callbacks := pm.pool.onExhausted
+	if usable {
+		callbacks = pm.pool.onRecovered
+	}
+	callbacks = append([]func(){}, callbacks...)
+	pm.pool.mu.Unlock()
+
+	for _, fn := range callbacks {
+		fn()
+	}
+}
+
+// watchProxies registers the ProxyManagerImpl to check the pool when the state of the proxies is changed.
+//
+// The half-life of the decayed counts is set to the proxies, see WithStatsHalfLife.
+func (pm *ProxyManagerImpl) watchProxies(proxies []*Proxy) {
+	for _, p := range proxies {
+		p.setClock(pm.clock)
+	}
+	pm.applyStatsHalfLife(proxies)
+	pm.events.trackDisabled(proxies)
+	for _, p := range proxies {
+		p.watch(pm, pm.onProxyChanged)
+	}
+}
+
+// watchResources registers the ProxyManagerImpl to check the pool when proxies are added to the resources
+// or the state of their proxies is changed.
+func (pm *ProxyManagerImpl) watchResources(resources []*ResourceConfig) {
+	for _, rc := range resources {
+		rc.setClock(pm.clock)
+		rc.setOnAdd(func(proxies []*Proxy) {
+			pm.applyDefaultMetadata(proxies)
+			pm.watchProxies(proxies)
+			pm.checkPool()
+		})
+		rc.setOnRemove(pm.onProxiesRemoved)
+		proxies := rc.GetProxies()
+		pm.applyDefaultMetadata(proxies)
+		pm.watchProxies(proxies)
+	}
+}
+
+// unwatchProxies unregisters the ProxyManagerImpl from the proxies that are no longer in the fleet.
+func (pm *ProxyManagerImpl) unwatchProxies(proxies []*Proxy) {
+	fleet := make(map[*Proxy]struct{})
+	for _, p := range pm.fleet() {
+		fleet[p] = struct{}{}
+	}
+	unwatched := make([]*Proxy, 0, len(proxies))
+	for _, p := range proxies {
+		if _, ok := fleet[p]; !ok {
+			p.unwatch(pm)
+			unwatched = append(unwatched, p)
+		}
+	}
+	pm.events.untrackDisabled(unwatched)
+}
+
+// onProxiesRemoved is called when proxies are removed from the global pool or from a resource.
+//
+// A removed proxy is cleared from the last used if it is currently selected.
+func (pm *ProxyManagerImpl) onProxiesRemoved(proxies []*Proxy) {
+	pm.clearLastUsed(proxies...)
+	pm.unwatchProxies(proxies)
+	pm.checkPool()
+}
+
+// onProxyChanged is called when the state of a proxy of the fleet is changed.
+func (pm *ProxyManagerImpl) onProxyChanged(p *Proxy) {
+	pm.events.proxyChanged(p)
+	pm.checkPool()
+}
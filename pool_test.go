@@ -0,0 +1,57 @@
+package proxym_test
+
+import (
+	"testing"
+
+	"github.com/nezbut/proxym"
+)
+
+func TestPoolExhaustedRecovered(t *testing.T) {
+	proxies := newProxies(3)
+	pm := newManager(proxies)
+
+	var exhausted, recovered int
+	pm.OnPoolExhausted(func() { exhausted++ })
+	pm.OnPoolRecovered(func() { recovered++ })
+
+	for _, p := range proxies {
+		p.Disable()
+	}
+	proxies[0].Enable()
+	proxies[1].Enable()
+
+	if exhausted != 1 || recovered != 1 {
+		t.Errorf("got %d exhausted and %d recovered events, want 1 and 1", exhausted, recovered)
+	}
+}
+
+func TestAddProxiesUnwatchesEvicted(t *testing.T) {
+	proxies := newProxies(3)
+	pm := newManager(proxies[:2], proxym.WithMaxPool(2, proxym.EvictOldestLastUsed))
+
+	var disabled []*proxym.Proxy
+	pm.OnDisable(func(p *proxym.Proxy) { disabled = append(disabled, p) })
+
+	if err := pm.AddProxies(proxies[2]); err != nil {
+		t.Fatal(err)
+	}
+	if got := len(pm.GetProxies()); got != 2 {
+		t.Fatalf("got %d proxies in the pool, want 2", got)
+	}
+
+	pool := make(map[*proxym.Proxy]bool)
+	for _, p := range pm.GetProxies() {
+		pool[p] = true
+	}
+	for _, p := range proxies {
+		p.Disable()
+	}
+	for _, p := range disabled {
+		if !pool[p] {
+			t.Errorf("OnDisable fired for the evicted proxy %s", p)
+		}
+	}
+	if len(disabled) != 2 {
+		t.Errorf("got %d OnDisable events, want 2", len(disabled))
+	}
+}
@@ -0,0 +1,60 @@
+package proxym
+
+import "time"
+
+// ProbeStats are the latencies of the proxy measured by the probes against a reference endpoint,
+// independent of the real traffic, see ProxyStats.Probe and healthcheck.LatencyProber.
+type ProbeStats struct {
+	// Connect is the rolling average duration of the connection to the proxy.
+	Connect time.Duration
+	// TTFB is the rolling average time to the first byte of the response, including the connection.
+	TTFB time.Duration
+	// Samples is the number of the successful probes.
+	Samples uint
+	// ProbedAt is the time of the last probe.
+	ProbedAt time.Time
+	// Failed is true if the last probe failed.
+	Failed bool
+}
+
+// SpeedScore returns the speed score of the proxy in (0, 1], the higher the faster: 1 / (1 + TTFB in seconds),
+// so the proxy with the TTFB of 1 second scores 0.5. It returns 0 if the proxy was not probed or the last probe failed.
+func (s ProbeStats) SpeedScore() float64 {
+	if s.Samples == 0 || s.Failed {
+		return 0
+	}
+	return 1 / (1 + s.TTFB.Seconds())
+}
+
+// ObserveProbe adds the connection duration and the time to the first byte of the successful probe
+// to the rolling averages of the probe latencies, see Probe.
+func (s *ProxyStats) ObserveProbe(connect, ttfb time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.probe.Samples == 0 {
+		s.probe.Connect, s.probe.TTFB = connect, ttfb
+	} else {
+		s.probe.Connect += time.Duration(latencyWeight * float64(connect-s.probe.Connect))
+		s.probe.TTFB += time.Duration(latencyWeight * float64(ttfb-s.probe.TTFB))
+	}
+	s.probe.Samples++
+	s.probe.ProbedAt = time.Now()
+	s.probe.Failed = false
+}
+
+// ObserveProbeFailure records the failed probe, the proxy scores 0 until the next successful probe.
+func (s *ProxyStats) ObserveProbeFailure() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.probe.ProbedAt = time.Now()
+	s.probe.Failed = true
+}
+
+// Probe returns the latencies of the proxy measured by the probes, see ObserveProbe.
+//
+// The probes are not requests, so they do not change the other statistics.
+func (s *ProxyStats) Probe() ProbeStats {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.probe
+}
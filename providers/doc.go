@@ -0,0 +1,14 @@
+// Package providers provides the proxym.ProxySource adapters for the APIs of the proxy vendors.
+//
+// The adapters authenticate with the API key, fetch the live proxy list and map the country
+// and the expiration time of the proxies into proxym.ProxyMetadata, for example:
+//
+//	pm := proxym.NewProxyManager(
+//	    proxym.WithProxySource(providers.NewWebshare(os.Getenv("WEBSHARE_API_KEY"))),
+//	    proxym.WithRotationStrategy(rotations.DefaultRotationStrategy()),
+//	    proxym.WithSelectStrategy(selects.DefaultSelectStrategy()),
+//	)
+//	pm.StartRefresh(ctx)
+//
+// The vendors with a JSON list endpoint are supported by JSONProvider with the mapping of the fields.
+package providers
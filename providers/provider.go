@@ -0,0 +1,105 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// Errors of the providers.
+var (
+	ErrUnexpectedStatus = errors.New("unexpected status")
+	ErrInvalidResponse  = errors.New("invalid response")
+)
+
+// Option is option for the providers.
+type Option func(*options)
+
+// options are the common options of the providers.
+type options struct {
+	client  *http.Client
+	baseURL string
+	header  http.Header
+	query   url.Values
+}
+
+// WithHTTPClient sets the client of the API requests, by default http.DefaultClient.
+func WithHTTPClient(client *http.Client) Option {
+	return func(o *options) {
+		o.client = client
+	}
+}
+
+// WithBaseURL sets the base url of the API, for example of a mock server or of a filtered list.
+func WithBaseURL(baseURL string) Option {
+	return func(o *options) {
+		o.baseURL = baseURL
+	}
+}
+
+// WithAPIKeyHeader sends the API key in the header of every request, for example "X-Api-Key".
+func WithAPIKeyHeader(name, apiKey string) Option {
+	return func(o *options) {
+		o.header.Set(name, apiKey)
+	}
+}
+
+// WithAPIKeyQuery sends the API key in the query parameter of every request, for example "auth".
+func WithAPIKeyQuery(param, apiKey string) Option {
+	return func(o *options) {
+		o.query.Set(param, apiKey)
+	}
+}
+
+// newOptions returns the options with the default base url.
+func newOptions(baseURL string, opts []Option) *options {
+	o := &options{
+		client:  http.DefaultClient,
+		baseURL: baseURL,
+		header:  make(http.Header),
+		query:   make(url.Values),
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// getJSON sends the GET request to the url with the headers and the query parameters of the options
+// and decodes the JSON response into out.
+func (o *options) getJSON(ctx context.Context, rawURL string, header http.Header, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, http.NoBody)
+	if err != nil {
+		return err
+	}
+	if len(o.query) != 0 {
+		query := req.URL.Query()
+		for key, values := range o.query {
+			query[key] = values
+		}
+		req.URL.RawQuery = query.Encode()
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	for name, values := range o.header {
+		req.Header[name] = values
+	}
+
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("%w: %s", ErrUnexpectedStatus, resp.Status)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidResponse, err)
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package providers
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/nezbut/proxym"
+)
+
+// webshareBaseURL is the base url of the Webshare API.
+const webshareBaseURL = "https://proxy.webshare.io/api/v2/"
+
+// webshareList is the page of the Webshare proxy list.
+type webshareList struct {
+	Next    *string         `json:"next"`
+	Results []webshareProxy `json:"results"`
+}
+
+// webshareProxy is the proxy of the Webshare proxy list.
+type webshareProxy struct {
+	Username     string `json:"username"`
+	Password     string `json:"password"`
+	ProxyAddress string `json:"proxy_address"`
+	Port         int    `json:"port"`
+	Valid        bool   `json:"valid"`
+	CountryCode  string `json:"country_code"`
+}
+
+// Webshare is a proxym.ProxySource of the proxy list of the Webshare account.
+//
+// It fetches all pages of the list in the direct mode, the invalid proxies are skipped.
+// The proxies have the country of the proxy in the metadata.
+type Webshare struct {
+	apiKey string
+	opts   *options
+}
+
+// NewWebshare returns a new Webshare with the API key of the account.
+func NewWebshare(apiKey string, opts ...Option) *Webshare {
+	return &Webshare{apiKey: apiKey, opts: newOptions(webshareBaseURL, opts)}
+}
+
+// Fetch returns the valid proxies of the list.
+func (w *Webshare) Fetch(ctx context.Context) ([]*proxym.Proxy, error) {
+	next, err := url.JoinPath(w.opts.baseURL, "proxy/list/")
+	if err != nil {
+		return nil, err
+	}
+	next += "?mode=direct&page=1&page_size=100"
+
+	header := http.Header{"Authorization": {"Token " + w.apiKey}}
+	var proxies []*proxym.Proxy
+	for next != "" {
+		var page webshareList
+		if err := w.opts.getJSON(ctx, next, header, &page); err != nil {
+			return nil, err
+		}
+		for _, p := range page.Results {
+			if !p.Valid {
+				continue
+			}
+			u := &url.URL{
+				Scheme: "http",
+				User:   url.UserPassword(p.Username, p.Password),
+				Host:   net.JoinHostPort(p.ProxyAddress, strconv.Itoa(p.Port)),
+			}
+			meta := proxym.NewProxyMetadata(p.CountryCode, proxym.ProxyPriorityLow, time.Time{})
+			proxies = append(proxies, proxym.NewProxy(u, meta))
+		}
+
+		next = ""
+		if page.Next != nil {
+			next = *page.Next
+		}
+	}
+	return proxies, nil
+}
@@ -1,15 +1,28 @@
 package proxym
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"maps"
 	"net/http"
 	"net/url"
+	"sort"
+	"strings"
 	"sync"
 	"time"
+	"unicode/utf8"
 )
 
 // ProxyPriority is a representation of a proxy priority in proxym.
 type ProxyPriority uint
 
+// directProxyID is the id of a direct connection.
+const directProxyID = "direct"
+
+// proxyIDLength is the length of the proxy id in bytes.
+const proxyIDLength = 8
+
 // Proxy priorities.
 const (
 	ProxyPriorityLow ProxyPriority = iota
@@ -22,24 +35,48 @@ const (
 // It has statistics and metadata can be useful for RotationStrategy and SelectStrategy.
 //
 // It can also be currently active or enabled/disabled.
+//
+// The active state is reference-counted, a proxy can be active for several users at once.
 type Proxy struct {
-	url        *url.URL
-	stats      *ProxyStats
-	meta       *ProxyMetadata
-	isActive   bool
-	isDisabled bool
-	mu         sync.RWMutex
+	id           string
+	url          *url.URL
+	stats        *ProxyStats
+	meta         *ProxyMetadata
+	implicitMeta bool
+	activeCount  int
+	inFlight     int
+	isDisabled   bool
+	disabledTill time.Time
+	cooldowns    uint
+	isDraining   bool
+	onDrained    func(*Proxy)
+	limiter      *RateLimiter
+	watchers     map[any]func(*Proxy)
+	// credentialsExpiresAt is the expiration time of the credentials, see SetCredentialsExpiresAt.
+	credentialsExpiresAt time.Time
+	// isQuarantined and probeSuccesses are the quarantine state, see Quarantine.
+	isQuarantined  bool
+	probeSuccesses uint
+	// clock is the clock of the manager, see setClock.
+	clock Clock
+	mu    sync.RWMutex
 }
 
 // NewProxy creates a new Proxy.
+//
+// If meta is nil, the proxy gets empty metadata which can be replaced by the default metadata
+// of the manager, see WithDefaultMetadata.
 func NewProxy(url *url.URL, meta *ProxyMetadata) *Proxy {
-	if meta == nil {
+	implicitMeta := meta == nil
+	if implicitMeta {
 		meta = &ProxyMetadata{}
 	}
 	return &Proxy{
-		url:   url,
-		meta:  meta,
-		stats: &ProxyStats{},
+		id:           proxyID(url),
+		url:          url,
+		meta:         meta,
+		implicitMeta: implicitMeta,
+		stats:        &ProxyStats{},
 	}
 }
 
@@ -70,6 +107,24 @@ func NewDirectConnection() *Proxy {
 	return NewProxy(nil, nil)
 }
 
+// ID returns the stable identifier of the proxy.
+//
+// It is derived from the normalized url the proxy was created with, see NormalizeProxyURL,
+// so it is the same across the process restarts and does not change when the credentials are refreshed.
+// The id of a direct connection is "direct".
+func (p *Proxy) ID() string {
+	return p.id
+}
+
+// proxyID returns the id of the proxy with the url, the credentials are hashed so the id does not reveal them.
+func proxyID(u *url.URL) string {
+	if u == nil {
+		return directProxyID
+	}
+	sum := sha256.Sum256([]byte(NormalizeProxyURL(u)))
+	return hex.EncodeToString(sum[:proxyIDLength])
+}
+
 // URL returns the proxy url.
 func (p *Proxy) URL() *url.URL {
 	p.mu.RLock()
@@ -86,46 +141,209 @@ func (p *Proxy) String() string {
 	return u.String()
 }
 
-// Disable marks the proxy as disabled.
+// Disable marks the proxy as disabled until it is enabled.
 func (p *Proxy) Disable() {
 	p.mu.Lock()
-	defer p.mu.Unlock()
 	p.isDisabled = true
+	p.disabledTill = time.Time{}
+	p.mu.Unlock()
+	p.notify()
 }
 
-// Enable marks the proxy as enabled.
+// DisableFor marks the proxy as disabled for the duration, after that the proxy is enabled again.
+//
+// The proxy is re-enabled lazily, that is, by the first IsDisabled call after the duration,
+// for example by selects.RemoveDisabledFilter on the next selection.
+func (p *Proxy) DisableFor(duration time.Duration) {
+	p.mu.Lock()
+	p.isDisabled = true
+	p.disabledTill = clockNow(p.clock).Add(duration)
+	p.mu.Unlock()
+	p.notify()
+}
+
+// DisableUntil marks the proxy as disabled until the time like DisableFor,
+// for example to apply the disabled status shared by another instance.
+// The zero time disables the proxy until it is enabled like Disable.
+func (p *Proxy) DisableUntil(till time.Time) {
+	p.mu.Lock()
+	p.isDisabled = true
+	p.disabledTill = till
+	p.mu.Unlock()
+	p.notify()
+}
+
+// Cooldown disables the proxy for the duration of the next consecutive cooldown by the policy
+// like DisableFor and returns the duration, so each repeated failure disables the proxy for longer.
+//
+// The consecutive cooldowns are reset by Enable, Recycle and the successful response passed to Update.
+func (p *Proxy) Cooldown(policy Cooldown) time.Duration {
+	p.mu.Lock()
+	p.cooldowns++
+	duration := policy.Duration(p.cooldowns)
+	p.isDisabled = true
+	p.disabledTill = clockNow(p.clock).Add(duration)
+	p.mu.Unlock()
+	p.notify()
+	return duration
+}
+
+// Cooldowns returns the number of the consecutive cooldowns of the proxy, see Cooldown.
+func (p *Proxy) Cooldowns() uint {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.cooldowns
+}
+
+// DisabledUntil returns the time after which the disabled proxy is enabled again,
+// it returns the zero time if the proxy is enabled or disabled until it is enabled.
+func (p *Proxy) DisabledUntil() time.Time {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if !p.isDisabled {
+		return time.Time{}
+	}
+	return p.disabledTill
+}
+
+// Enable marks the proxy as enabled and resets the consecutive cooldowns.
 func (p *Proxy) Enable() {
 	p.mu.Lock()
-	defer p.mu.Unlock()
 	p.isDisabled = false
+	p.disabledTill = time.Time{}
+	p.cooldowns = 0
+	p.mu.Unlock()
+	p.notify()
 }
 
 // IsDisabled returns true if the proxy is disabled.
+//
+// If the cooldown of the proxy is over, see DisableFor, the proxy is enabled again and it returns false.
 func (p *Proxy) IsDisabled() bool {
+	p.mu.RLock()
+	disabled, till, clock := p.isDisabled, p.disabledTill, p.clock
+	p.mu.RUnlock()
+	if !disabled || till.IsZero() || clockNow(clock).Before(till) {
+		return disabled
+	}
+	return p.cooldownOver(till)
+}
+
+// disabledFlag returns the disabled flag of the proxy as it is set, unlike IsDisabled the cooldown is not checked.
+func (p *Proxy) disabledFlag() bool {
 	p.mu.RLock()
 	defer p.mu.RUnlock()
 	return p.isDisabled
 }
 
-// activate marks the proxy as active.
+// cooldownOver enables the proxy whose cooldown until the time is over and returns if the proxy is still disabled,
+// that is, if it was disabled again meanwhile. Unlike Enable, the consecutive cooldowns are kept.
+func (p *Proxy) cooldownOver(till time.Time) bool {
+	p.mu.Lock()
+	if !p.isDisabled || !p.disabledTill.Equal(till) {
+		disabled := p.isDisabled
+		p.mu.Unlock()
+		return disabled
+	}
+	p.isDisabled = false
+	p.disabledTill = time.Time{}
+	p.mu.Unlock()
+	p.notify()
+	return false
+}
+
+// Drain marks the proxy as draining, it is used for the proxies being retired.
+//
+// A draining proxy is not selected for new requests, like a disabled one, but the in-flight requests are not affected.
+// Unlike Disable, it is reported separately by IsDraining.
+// When the number of in-flight requests reaches zero, onDrained is called once so it is safe to remove the proxy,
+// if there are no in-flight requests, it is called immediately. The onDrained may be nil.
+func (p *Proxy) Drain(onDrained func(*Proxy)) {
+	p.mu.Lock()
+	p.isDraining = true
+	p.onDrained = onDrained
+	idle := p.inFlight == 0
+	p.mu.Unlock()
+	p.notify()
+
+	if idle {
+		p.drained()
+	}
+}
+
+// IsDraining returns true if the proxy is draining.
+func (p *Proxy) IsDraining() bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.isDraining
+}
+
+// InFlight returns the number of in-flight requests through the proxy.
+func (p *Proxy) InFlight() int {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.inFlight
+}
+
+// acquire increments the number of in-flight requests through the proxy.
+func (p *Proxy) acquire() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.inFlight++
+}
+
+// release decrements the number of in-flight requests through the proxy.
+func (p *Proxy) release() {
+	p.mu.Lock()
+	if p.inFlight > 0 {
+		p.inFlight--
+	}
+	idle := p.inFlight == 0 && p.isDraining
+	p.mu.Unlock()
+
+	if idle {
+		p.drained()
+	}
+}
+
+// drained calls the onDrained callback once.
+func (p *Proxy) drained() {
+	p.mu.Lock()
+	onDrained := p.onDrained
+	p.onDrained = nil
+	p.mu.Unlock()
+
+	if onDrained != nil {
+		onDrained(p)
+	}
+}
+
+// activate adds an active reference to the proxy.
 func (p *Proxy) activate() {
 	p.mu.Lock()
 	defer p.mu.Unlock()
-	p.isActive = true
+	p.activeCount++
 }
 
-// deactivate marks the proxy as inactive.
+// deactivate removes an active reference from the proxy.
 func (p *Proxy) deactivate() {
 	p.mu.Lock()
 	defer p.mu.Unlock()
-	p.isActive = false
+	if p.activeCount > 0 {
+		p.activeCount--
+	}
 }
 
 // IsActive returns true if the proxy is active.
 func (p *Proxy) IsActive() bool {
+	return p.ActiveCount() > 0
+}
+
+// ActiveCount returns the number of active references to the proxy.
+func (p *Proxy) ActiveCount() int {
 	p.mu.RLock()
 	defer p.mu.RUnlock()
-	return p.isActive
+	return p.activeCount
 }
 
 // IsDirect returns true if proxy represents a direct connection.
@@ -135,9 +353,99 @@ func (p *Proxy) IsDirect() bool {
 	return p.url == nil
 }
 
+// Recycle resets all runtime state of the proxy while preserving the url and metadata.
+//
+// The statistics are cleared like ProxyStats.Reset, so the half-life of the decay is kept,
+// the proxy is marked as enabled and not quarantined,
+// after that the proxy behaves like a freshly created one.
+//
+// The active references are kept, as they are held by the pools that use the proxy as the last used,
+// ProxyManagerImpl.RecycleWhere releases them by clearing the proxy from the last used.
+func (p *Proxy) Recycle() {
+	p.mu.Lock()
+	p.stats.Reset()
+	p.isDisabled = false
+	p.disabledTill = time.Time{}
+	p.cooldowns = 0
+	p.isDraining = false
+	p.onDrained = nil
+	p.isQuarantined = false
+	p.probeSuccesses = 0
+	p.mu.Unlock()
+	p.notify()
+}
+
+// SetRateLimiter attaches the limiter of the requests through the proxy, nil removes it.
+//
+// The limiter is used by ProxyTransport, see WithRateLimitWait, and by selects.RemoveRateLimitedFilter.
+// The same limiter can be attached to several proxies to limit them together.
+func (p *Proxy) SetRateLimiter(limiter *RateLimiter) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.limiter = limiter
+}
+
+// RateLimiter returns the limiter of the requests through the proxy, it returns nil if the proxy is not limited.
+func (p *Proxy) RateLimiter() *RateLimiter {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.limiter
+}
+
+// watch registers the function called after the state of the proxy is changed
+// by Disable, DisableFor, Cooldown, Enable, Drain or Recycle and when its cooldown is over.
+// A new function with the same key replaces the previous one.
+func (p *Proxy) watch(key any, fn func(*Proxy)) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.watchers == nil {
+		p.watchers = make(map[any]func(*Proxy))
+	}
+	p.watchers[key] = fn
+}
+
+// unwatch removes the function registered by watch with the key.
+func (p *Proxy) unwatch(key any) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.watchers, key)
+}
+
+// notify calls the functions registered by watch.
+func (p *Proxy) notify() {
+	p.mu.RLock()
+	watchers := make([]func(*Proxy), 0, len(p.watchers))
+	for _, fn := range p.watchers {
+		watchers = append(watchers, fn)
+	}
+	p.mu.RUnlock()
+
+	for _, fn := range watchers {
+		fn(p)
+	}
+}
+
 // Update is shorthand for Proxy.Stats().Update(response, err).
+//
+// A successful response also resets the consecutive cooldowns of the proxy, see Cooldown.
 func (p *Proxy) Update(response *http.Response, err error) {
-	p.Stats().Update(response, err)
+	p.record(responseOutcome(response, err))
+}
+
+// record records the request with the status code and the outcome to the statistics of the proxy,
+// a success also resets the consecutive cooldowns.
+func (p *Proxy) record(status int, failed bool) {
+	p.Stats().record(status, failed)
+	if !failed {
+		p.resetCooldowns()
+	}
+}
+
+// resetCooldowns resets the consecutive cooldowns of the proxy after the successful request.
+func (p *Proxy) resetCooldowns() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.cooldowns = 0
 }
 
 // Stats returns the statistics of the proxy.
@@ -147,6 +455,25 @@ func (p *Proxy) Stats() *ProxyStats {
 	return p.stats
 }
 
+// applyDefaultMetadata sets a copy of the default metadata if the proxy was created without metadata.
+func (p *Proxy) applyDefaultMetadata(meta *ProxyMetadata) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.implicitMeta {
+		p.meta = meta.Clone()
+		p.implicitMeta = false
+	}
+}
+
+// setClock sets the clock of the ProxyManagerImpl to the proxy and its statistics,
+// so the cooldowns and the times of the statistics follow it, see WithClock.
+func (p *Proxy) setClock(clock Clock) {
+	p.mu.Lock()
+	p.clock = clock
+	p.mu.Unlock()
+	p.stats.setClock(clock)
+}
+
 // Metadata returns the metadata of the proxy.
 func (p *Proxy) Metadata() *ProxyMetadata {
 	p.mu.RLock()
@@ -159,10 +486,24 @@ type ProxyStats struct {
 	totalRequests uint
 	successCount  uint
 	errorCount    uint
+	consecErrors  uint
 	lastUsed      time.Time
+	firstUsed     time.Time
+	latency       time.Duration
+	latencyCount  uint
+	lastStatus    int
+	bytesSent     uint64
+	bytesReceived uint64
+	window        statsWindow
+	decay         statsDecay
+	probe         ProbeStats
+	clock         Clock
 	mu            sync.RWMutex
 }
 
+// latencyWeight is the weight of the new latency sample in the rolling average latency.
+const latencyWeight = 0.2
+
 // TotalRequests returns the total requests of the proxy.
 func (s *ProxyStats) TotalRequests() uint {
 	s.mu.RLock()
@@ -184,6 +525,27 @@ func (s *ProxyStats) ErrorCount() uint {
 	return s.errorCount
 }
 
+// ConsecutiveErrors returns the number of errors of the proxy since the last success.
+func (s *ProxyStats) ConsecutiveErrors() uint {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.consecErrors
+}
+
+// SuccessRate returns the ratio of successful requests to the total requests of the proxy.
+//
+// If the proxy has no requests, it returns 1.
+func (s *ProxyStats) SuccessRate() float64 {
+	return s.Snapshot().SuccessRate()
+}
+
+// FirstUsed returns the first used date of the proxy, it is used as the start of the warm-up.
+func (s *ProxyStats) FirstUsed() time.Time {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.firstUsed
+}
+
 // LastUsed returns the last used date of the proxy.
 func (s *ProxyStats) LastUsed() time.Time {
 	s.mu.RLock()
@@ -191,19 +553,259 @@ func (s *ProxyStats) LastUsed() time.Time {
 	return s.lastUsed
 }
 
-// Update updates the proxy statistics at the expense of *http.Response and response error.
+// BytesSent returns the number of bytes of the request bodies sent through the proxy.
+func (s *ProxyStats) BytesSent() uint64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.bytesSent
+}
+
+// BytesReceived returns the number of bytes of the response bodies received through the proxy.
+func (s *ProxyStats) BytesReceived() uint64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.bytesReceived
+}
+
+// BytesTransferred returns the number of bytes sent and received through the proxy.
+func (s *ProxyStats) BytesTransferred() uint64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.bytesSent + s.bytesReceived
+}
+
+// AddBytes adds the number of bytes sent and received through the proxy.
+//
+// ProxyTransport adds the bytes of the request body read by the base transport
+// and the bytes read from the response body.
+func (s *ProxyStats) AddBytes(sent, received uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.bytesSent += sent
+	s.bytesReceived += received
+}
+
+// LastStatusCode returns the status code of the last response through the proxy.
+//
+// If the last request failed without a response or the proxy has no requests, it returns 0.
+func (s *ProxyStats) LastStatusCode() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.lastStatus
+}
+
+// AverageLatency returns the rolling average latency of the proxy, the exponentially weighted moving average
+// of the observed latencies, so recent requests matter more.
+//
+// If the proxy has no observed latency, it returns 0.
+func (s *ProxyStats) AverageLatency() time.Duration {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.latency
+}
+
+// LatencySamples returns the number of observed latencies of the proxy.
+func (s *ProxyStats) LatencySamples() uint {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.latencyCount
+}
+
+// ObserveLatency adds the latency of the request to the rolling average latency of the proxy.
+//
+// ProxyTransport observes the time until the response headers of the successful requests.
+func (s *ProxyStats) ObserveLatency(latency time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.latencyCount == 0 {
+		s.latency = latency
+	} else {
+		s.latency += time.Duration(latencyWeight * float64(latency-s.latency))
+	}
+	s.latencyCount++
+}
+
+// Update updates the proxy statistics at the expense of *http.Response and response error,
+// any response without an error is a success, see DefaultSuccessPredicate.
 func (s *ProxyStats) Update(response *http.Response, err error) {
+	s.record(responseOutcome(response, err))
+}
+
+// responseOutcome returns the status code of the response, zero if there is no response,
+// and true if the request failed, that is, there is no response or there is an error.
+func responseOutcome(response *http.Response, err error) (int, bool) {
+	if response == nil {
+		return 0, true
+	}
+	return response.StatusCode, err != nil
+}
+
+// record records the request with the status code, zero if there is no response, and the outcome.
+func (s *ProxyStats) record(status int, failed bool) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	s.totalRequests++
 
-	if response != nil && err == nil {
+	s.lastStatus = status
+	if !failed {
 		s.successCount++
+		s.consecErrors = 0
 	} else {
 		s.errorCount++
+		s.consecErrors++
+	}
+
+	s.lastUsed = clockNow(s.clock)
+	if s.firstUsed.IsZero() {
+		s.firstUsed = s.lastUsed
 	}
+	s.window.record(s.lastUsed, failed)
+	s.decay.record(s.lastUsed, failed)
+}
+
+// Window returns the number of the requests and the errors of the proxy since the time,
+// so the old errors stop counting, for example stats.Window(time.Now().Add(-5 * time.Minute)).
+//
+// The requests are counted in 10 second buckets, so the window is rounded to them,
+// and are kept for 1 hour, so a longer window counts only the last hour.
+func (s *ProxyStats) Window(since time.Time) WindowStats {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.window.since(since)
+}
+
+// LastRequests returns the number of the requests and the errors of the proxy among its last n requests,
+// so the recent behavior is seen regardless of the traffic, for example stats.LastRequests(100).
+//
+// The outcomes of the last 1000 requests are kept, so a greater n counts only the last 1000 requests.
+func (s *ProxyStats) LastRequests(n uint) WindowStats {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.window.last(n)
+}
+
+// Snapshot returns a consistent copy of the proxy statistics.
+func (s *ProxyStats) Snapshot() StatsSnapshot {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return StatsSnapshot{
+		TotalRequests:     s.totalRequests,
+		SuccessCount:      s.successCount,
+		ErrorCount:        s.errorCount,
+		ConsecutiveErrors: s.consecErrors,
+		LastUsed:          s.lastUsed,
+		FirstUsed:         s.firstUsed,
+		AverageLatency:    s.latency,
+		LatencySamples:    s.latencyCount,
+		LastStatusCode:    s.lastStatus,
+		BytesSent:         s.bytesSent,
+		BytesReceived:     s.bytesReceived,
+	}
+}
+
+// Restore sets the proxy statistics from the snapshot.
+func (s *ProxyStats) Restore(snapshot StatsSnapshot) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.totalRequests = snapshot.TotalRequests
+	s.successCount = snapshot.SuccessCount
+	s.errorCount = snapshot.ErrorCount
+	s.consecErrors = snapshot.ConsecutiveErrors
+	s.lastUsed = snapshot.LastUsed
+	s.firstUsed = snapshot.FirstUsed
+	s.latency = snapshot.AverageLatency
+	s.latencyCount = snapshot.LatencySamples
+	s.lastStatus = snapshot.LastStatusCode
+	s.bytesSent = snapshot.BytesSent
+	s.bytesReceived = snapshot.BytesReceived
+}
+
+// setClock sets the clock of the times of the statistics, nil is the system time.
+func (s *ProxyStats) setClock(clock Clock) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.clock = clock
+}
+
+// Reset clears the proxy statistics as if the proxy had no requests, for example after the upstream issue is fixed,
+// so the rotation strategies such as rotations.ErrorThresholdRotation stop reacting to the old errors.
+//
+// The windowed and the decayed counts and the probe latencies are cleared too, the half-life is kept,
+// see SetHalfLife.
+func (s *ProxyStats) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.totalRequests = 0
+	s.successCount = 0
+	s.errorCount = 0
+	s.consecErrors = 0
+	s.lastUsed = time.Time{}
+	s.firstUsed = time.Time{}
+	s.latency = 0
+	s.latencyCount = 0
+	s.lastStatus = 0
+	s.bytesSent = 0
+	s.bytesReceived = 0
+	s.window = statsWindow{}
+	s.decay = statsDecay{halfLife: s.decay.halfLife}
+	s.probe = ProbeStats{}
+}
+
+// StatsSnapshot is a point-in-time copy of the proxy statistics.
+//
+// It can also be the sum of the statistics of several proxies.
+// ConsecutiveErrors is the number of errors since the last success.
+// AverageLatency is the rolling average latency of LatencySamples observed latencies.
+// LastStatusCode is the status code of the last response, 0 if there is none.
+type StatsSnapshot struct {
+	TotalRequests     uint
+	SuccessCount      uint
+	ErrorCount        uint
+	ConsecutiveErrors uint
+	LastUsed          time.Time
+	FirstUsed         time.Time
+	AverageLatency    time.Duration
+	LatencySamples    uint
+	LastStatusCode    int
+	BytesSent         uint64
+	BytesReceived     uint64
+}
+
+// SuccessRate returns the ratio of successful requests to the total requests.
+//
+// If there are no requests, it returns 1.
+func (s StatsSnapshot) SuccessRate() float64 {
+	if s.TotalRequests == 0 {
+		return 1
+	}
+	return float64(s.SuccessCount) / float64(s.TotalRequests)
+}
 
-	s.lastUsed = time.Now()
+// Add returns the sum of the snapshots, LastUsed and its LastStatusCode are the latest of the two
+// and FirstUsed is the earliest of the two,
+// ConsecutiveErrors is the greatest of the two and AverageLatency is the average weighted by LatencySamples.
+func (s StatsSnapshot) Add(other StatsSnapshot) StatsSnapshot {
+	if samples := s.LatencySamples + other.LatencySamples; samples != 0 {
+		s.AverageLatency = time.Duration(
+			(float64(s.AverageLatency)*float64(s.LatencySamples) +
+				float64(other.AverageLatency)*float64(other.LatencySamples)) / float64(samples),
+		)
+		s.LatencySamples = samples
+	}
+	s.TotalRequests += other.TotalRequests
+	s.SuccessCount += other.SuccessCount
+	s.ErrorCount += other.ErrorCount
+	s.BytesSent += other.BytesSent
+	s.BytesReceived += other.BytesReceived
+	s.ConsecutiveErrors = max(s.ConsecutiveErrors, other.ConsecutiveErrors)
+	if other.LastUsed.After(s.LastUsed) {
+		s.LastUsed = other.LastUsed
+		s.LastStatusCode = other.LastStatusCode
+	}
+	if !other.FirstUsed.IsZero() && (s.FirstUsed.IsZero() || other.FirstUsed.Before(s.FirstUsed)) {
+		s.FirstUsed = other.FirstUsed
+	}
+	return s
 }
 
 // ProxyMetadata is a representation of a proxy metadata in proxym.
@@ -211,6 +813,10 @@ type ProxyMetadata struct {
 	country   string
 	priority  ProxyPriority
 	expiresAt time.Time
+	weight    uint
+	timeout   time.Duration
+	headers   http.Header
+	tags      map[string]struct{}
 	mu        sync.RWMutex
 }
 
@@ -223,6 +829,21 @@ func NewProxyMetadata(country string, priority ProxyPriority, expiresAt time.Tim
 	}
 }
 
+// Clone returns a copy of the metadata.
+func (m *ProxyMetadata) Clone() *ProxyMetadata {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return &ProxyMetadata{
+		country:   m.country,
+		priority:  m.priority,
+		expiresAt: m.expiresAt,
+		weight:    m.weight,
+		timeout:   m.timeout,
+		headers:   m.headers.Clone(),
+		tags:      maps.Clone(m.tags),
+	}
+}
+
 // SetPriority sets the priority of the proxy.
 func (m *ProxyMetadata) SetPriority(priority ProxyPriority) {
 	m.mu.Lock()
@@ -237,6 +858,144 @@ func (m *ProxyMetadata) Priority() ProxyPriority {
 	return m.priority
 }
 
+// IsExpired returns true if the expiration date of the proxy is set and is not after now.
+func (m *ProxyMetadata) IsExpired(now time.Time) bool {
+	expiresAt := m.ExpiresAt()
+	return !expiresAt.IsZero() && !now.Before(expiresAt)
+}
+
+// SetWeight sets the weight of the proxy used by the weighted select strategies.
+func (m *ProxyMetadata) SetWeight(weight uint) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.weight = weight
+}
+
+// Weight returns the weight of the proxy used by the weighted select strategies.
+//
+// If the weight is not set, it returns 1.
+func (m *ProxyMetadata) Weight() uint {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if m.weight == 0 {
+		return 1
+	}
+	return m.weight
+}
+
+// SetTimeout sets the timeout of the requests through the proxy applied by ProxyTransport.
+//
+// It takes precedence over the timeout of the priority tier, see WithPriorityTimeouts.
+func (m *ProxyMetadata) SetTimeout(timeout time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.timeout = timeout
+}
+
+// Timeout returns the timeout of the requests through the proxy, zero means no timeout.
+func (m *ProxyMetadata) Timeout() time.Duration {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.timeout
+}
+
+// SetHeader sets the header injected into the requests through the proxy,
+// for example Proxy-Authorization with a token or a provider-specific routing header.
+//
+// The header is added to the CONNECT request for https targets and to the request itself for http targets,
+// so it never reaches the target server through a tunnel.
+// It returns an ErrInvalidHeaderName error if the name is not a valid header name.
+func (m *ProxyMetadata) SetHeader(name, value string) error {
+	if !validHeaderName(name) {
+		return fmt.Errorf("%w: %q", ErrInvalidHeaderName, name)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.headers == nil {
+		m.headers = make(http.Header)
+	}
+	m.headers.Set(name, value)
+	return nil
+}
+
+// DeleteHeader deletes the header injected into the requests through the proxy.
+func (m *ProxyMetadata) DeleteHeader(name string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.headers.Del(name)
+}
+
+// Headers returns the copied headers injected into the requests through the proxy.
+func (m *ProxyMetadata) Headers() http.Header {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.headers.Clone()
+}
+
+// AddTag adds the tags to the proxy, the tags segment the pools, for example "residential" or "provider-x".
+func (m *ProxyMetadata) AddTag(tags ...string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.tags == nil {
+		m.tags = make(map[string]struct{}, len(tags))
+	}
+	for _, tag := range tags {
+		m.tags[tag] = struct{}{}
+	}
+}
+
+// RemoveTag removes the tags from the proxy.
+func (m *ProxyMetadata) RemoveTag(tags ...string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, tag := range tags {
+		delete(m.tags, tag)
+	}
+}
+
+// HasTag returns true if the proxy has the tag.
+func (m *ProxyMetadata) HasTag(tag string) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	_, ok := m.tags[tag]
+	return ok
+}
+
+// Tags returns the sorted tags of the proxy.
+func (m *ProxyMetadata) Tags() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	tags := make([]string, 0, len(m.tags))
+	for tag := range m.tags {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	return tags
+}
+
+// validHeaderName returns true if the name is a valid header name token.
+func validHeaderName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, r := range name {
+		if r >= utf8.RuneSelf || !headerTokenChar(byte(r)) {
+			return false
+		}
+	}
+	return true
+}
+
+// headerTokenChar returns true if the byte is a token character by RFC 7230.
+func headerTokenChar(b byte) bool {
+	switch {
+	case 'a' <= b && b <= 'z', 'A' <= b && b <= 'Z', '0' <= b && b <= '9':
+		return true
+	default:
+		return strings.IndexByte("!#$%&'*+-.^_`|~", b) >= 0
+	}
+}
+
 // SetCountry sets the country of the proxy.
 func (m *ProxyMetadata) SetCountry(country string) {
 	m.mu.Lock()
@@ -0,0 +1,132 @@
+package proxym_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/nezbut/proxym"
+	"github.com/nezbut/proxym/rotations"
+	"github.com/nezbut/proxym/selects"
+)
+
+func TestProxyRecycle(t *testing.T) {
+	proxy := proxym.NewProxyStr("http://proxy:8080", nil)
+	pm := newManager([]*proxym.Proxy{proxy})
+	if _, err := pm.GetNextProxy("example.com"); err != nil {
+		t.Fatal(err)
+	}
+	use(proxy, true, false)
+	proxy.Disable()
+
+	// The manager still holds the proxy as the last used, so its active reference is kept.
+	proxy.Recycle()
+	if !proxy.IsActive() {
+		t.Fatal("got the last used proxy inactive after Recycle, want the reference of the manager kept")
+	}
+
+	if got := pm.RecycleWhere(func(p *proxym.Proxy) bool { return p == proxy }); got != 1 {
+		t.Fatalf("got %d recycled proxies, want 1", got)
+	}
+	fresh := proxym.NewProxyStr("http://proxy:8080", nil)
+	if got, want := proxy.Stats().Snapshot(), fresh.Stats().Snapshot(); got != want {
+		t.Errorf("got stats %+v, want %+v", got, want)
+	}
+	if proxy.IsDisabled() {
+		t.Error("got the recycled proxy disabled, want enabled")
+	}
+	if proxy.IsActive() {
+		t.Error("got the recycled proxy active, want its reference released by RecycleWhere")
+	}
+}
+
+func TestRecycleWhere(t *testing.T) {
+	proxies := newProxies(3)
+	pm := newManager(proxies)
+	used, err := pm.GetNextProxy("example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range proxies {
+		use(p, false)
+	}
+
+	if got := pm.RecycleWhere(func(p *proxym.Proxy) bool { return p != proxies[0] }); got != 2 {
+		t.Errorf("got %d recycled proxies, want 2", got)
+	}
+	if got := proxies[0].Stats().TotalRequests(); got != 1 {
+		t.Errorf("got %d requests of the kept proxy, want 1", got)
+	}
+	for _, p := range proxies[1:] {
+		if got := p.Stats().TotalRequests(); got != 0 {
+			t.Errorf("got %d requests of the recycled proxy %s, want 0", got, p)
+		}
+	}
+	if used != proxies[0] && pm.LastUsed() != nil {
+		t.Errorf("got the recycled proxy %s as the last used, want nil", pm.LastUsed())
+	}
+	if used.IsActive() != (used == proxies[0]) {
+		t.Errorf("got the last used proxy %s active %t, want %t", used, used.IsActive(), used == proxies[0])
+	}
+}
+
+func TestProxyDrain(t *testing.T) {
+	proxies := newProxyServers(t, 2)
+	draining, other := proxies[0], proxies[1]
+	pm := proxym.NewProxyManager(
+		proxym.WithProxies(draining),
+		proxym.WithRotationStrategy(rotations.RoundRobinRotation{}),
+		proxym.WithSelectStrategy(selects.NewFilteredSelectFactory(selects.NewRoundRobinSelect,
+			selects.RemoveDisabledFilter{}, selects.RemoveDrainingFilter{})),
+	)
+	base, err := proxym.CloneRoundTripperWithProxySelector(pm, http.DefaultTransport)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &http.Client{Transport: proxym.NewProxyTransport(pm, base)}
+	// get returns the response of the request through the draining proxy, the body is left open.
+	get := func() *http.Response {
+		req, err := http.NewRequestWithContext(proxym.ContextWithProxy(context.Background(), draining),
+			http.MethodGet, "http://example.com/", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+	first, second := get(), get()
+
+	drained := 0
+	draining.Drain(func(*proxym.Proxy) { drained++ })
+	if !draining.IsDraining() || draining.IsDisabled() {
+		t.Fatal("got the draining proxy not draining or disabled")
+	}
+	if err := pm.AddProxies(other); err != nil {
+		t.Fatal(err)
+	}
+	for range 3 {
+		if p, err := pm.GetNextProxy("example.com"); err != nil || p != other {
+			t.Fatalf("got %v, %v for a new request, want the proxy that is not draining", p, err)
+		}
+	}
+
+	first.Body.Close()
+	if drained != 0 {
+		t.Fatalf("got the drained callback with %d in-flight requests, want it at idle", draining.InFlight())
+	}
+	second.Body.Close()
+	second.Body.Close()
+	if drained != 1 {
+		t.Errorf("got the drained callback %d times at idle, want once", drained)
+	}
+
+	// An idle proxy is drained immediately.
+	idle := 0
+	other.Drain(func(*proxym.Proxy) { idle++ })
+	if idle != 1 {
+		t.Errorf("got the drained callback %d times for an idle proxy, want once", idle)
+	}
+}
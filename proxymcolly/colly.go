@@ -0,0 +1,144 @@
+package proxymcolly
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+
+	"github.com/gocolly/colly/v2"
+	"github.com/nezbut/proxym"
+)
+
+// releasedKeyPrefix is the prefix of the colly.Context keys that mark the requests whose proxy is released.
+const releasedKeyPrefix = "proxym.released."
+
+// NewProxyFunc returns the colly.ProxyFunc that selects the next available proxy of the manager
+// for every request, see proxym.GetProxySelector.
+//
+// The url of the proxy is stored in the request context under colly.ProxyURLKey,
+// so colly reports it as colly.Request.ProxyURL. The outcomes are not recorded, see Install.
+func NewProxyFunc(pm proxym.ProxyManager, opts ...proxym.ProxySelectorOption) colly.ProxyFunc {
+	selector := proxym.GetProxySelector(pm, opts...)
+	return func(req *http.Request) (*url.URL, error) {
+		u, err := selector(req)
+		if err != nil {
+			return nil, err
+		}
+		*req = *req.WithContext(context.WithValue(req.Context(), colly.ProxyURLKey, proxyURL(u)))
+		return u, nil
+	}
+}
+
+// Install sets the proxy function of the collector that checks out the next available proxy of the manager
+// for every request like proxym.ProxyManagerImpl.AcquireContext, and registers the OnResponse and OnError
+// callbacks that release the proxy with the outcome of the request, see proxym.ProxyLease.Release.
+// So the proxy statistics, the resource statistics and the disable policy work like with proxym.ProxyTransport,
+// and the request counts as an in-flight request through the proxy until its callbacks.
+//
+// The redirects and the retries of the transport use the proxy of the request without a new selection.
+// The outcomes are matched to the leases by colly.Request.ProxyURL and the host of the request,
+// the error responses count as failures, the responses as successes.
+//
+// Call this function in the application initialization, as this function is not thread-safe.
+func Install(c *colly.Collector, pm *proxym.ProxyManagerImpl) {
+	t := &tracker{pm: pm, leases: make(map[string][]trackedLease)}
+	c.SetProxyFunc(t.proxy)
+	c.OnResponse(func(r *colly.Response) {
+		t.release(r, nil)
+	})
+	c.OnError(t.release)
+}
+
+// trackedLease is a lease of a proxy checked out for a request to the host.
+type trackedLease struct {
+	host  string
+	lease *proxym.ProxyLease
+}
+
+// tracker keeps the leases of the in-flight requests by the proxy url.
+type tracker struct {
+	pm     *proxym.ProxyManagerImpl
+	leases map[string][]trackedLease
+	mu     sync.Mutex
+}
+
+// proxy checks out the proxy for the request, the proxy of the request context is reused.
+func (t *tracker) proxy(req *http.Request) (*url.URL, error) {
+	if proxy := proxym.ProxyFromContext(req.Context()); proxy != nil {
+		return proxy.URL(), nil
+	}
+
+	host := req.URL.Hostname()
+	lease, err := t.pm.AcquireContext(req.Context(), host)
+	if err != nil {
+		return nil, err
+	}
+	u := lease.Proxy().URL()
+	key := proxyURL(u)
+
+	t.mu.Lock()
+	t.leases[key] = append(t.leases[key], trackedLease{host: host, lease: lease})
+	t.mu.Unlock()
+
+	ctx := proxym.ContextWithProxy(req.Context(), lease.Proxy())
+	*req = *req.WithContext(context.WithValue(ctx, colly.ProxyURLKey, key))
+	return u, nil
+}
+
+// release releases the lease of the request with the outcome, only the first callback of the request has effect.
+func (t *tracker) release(r *colly.Response, result error) {
+	if r == nil || r.Request == nil {
+		return
+	}
+	if r.Ctx != nil {
+		key := releasedKeyPrefix + strconv.FormatUint(uint64(r.Request.ID), 10)
+		if r.Ctx.GetAny(key) != nil {
+			return
+		}
+		r.Ctx.Put(key, true)
+	}
+
+	var host string
+	if r.Request.URL != nil {
+		host = r.Request.URL.Hostname()
+	}
+	if lease := t.take(r.Request.ProxyURL, host); lease != nil {
+		lease.Release(result)
+	}
+}
+
+// take removes and returns the lease of the proxy url, preferably of the host, nil if there is none.
+func (t *tracker) take(key, host string) *proxym.ProxyLease {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	leases := t.leases[key]
+	if len(leases) == 0 {
+		return nil
+	}
+	i := 0
+	for j, tracked := range leases {
+		if tracked.host == host {
+			i = j
+			break
+		}
+	}
+	lease := leases[i].lease
+	leases = append(leases[:i], leases[i+1:]...)
+	if len(leases) == 0 {
+		delete(t.leases, key)
+	} else {
+		t.leases[key] = leases
+	}
+	return lease
+}
+
+// proxyURL returns the url of the proxy like colly stores it, empty for a direct connection.
+func proxyURL(u *url.URL) string {
+	if u == nil {
+		return ""
+	}
+	return u.String()
+}
@@ -0,0 +1,3 @@
+// Package proxymcolly plugs a proxym.ProxyManager into a gocolly collector as its proxy function
+// and feeds the outcomes of the colly callbacks back into the statistics of the selected proxies.
+package proxymcolly
@@ -0,0 +1,45 @@
+package proxymfasthttp
+
+import (
+	"context"
+	"net"
+	"time"
+
+	"github.com/nezbut/proxym"
+	"github.com/valyala/fasthttp"
+)
+
+// NewDialFunc returns the fasthttp.DialFunc that dials the addresses through the next available proxy
+// of the manager by the host of the address, see proxym.Dialer.
+//
+// fasthttp keeps the dialed connections alive, so the proxy is selected per connection, not per request,
+// set fasthttp.Client.MaxConnDuration to rotate the connections. The proxy is released with the success outcome
+// when fasthttp closes the connection, the failed dials are recorded as failures.
+func NewDialFunc(pm *proxym.ProxyManagerImpl, opts ...proxym.DialerOption) fasthttp.DialFunc {
+	dialer := proxym.NewDialer(pm, opts...)
+	return func(addr string) (net.Conn, error) {
+		return dialer.Dial("tcp", addr)
+	}
+}
+
+// NewDialFuncWithTimeout returns the fasthttp.DialFuncWithTimeout like NewDialFunc,
+// the timeout bounds the selection of the proxy and the dial, zero means no timeout.
+func NewDialFuncWithTimeout(pm *proxym.ProxyManagerImpl, opts ...proxym.DialerOption) fasthttp.DialFuncWithTimeout {
+	dialer := proxym.NewDialer(pm, opts...)
+	return func(addr string, timeout time.Duration) (net.Conn, error) {
+		if timeout <= 0 {
+			return dialer.Dial("tcp", addr)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		return dialer.DialContext(ctx, "tcp", addr)
+	}
+}
+
+// PatchClient sets the dial functions of the fasthttp.Client to NewDialFunc and NewDialFuncWithTimeout.
+//
+// Call this function in the application initialization, as this function is not thread-safe.
+func PatchClient(client *fasthttp.Client, pm *proxym.ProxyManagerImpl, opts ...proxym.DialerOption) {
+	client.Dial = NewDialFunc(pm, opts...)
+	client.DialTimeout = NewDialFuncWithTimeout(pm, opts...)
+}
@@ -0,0 +1,3 @@
+// Package proxymfasthttp provides the fasthttp dial functions that dial the connections
+// through the proxies of a proxym.ProxyManagerImpl, since fasthttp has no proxy function of the requests.
+package proxymfasthttp
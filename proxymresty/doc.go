@@ -0,0 +1,4 @@
+// Package proxymresty installs a proxym.ProxyManager into a go-resty client: the requests are sent
+// through the proxies of the manager, the failed requests are retried through the next proxy
+// and the responses are attributed to the proxies that served them.
+package proxymresty
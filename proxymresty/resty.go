@@ -0,0 +1,80 @@
+package proxymresty
+
+import (
+	"net/http"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/nezbut/proxym"
+)
+
+// Option is option for Install.
+type Option func(*config)
+
+// config is the configuration of Install.
+type config struct {
+	transportOpts []proxym.ProxyTransportOption
+	retry         bool
+	retryOpts     []proxym.RetryTransportOption
+}
+
+// WithTransportOptions sets the options of the proxym.ProxyTransport of the client.
+func WithTransportOptions(opts ...proxym.ProxyTransportOption) Option {
+	return func(c *config) {
+		c.transportOpts = append(c.transportOpts, opts...)
+	}
+}
+
+// WithRetry retries the failed requests through the next proxy with a proxym.RetryTransport,
+// by default 3 times on the 429, 502, 503 and 504 status codes and on the connection errors, see NewRetryTransport.
+//
+// The retries happen inside the transport, so every attempt rotates the failed proxy,
+// unlike the retries of resty.Client.SetRetryCount that can reuse it.
+func WithRetry(opts ...proxym.RetryTransportOption) Option {
+	return func(c *config) {
+		c.retry = true
+		c.retryOpts = append(c.retryOpts, opts...)
+	}
+}
+
+// Install installs the manager into the resty.Client: its transport is cloned with a proxym.ProxySelector
+// and wrapped in a proxym.ProxyTransport, see proxym.PatchClient, and in a proxym.RetryTransport with WithRetry.
+// The proxy that served a response is returned by Proxy.
+//
+// resty.Client.SetProxy and resty.Client.RemoveProxy do not work after the installation,
+// since the transport is no longer a http.Transport.
+//
+// Call this function in the application initialization, as this function is not thread-safe.
+// It returns a proxym.ErrUnsupportedRoundTripperImpl error if the transport of the client is not supported,
+// see proxym.CloneRoundTripperWithProxySelector.
+func Install(client *resty.Client, pm proxym.ProxyManager, opts ...Option) error {
+	cfg := &config{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	base := client.GetClient().Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	cloned, err := proxym.CloneRoundTripperWithProxySelector(pm, base)
+	if err != nil {
+		return err
+	}
+
+	var transport http.RoundTripper = proxym.NewProxyTransport(pm, cloned, cfg.transportOpts...)
+	if cfg.retry {
+		transport = proxym.NewRetryTransport(pm, transport, cfg.retryOpts...)
+	}
+	client.SetTransport(transport)
+	return nil
+}
+
+// Proxy returns the proxy that served the response, see proxym.ProxyFromContext.
+//
+// It returns nil if the request failed without a response or was not sent through the manager.
+func Proxy(resp *resty.Response) *proxym.Proxy {
+	if resp == nil || resp.RawResponse == nil || resp.RawResponse.Request == nil {
+		return nil
+	}
+	return proxym.ProxyFromContext(resp.RawResponse.Request.Context())
+}
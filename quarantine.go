@@ -0,0 +1,128 @@
+package proxym
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// QuarantinePolicy is a policy of the quarantine of the failing proxies, see WithQuarantine.
+//
+// A quarantined proxy is not selected for the regular requests, see selects.RemoveQuarantinedFilter,
+// instead every ProbeEvery-th selection probes one of the quarantined proxies. After Successes consecutive
+// successful probes the proxy returns to the full rotation, a failed probe starts the count over.
+type QuarantinePolicy struct {
+	// Threshold is the number of the consecutive errors that quarantines a proxy,
+	// if it is zero, the proxies are quarantined only by Proxy.Quarantine.
+	Threshold uint
+	// ProbeEvery is the interval of the probes in selections, for example 100 probes in 1 of 100 selections,
+	// if it is zero, the quarantined proxies are not probed.
+	ProbeEvery uint
+	// Successes is the number of the consecutive successful probes that releases the proxy, at least 1.
+	Successes uint
+}
+
+// WithQuarantine sets the QuarantinePolicy of the ProxyManagerImpl, so the failing proxies are quarantined
+// instead of being disabled and they are probed by the occasional selections until they recover.
+//
+// The results of the probes are recorded by ProxyTransport and ProxyLease.Release.
+// The select strategies must exclude the quarantined proxies, see selects.RemoveQuarantinedFilter,
+// selects.DefaultSelectStrategy does that.
+func WithQuarantine(policy QuarantinePolicy) ProxyManagerImplOption {
+	return func(pm *ProxyManagerImpl) {
+		pm.quarantine.policy = policy
+	}
+}
+
+// Quarantine puts the proxy into the quarantine, see QuarantinePolicy, and starts the count of the probes over.
+func (p *Proxy) Quarantine() {
+	p.mu.Lock()
+	p.isQuarantined = true
+	p.probeSuccesses = 0
+	p.mu.Unlock()
+	p.notify()
+}
+
+// Unquarantine returns the quarantined proxy to the full rotation.
+func (p *Proxy) Unquarantine() {
+	p.mu.Lock()
+	p.isQuarantined = false
+	p.probeSuccesses = 0
+	p.mu.Unlock()
+	p.notify()
+}
+
+// IsQuarantined returns true if the proxy is quarantined, see Quarantine.
+func (p *Proxy) IsQuarantined() bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.isQuarantined
+}
+
+// probed records the result of the probe of the quarantined proxy, the proxy is released
+// after the number of the consecutive successful probes. It does nothing if the proxy is not quarantined.
+func (p *Proxy) probed(failed bool, successes uint) {
+	p.mu.Lock()
+	if !p.isQuarantined {
+		p.mu.Unlock()
+		return
+	}
+	if failed {
+		p.probeSuccesses = 0
+		p.mu.Unlock()
+		return
+	}
+	p.probeSuccesses++
+	released := p.probeSuccesses >= max(successes, 1)
+	if released {
+		p.isQuarantined = false
+		p.probeSuccesses = 0
+	}
+	p.mu.Unlock()
+
+	if released {
+		p.notify()
+	}
+}
+
+// quarantine is the quarantine of the ProxyManagerImpl.
+type quarantine struct {
+	policy     QuarantinePolicy
+	selections atomic.Uint64
+}
+
+// observe quarantines the proxy if its consecutive errors reach the threshold
+// or records the result of the probe if the proxy is quarantined.
+func (q *quarantine) observe(proxy *Proxy, failed bool) {
+	if proxy.IsQuarantined() {
+		proxy.probed(failed, q.policy.Successes)
+		return
+	}
+	if failed && q.policy.Threshold != 0 && proxy.Stats().ConsecutiveErrors() >= q.policy.Threshold {
+		proxy.Quarantine()
+	}
+}
+
+// probe counts the selection and returns the quarantined proxy to probe on every ProbeEvery-th selection,
+// the quarantined proxies are probed in turn. It returns nil if the selection is not a probe
+// or there is no usable quarantined proxy.
+func (q *quarantine) probe(proxies []*Proxy, now time.Time) *Proxy {
+	every := uint64(q.policy.ProbeEvery)
+	if every == 0 {
+		return nil
+	}
+	n := q.selections.Add(1)
+	if n%every != 0 {
+		return nil
+	}
+
+	var candidates []*Proxy
+	for _, p := range proxies {
+		if p.IsQuarantined() && !p.IsDisabled() && !p.IsDraining() && !p.Metadata().IsExpired(now) {
+			candidates = append(candidates, p)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+	return candidates[(n/every)%uint64(len(candidates))]
+}
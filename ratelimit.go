@@ -0,0 +1,95 @@
+package proxym
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// RateLimiter is a token-bucket limiter of the requests through a proxy, see Proxy.SetRateLimiter.
+//
+// The bucket holds up to burst tokens and is refilled at the rate tokens per second,
+// each request takes one token.
+type RateLimiter struct {
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+	mu     sync.Mutex
+}
+
+// NewRateLimiter returns a new full RateLimiter with the rate in requests per second and the burst.
+//
+// If burst is less than 1, it is 1.
+func NewRateLimiter(rate float64, burst int) *RateLimiter {
+	b := float64(max(burst, 1))
+	return &RateLimiter{rate: rate, burst: b, tokens: b, last: time.Now()}
+}
+
+// Available returns true if the bucket has a token, the token is not taken.
+func (l *RateLimiter) Available() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.refill(time.Now())
+	return l.tokens >= 1
+}
+
+// Allow takes a token and returns true if the bucket has one, otherwise it returns false.
+func (l *RateLimiter) Allow() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.refill(time.Now())
+	if l.tokens < 1 {
+		return false
+	}
+	l.tokens--
+	return true
+}
+
+// Wait waits for a token and takes it.
+//
+// It returns the context error if the context is done first
+// and ErrProxyRateLimited if the bucket is empty and is never refilled, that is, the rate is not positive.
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	for {
+		delay, ok := l.reserve()
+		if ok {
+			return nil
+		}
+		if delay <= 0 {
+			return ErrProxyRateLimited
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// reserve takes a token and returns true if the bucket has one,
+// otherwise it returns the time until the next token.
+func (l *RateLimiter) reserve() (time.Duration, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.refill(time.Now())
+	if l.tokens >= 1 {
+		l.tokens--
+		return 0, true
+	}
+	if l.rate <= 0 {
+		return 0, false
+	}
+	return time.Duration((1 - l.tokens) / l.rate * float64(time.Second)), false
+}
+
+// refill adds the tokens for the time passed since the last refill.
+func (l *RateLimiter) refill(now time.Time) {
+	if elapsed := now.Sub(l.last); elapsed > 0 && l.rate > 0 {
+		l.tokens = min(l.burst, l.tokens+elapsed.Seconds()*l.rate)
+	}
+	l.last = now
+}
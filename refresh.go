@@ -0,0 +1,186 @@
+package proxym
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// defaultRefreshInterval is the default interval between the refreshes of the ProxyRefresher.
+const defaultRefreshInterval = 5 * time.Minute
+
+// ProxySource is a source of the proxy list, for example a remote list, see load.HTTPSource.
+type ProxySource interface {
+	// Fetch returns the current proxies of the source.
+	Fetch(ctx context.Context) ([]*Proxy, error)
+}
+
+// ProxySourceFunc is a function that implements ProxySource,
+// for example to fetch the proxies from a database or the API of a proxy vendor.
+type ProxySourceFunc func(ctx context.Context) ([]*Proxy, error)
+
+// Fetch calls the function.
+func (f ProxySourceFunc) Fetch(ctx context.Context) ([]*Proxy, error) {
+	return f(ctx)
+}
+
+// MergeSources returns the ProxySource that fetches the proxies from all sources in order and joins them.
+//
+// If any source fails, the merged source fails too, so the proxies of the failed source are not removed.
+func MergeSources(sources ...ProxySource) ProxySource {
+	return ProxySourceFunc(func(ctx context.Context) ([]*Proxy, error) {
+		var proxies []*Proxy
+		for _, source := range sources {
+			fetched, err := source.Fetch(ctx)
+			if err != nil {
+				return nil, err
+			}
+			proxies = append(proxies, fetched...)
+		}
+		return proxies, nil
+	})
+}
+
+// ProxyRefresher periodically fetches the proxies from the ProxySource
+// and syncs the global pool of the ProxyManagerImpl with them, see ProxyManagerImpl.SyncProxies,
+// so the unchanged proxies keep their statistics.
+//
+// If the source returns an error, the pool is not changed.
+type ProxyRefresher struct {
+	pm        *ProxyManagerImpl
+	source    ProxySource
+	interval  time.Duration
+	onRefresh func(added, removed int, err error)
+	cancel    context.CancelFunc
+	done      chan struct{}
+	mu        sync.Mutex
+}
+
+// ProxyRefresherOption is option for ProxyRefresher.
+type ProxyRefresherOption func(*ProxyRefresher)
+
+// WithRefreshInterval sets the interval between the refreshes, by default 5 minutes.
+// A non-positive interval is replaced by the default.
+func WithRefreshInterval(interval time.Duration) ProxyRefresherOption {
+	return func(r *ProxyRefresher) {
+		r.interval = interval
+	}
+}
+
+// WithOnRefresh sets the function called after each refresh with the number of added and removed proxies
+// or with the error of the source.
+func WithOnRefresh(onRefresh func(added, removed int, err error)) ProxyRefresherOption {
+	return func(r *ProxyRefresher) {
+		r.onRefresh = onRefresh
+	}
+}
+
+// NewProxyRefresher returns a new ProxyRefresher.
+func NewProxyRefresher(pm *ProxyManagerImpl, source ProxySource, opts ...ProxyRefresherOption) *ProxyRefresher {
+	r := &ProxyRefresher{
+		pm:       pm,
+		source:   source,
+		interval: defaultRefreshInterval,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	if r.interval <= 0 {
+		r.interval = defaultRefreshInterval
+	}
+	return r
+}
+
+// Start starts the periodic refreshes in the background, the first refresh runs immediately.
+//
+// The refreshes run until the context is canceled or Stop is called.
+// Calling Start on a started ProxyRefresher does nothing.
+func (r *ProxyRefresher) Start(ctx context.Context) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.cancel != nil {
+		return
+	}
+
+	ctx, r.cancel = context.WithCancel(ctx)
+	r.done = make(chan struct{})
+	go r.run(ctx, r.done)
+}
+
+// Stop stops the periodic refreshes and waits for the running refresh to finish.
+func (r *ProxyRefresher) Stop() {
+	r.mu.Lock()
+	cancel, done := r.cancel, r.done
+	r.cancel, r.done = nil, nil
+	r.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+		<-done
+	}
+}
+
+// Refresh fetches the proxies from the source once and syncs the global pool with them.
+// If the strict validation is enabled and any fetched proxy is invalid, the pool is not changed,
+// see WithStrictValidation.
+//
+// It returns the number of added and removed proxies.
+func (r *ProxyRefresher) Refresh(ctx context.Context) (int, int, error) {
+	proxies, err := r.source.Fetch(ctx)
+	if err != nil {
+		return 0, 0, err
+	}
+	if err := r.pm.ValidateProxies(proxies...); err != nil {
+		return 0, 0, err
+	}
+	added, removed := r.pm.SyncProxies(proxies...)
+	return added, removed, nil
+}
+
+// run runs the periodic refreshes until the context is canceled.
+func (r *ProxyRefresher) run(ctx context.Context, done chan struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		added, removed, err := r.Refresh(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		if r.onRefresh != nil {
+			r.onRefresh(added, removed, err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// StartRefresh starts the periodic refreshes of the global pool from the source set by WithProxySource,
+// see ProxyRefresher.Start. Without the source it does nothing.
+func (pm *ProxyManagerImpl) StartRefresh(ctx context.Context) {
+	if pm.refresher != nil {
+		pm.refresher.Start(ctx)
+	}
+}
+
+// StopRefresh stops the periodic refreshes started by StartRefresh, see ProxyRefresher.Stop.
+func (pm *ProxyManagerImpl) StopRefresh() {
+	if pm.refresher != nil {
+		pm.refresher.Stop()
+	}
+}
+
+// Refresh refreshes the global pool from the source set by WithProxySource once
+// and returns the number of added and removed proxies, see ProxyRefresher.Refresh.
+// Without the source it does nothing.
+func (pm *ProxyManagerImpl) Refresh(ctx context.Context) (int, int, error) {
+	if pm.refresher == nil {
+		return 0, 0, nil
+	}
+	return pm.refresher.Refresh(ctx)
+}
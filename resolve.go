@@ -0,0 +1,62 @@
+package proxym
+
+// matchKind is the kind of the match of a request to a ResourceConfig, the greater the more specific.
+type matchKind int
+
+// matchKind constants.
+const (
+	matchNone matchKind = iota
+	// matchAnyHost is the match of a ResourceConfig with only the matchers, the host is not compared.
+	matchAnyHost
+	// matchSubdomain is the match of a subdomain or of the registrable domain, see WithETLDPlusOne.
+	matchSubdomain
+	// matchPattern is the match of a domain pattern, see WithDomainPattern.
+	matchPattern
+	// matchExact is the match of the exact domain.
+	matchExact
+)
+
+// resourceMatch is how specifically a request matches a ResourceConfig.
+type resourceMatch struct {
+	kind matchKind
+	// length is the length of the matched domain or pattern.
+	length int
+	// matchers is the number of the matched matchers, see WithResourceMatcher.
+	matchers int
+}
+
+// moreSpecific returns true if the match is more specific than the other one.
+func (m resourceMatch) moreSpecific(other resourceMatch) bool {
+	if m.kind != other.kind {
+		return m.kind > other.kind
+	}
+	if m.length != other.length {
+		return m.length > other.length
+	}
+	return m.matchers > other.matchers
+}
+
+// resolveResource returns the index and the ResourceConfig that wins among the resources matched by match,
+// it returns nil if no resource matches.
+//
+// The resource with the highest match priority wins, see WithMatchPriority. Among the resources with equal priority
+// the most specific match wins: the exact domain, then the domain pattern, then the subdomain
+// and then the resource with only the matchers. Within the same kind the longer domain or pattern wins,
+// then the resource with more matchers. If the matches are equal, the resource added first wins.
+func resolveResource(resources []*ResourceConfig, match func(*ResourceConfig) resourceMatch) (int, *ResourceConfig) {
+	index := -1
+	var best *ResourceConfig
+	var bestMatch resourceMatch
+	var bestPriority int
+	for i, rc := range resources {
+		m := match(rc)
+		if m.kind == matchNone {
+			continue
+		}
+		priority := rc.MatchPriority()
+		if best == nil || priority > bestPriority || (priority == bestPriority && m.moreSpecific(bestMatch)) {
+			index, best, bestMatch, bestPriority = i, rc, m, priority
+		}
+	}
+	return index, best
+}
@@ -1,9 +1,16 @@
 package proxym
 
 import (
+	"net"
 	"net/url"
+	"regexp"
 	"strings"
 	"sync"
+	"time"
+	"unicode/utf8"
+
+	"golang.org/x/net/idna"
+	"golang.org/x/net/publicsuffix"
 )
 
 // ResourceConfig is a representation of a resource config in proxym.
@@ -12,9 +19,22 @@ import (
 type ResourceConfig struct {
 	proxies             []*Proxy
 	domain              string
+	patterns            []*regexp.Regexp
+	matchers            []ResourceMatcher
 	notIgnoreSubdomains bool
+	compareETLDPlusOne  bool
+	matchPriority       int
+	share               uint
+	fallbackToGlobal    bool
+	onAdd               func([]*Proxy)
+	onRemove            func([]*Proxy)
 	selectStrategy      SelectStrategy
 	rotationStrategy    RotationStrategy
+	counters            selectionCounters
+	stats               ProxyStats
+	proxyStats          map[*Proxy]*ProxyStats
+	bans                map[*Proxy]time.Time
+	clock               Clock
 	mu                  sync.RWMutex
 }
 
@@ -72,6 +92,23 @@ func (rc *ResourceConfig) Domain() string {
 	return rc.domain
 }
 
+// Share returns the fair share of the ResourceConfig, see WithFairShare.
+//
+// If the share is not set, it returns 1.
+func (rc *ResourceConfig) Share() uint {
+	rc.mu.RLock()
+	defer rc.mu.RUnlock()
+	if rc.share == 0 {
+		return 1
+	}
+	return rc.share
+}
+
+// SelectionCounters returns the selection counters of the ResourceConfig.
+func (rc *ResourceConfig) SelectionCounters() SelectionCounters {
+	return rc.counters.snapshot()
+}
+
 // GetProxies returns the copied list of proxies.
 func (rc *ResourceConfig) GetProxies() []*Proxy {
 	rc.mu.RLock()
@@ -86,44 +123,261 @@ func (rc *ResourceConfig) GetProxies() []*Proxy {
 // AddProxies adds proxies to the ResourceConfig.
 func (rc *ResourceConfig) AddProxies(proxies ...*Proxy) {
 	rc.mu.Lock()
-	defer rc.mu.Unlock()
 	rc.proxies = append(rc.proxies, proxies...)
+	onAdd := rc.onAdd
+	rc.mu.Unlock()
+
+	if onAdd != nil {
+		onAdd(proxies)
+	}
+}
+
+// RemoveProxies removes the proxies from the ResourceConfig and returns the number of removed proxies.
+func (rc *ResourceConfig) RemoveProxies(proxies ...*Proxy) int {
+	return rc.RemoveWhere(matchProxies(proxies))
+}
+
+// RemoveProxiesByURL removes the proxies with the urls from the ResourceConfig
+// and returns the number of removed proxies.
+func (rc *ResourceConfig) RemoveProxiesByURL(urls ...string) int {
+	return rc.RemoveWhere(matchProxyURLs(urls))
+}
+
+// RemoveWhere removes the proxies for which the predicate returns true from the ResourceConfig
+// and returns the number of removed proxies.
+func (rc *ResourceConfig) RemoveWhere(pred func(*Proxy) bool) int {
+	rc.mu.Lock()
+	var removed []*Proxy
+	rc.proxies, removed = partitionProxies(rc.proxies, pred)
+	for _, p := range removed {
+		delete(rc.proxyStats, p)
+		delete(rc.bans, p)
+	}
+	onRemove := rc.onRemove
+	rc.mu.Unlock()
+
+	if onRemove != nil && len(removed) != 0 {
+		onRemove(removed)
+	}
+	return len(removed)
+}
+
+// MatchPriority returns the match priority of the ResourceConfig, see WithMatchPriority.
+func (rc *ResourceConfig) MatchPriority() int {
+	rc.mu.RLock()
+	defer rc.mu.RUnlock()
+	return rc.matchPriority
+}
+
+// fallsBackToGlobal returns true if the ResourceConfig falls back to the global pool,
+// see WithResourceFallbackToGlobal.
+func (rc *ResourceConfig) fallsBackToGlobal() bool {
+	rc.mu.RLock()
+	defer rc.mu.RUnlock()
+	return rc.fallbackToGlobal
+}
+
+// setClock sets the clock of the ProxyManagerImpl to the ResourceConfig and its statistics,
+// so the bans and the times of the statistics follow it, see WithClock.
+func (rc *ResourceConfig) setClock(clock Clock) {
+	rc.mu.Lock()
+	rc.clock = clock
+	for _, stats := range rc.proxyStats {
+		stats.setClock(clock)
+	}
+	rc.mu.Unlock()
+	rc.stats.setClock(clock)
+}
+
+// setOnRemove sets the function called after proxies are removed from the ResourceConfig.
+func (rc *ResourceConfig) setOnRemove(fn func([]*Proxy)) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.onRemove = fn
+}
+
+// setOnAdd sets the function called after proxies are added to the ResourceConfig.
+func (rc *ResourceConfig) setOnAdd(fn func([]*Proxy)) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.onAdd = fn
+}
+
+// Match returns true if the request matches the ResourceConfig.
+//
+// The host of the request is compared by CompareDomain and the request must match all matchers
+// of the ResourceConfig, see WithResourceMatcher. If the ResourceConfig has only the matchers,
+// without the domain and the domain patterns, the host is not compared.
+func (rc *ResourceConfig) Match(info *RequestInfo) bool {
+	return rc.match(info).kind != matchNone
+}
+
+// match returns how specifically the request matches the ResourceConfig.
+func (rc *ResourceConfig) match(info *RequestInfo) resourceMatch {
+	rc.mu.RLock()
+	matchers := rc.matchers
+	anyHost := rc.domain == "" && len(rc.patterns) == 0 && len(matchers) != 0
+	rc.mu.RUnlock()
+
+	m := resourceMatch{kind: matchAnyHost}
+	if !anyHost {
+		if m = rc.compareDomain(info.Host); m.kind == matchNone {
+			return m
+		}
+	}
+	for _, matcher := range matchers {
+		if !matcher.Match(info) {
+			return resourceMatch{}
+		}
+	}
+	m.matchers = len(matchers)
+	return m
 }
 
 // CompareDomain compare domain.
 //
-// If notIgnoreSubdomains is false, then it will ignore subdomains in the comparison of the domain.
+// If notIgnoreSubdomains is false, then it will ignore subdomains in the comparison of the domain,
+// unless the domain of the ResourceConfig is a public suffix, like "co.uk", then only the exact domain matches.
+// With WithETLDPlusOne the domains are compared by the registrable domain.
 func (rc *ResourceConfig) CompareDomain(domain string) bool {
+	return rc.compareDomain(domain).kind != matchNone
+}
+
+// compareDomain returns how specifically the domain matches the ResourceConfig.
+func (rc *ResourceConfig) compareDomain(domain string) resourceMatch {
 	rcDomain := rc.Domain()
 	normalized := rc.normalizeDomain(domain)
 
-	if normalized == rcDomain {
-		return true
-	}
-
 	rc.mu.RLock()
 	defer rc.mu.RUnlock()
-	if !rc.notIgnoreSubdomains && strings.HasSuffix(normalized, "."+rcDomain) {
-		return true
+	hasDomain := rcDomain != "" || len(rc.patterns) == 0
+	if hasDomain && normalized == rcDomain {
+		return resourceMatch{kind: matchExact, length: len(rcDomain)}
+	}
+	for _, pattern := range rc.patterns {
+		if pattern.MatchString(normalized) {
+			return resourceMatch{kind: matchPattern, length: len(pattern.String())}
+		}
+	}
+	if !hasDomain {
+		return resourceMatch{}
+	}
+
+	if rc.compareETLDPlusOne {
+		if site, err := publicsuffix.EffectiveTLDPlusOne(normalized); err == nil && site == eTLDPlusOne(rcDomain) {
+			return resourceMatch{kind: matchSubdomain, length: len(rcDomain)}
+		}
+		return resourceMatch{}
+	}
+	if !rc.notIgnoreSubdomains && !isPublicSuffix(rcDomain) && strings.HasSuffix(normalized, "."+rcDomain) {
+		return resourceMatch{kind: matchSubdomain, length: len(rcDomain)}
 	}
 
-	return false
+	return resourceMatch{}
+}
+
+// isPublicSuffix returns true if the domain is a public suffix, like "com", "co.uk" or "github.io",
+// the subdomains of which belong to the different owners.
+//
+// The single-label domains that are not in the public suffix list, like "localhost", are not public suffixes.
+func isPublicSuffix(domain string) bool {
+	if net.ParseIP(domain) != nil {
+		return false
+	}
+	suffix, icann := publicsuffix.PublicSuffix(domain)
+	return suffix == domain && (icann || strings.Contains(domain, "."))
+}
+
+// eTLDPlusOne returns the registrable domain of the domain, that is, the public suffix and one more label,
+// for example "example.co.uk" for "api.example.co.uk". It returns the domain itself if it has no registrable domain.
+func eTLDPlusOne(domain string) string {
+	if site, err := publicsuffix.EffectiveTLDPlusOne(domain); err == nil {
+		return site
+	}
+	return domain
+}
+
+// globToRegexp returns the regular expression of the domain glob pattern.
+//
+// The "*" matches any sequence of characters within a label of the domain, the "?" matches one such character.
+// The internationalized labels without the wildcards are converted to punycode.
+func globToRegexp(pattern string) *regexp.Regexp {
+	labels := strings.Split(pattern, ".")
+	for i, label := range labels {
+		if !strings.ContainsAny(label, "*?") {
+			labels[i] = toASCIIDomain(label)
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString("^")
+	for _, r := range strings.ToLower(strings.Join(labels, ".")) {
+		switch r {
+		case '*':
+			b.WriteString(`[^.]*`)
+		case '?':
+			b.WriteString(`[^.]`)
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	b.WriteString("$")
+	return regexp.MustCompile(b.String())
 }
 
 // normalizeDomain normalizes domain.
+//
+// The internationalized domains are converted to punycode, so münchen.de and xn--mnchen-3ya.de are equal.
 func (rc *ResourceConfig) normalizeDomain(domain string) string {
 	if domain == "" {
 		return ""
 	}
-	return strings.ToLower(rc.getDomainFromURL(domain))
+	return toASCIIDomain(rc.getDomainFromURL(domain))
+}
+
+// toASCIIDomain returns the lowercase domain with the internationalized labels converted to punycode.
+//
+// If the domain can not be converted, it is returned in lowercase as is.
+func toASCIIDomain(domain string) string {
+	if !isASCII(domain) {
+		if ascii, err := idna.Lookup.ToASCII(domain); err == nil {
+			return ascii
+		}
+	}
+	return strings.ToLower(domain)
+}
+
+// isASCII returns true if the string has only ASCII characters.
+func isASCII(s string) bool {
+	for i := range len(s) {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
 }
 
 // getDomainFromURL gets domain from url.
+//
+// The domain has url.URL.Hostname semantics, like the domain passed by GetProxySelector:
+// the port is stripped and the brackets of IPv6 literals are stripped,
+// so [2001:db8::1]:8443, https://example.com:8080/path and example.com:8080 are supported.
 func (rc *ResourceConfig) getDomainFromURL(urlStr string) string {
-	u, err := url.Parse(urlStr)
+	trimmed := strings.TrimSpace(urlStr)
+	if ip := net.ParseIP(strings.Trim(trimmed, "[]")); ip != nil {
+		return ip.String()
+	}
+	if !strings.Contains(trimmed, "://") {
+		trimmed = "//" + trimmed
+	}
+
+	u, err := url.Parse(trimmed)
 	if err != nil || u.Hostname() == "" {
 		return rc.trimDomain(urlStr)
 	}
+	if ip := net.ParseIP(u.Hostname()); ip != nil {
+		return ip.String()
+	}
 	return rc.trimDomain(u.Hostname())
 }
 
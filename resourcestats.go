@@ -0,0 +1,90 @@
+package proxym
+
+// resultRecorder is implemented by the managers that record the results of the requests by domain.
+//
+// ProxyTransport calls recordResult after the response of the request is received.
+type resultRecorder interface {
+	recordResult(info *RequestInfo, proxy *Proxy, status int, failed bool)
+}
+
+// recordResult records the result of the request through the proxy to the statistics of the resource of the request.
+//
+// The requests without a resource are not recorded, the OnResult callbacks are called
+// and the QuarantinePolicy and the DisablePolicy are applied for all requests.
+func (pm *ProxyManagerImpl) recordResult(info *RequestInfo, proxy *Proxy, status int, failed bool) {
+	pm.events.onResult.emit(ResultEvent{Domain: info.Host, Proxy: proxy, StatusCode: status, Failed: failed})
+	pm.quarantine.observe(proxy, failed)
+	if failed {
+		pm.disablePolicy.Apply(proxy)
+	}
+	resource, err := pm.getResource(info)
+	if err != nil {
+		return
+	}
+	resource.recordResult(proxy, status, failed)
+}
+
+// Stats returns the statistics of the requests to the domains of the ResourceConfig,
+// that is, the totals of the requests, the successes and the errors through all its proxies.
+//
+// The requests are recorded by ProxyTransport and ProxyLease.Release of the ProxyManagerImpl with the ResourceConfig,
+// only the request counters and the last used times are set, the latency and the bytes are not tracked.
+func (rc *ResourceConfig) Stats() *ProxyStats {
+	return &rc.stats
+}
+
+// StatsByProxy returns the statistics of the requests to the domains of the ResourceConfig by proxy,
+// so it can be seen which proxies were used for the resource and with what outcome.
+//
+// The statistics of a proxy are dropped when it is removed from the ResourceConfig.
+func (rc *ResourceConfig) StatsByProxy() map[*Proxy]StatsSnapshot {
+	rc.mu.RLock()
+	defer rc.mu.RUnlock()
+
+	stats := make(map[*Proxy]StatsSnapshot, len(rc.proxyStats))
+	for p, s := range rc.proxyStats {
+		stats[p] = s.Snapshot()
+	}
+	return stats
+}
+
+// recordResult records the result of the request through the proxy.
+func (rc *ResourceConfig) recordResult(proxy *Proxy, status int, failed bool) {
+	rc.stats.record(status, failed)
+
+	rc.mu.Lock()
+	if rc.proxyStats == nil {
+		rc.proxyStats = make(map[*Proxy]*ProxyStats)
+	}
+	stats, ok := rc.proxyStats[proxy]
+	if !ok {
+		stats = &ProxyStats{clock: rc.clock}
+		rc.proxyStats[proxy] = stats
+	}
+	rc.mu.Unlock()
+
+	stats.record(status, failed)
+}
+
+// ResetStats clears the statistics of the requests to the domains of the ResourceConfig,
+// the totals and the statistics by proxy, see ProxyStats.Reset.
+func (rc *ResourceConfig) ResetStats() {
+	rc.stats.Reset()
+
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.proxyStats = nil
+}
+
+// ResetStats clears the statistics of the proxies of the full fleet and of the resources, see ProxyStats.Reset,
+// so the operators can start over after fixing an upstream issue instead of recreating the ProxyManagerImpl.
+//
+// The selection counters are not cleared, see SelectionCounters.
+func (pm *ProxyManagerImpl) ResetStats() {
+	for _, p := range pm.fleet() {
+		p.Stats().Reset()
+	}
+	for _, rc := range pm.Resources() {
+		rc.ResetStats()
+	}
+}
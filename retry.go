@@ -0,0 +1,170 @@
+package proxym
+
+import (
+	"io"
+	"net/http"
+)
+
+// Default settings of the RetryTransport.
+const (
+	defaultMaxRetries = 3
+	// maxDiscardBytes is the maximum number of bytes of the failed response body read to reuse the connection.
+	maxDiscardBytes = 2 << 10
+)
+
+// defaultRetryStatusCodes are the response status codes retried by default.
+var defaultRetryStatusCodes = []int{
+	http.StatusTooManyRequests,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// proxyRotator is implemented by the managers that can rotate the failed proxy.
+type proxyRotator interface {
+	rotate(proxy *Proxy)
+}
+
+// RetryTransport is http.RoundTripper that retries the failed request through the next proxy.
+//
+// For each attempt it selects the proxy and stores it in the request context (see ContextWithProxy),
+// so the base transport must use the proxy from the context, like ProxyTransport and GetProxySelector do.
+// The proxy statistics are updated by the base transport, for example:
+//
+//	transport := proxym.NewRetryTransport(pm, proxym.NewProxyTransport(pm, baseTransport))
+//
+// A request fails when the base transport returns a connection error or a response with a retry status code.
+// The failed proxy is marked, the manager rotates it on the next selection, and the request is retried
+// through the next proxy up to max retries times, then the last response or error is returned.
+// The proxies already tried for the request are not selected again while other candidates remain.
+//
+// The request is not retried if its body can not be replayed, that is, http.Request.GetBody is nil,
+// or if the request context is done.
+type RetryTransport struct {
+	pm            ProxyManager
+	baseTransport http.RoundTripper
+	maxRetries    int
+	statusCodes   map[int]struct{}
+}
+
+// NewRetryTransport returns a new RetryTransport.
+//
+// By default, the request is retried 3 times on the 429, 502, 503 and 504 status codes.
+func NewRetryTransport(pm ProxyManager, baseTransport http.RoundTripper, opts ...RetryTransportOption) *RetryTransport {
+	rt := &RetryTransport{pm: pm, baseTransport: baseTransport, maxRetries: defaultMaxRetries}
+	WithRetryStatusCodes(defaultRetryStatusCodes...)(rt)
+	for _, opt := range opts {
+		opt(rt)
+	}
+	return rt
+}
+
+// RoundTrip sends the request through the next proxy and retries it through the next proxy on failure.
+//
+// If the request context already has a proxy (see ContextWithProxy), it is used for the first attempt.
+func (rt *RetryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	proxy := ProxyFromContext(req.Context())
+	if proxy == nil {
+		var err error
+		if proxy, _, err = nextProxy(rt.pm, req); err != nil {
+			return nil, err
+		}
+	}
+
+	tried := make(map[*Proxy]struct{}, rt.maxRetries+1)
+	for attempt := 0; ; attempt++ {
+		tried[proxy] = struct{}{}
+		resp, err := rt.baseTransport.RoundTrip(req.WithContext(ContextWithProxy(req.Context(), proxy)))
+		if !rt.shouldRetry(req, resp, err) || attempt >= rt.maxRetries {
+			return resp, err
+		}
+
+		rt.markFailed(proxy)
+		next, nextErr := rt.nextUntried(req, tried)
+		if nextErr != nil {
+			return resp, err
+		}
+		rewound, rewindErr := rewindBody(req)
+		if rewindErr != nil {
+			return resp, err
+		}
+		discardBody(resp)
+		req = rewound
+		proxy = next
+	}
+}
+
+// nextUntried selects the next proxy for the request that has not been tried.
+//
+// The selection is repeated while it returns a tried proxy, at most once per candidate of the request,
+// then the tried proxy is returned, so the tried proxies are reused only when no other candidate is selected.
+func (rt *RetryTransport) nextUntried(req *http.Request, tried map[*Proxy]struct{}) (*Proxy, error) {
+	var proxy *Proxy
+	for range max(rt.candidates(req), 1) {
+		var err error
+		if proxy, _, err = nextProxy(rt.pm, req); err != nil {
+			return nil, err
+		}
+		if _, ok := tried[proxy]; !ok {
+			return proxy, nil
+		}
+		rt.markFailed(proxy)
+	}
+	return proxy, nil
+}
+
+// candidates returns the number of the proxies the request is selected from,
+// that is, the proxies of its resource or of the global pool.
+func (rt *RetryTransport) candidates(req *http.Request) int {
+	if impl, ok := rt.pm.(*ProxyManagerImpl); ok {
+		if resource, err := impl.getResource(NewRequestInfo(req)); err == nil && !impl.fallsBack(resource) {
+			return len(resource.GetProxies())
+		}
+	}
+	return len(rt.pm.GetProxies())
+}
+
+// shouldRetry returns true if the request failed and can be retried.
+func (rt *RetryTransport) shouldRetry(req *http.Request, resp *http.Response, err error) bool {
+	if req.Context().Err() != nil {
+		return false
+	}
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false
+	}
+	if err != nil {
+		return true
+	}
+	_, ok := rt.statusCodes[resp.StatusCode]
+	return ok
+}
+
+// markFailed marks the proxy as failed, so the manager rotates it on the next selection.
+func (rt *RetryTransport) markFailed(proxy *Proxy) {
+	if rotator, ok := rt.pm.(proxyRotator); ok {
+		rotator.rotate(proxy)
+	}
+}
+
+// rewindBody returns the request with the new body from http.Request.GetBody.
+func rewindBody(req *http.Request) (*http.Request, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return req, nil
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, err
+	}
+	req = req.Clone(req.Context())
+	req.Body = body
+	return req, nil
+}
+
+// discardBody reads and closes the body of the response, so the connection can be reused.
+func discardBody(resp *http.Response) {
+	if resp == nil || resp.Body == nil {
+		return
+	}
+	_, _ = io.CopyN(io.Discard, resp.Body, maxDiscardBytes)
+	_ = resp.Body.Close()
+}
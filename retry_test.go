@@ -0,0 +1,66 @@
+package proxym_test
+
+import (
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/nezbut/proxym"
+	"github.com/nezbut/proxym/rotations"
+)
+
+// sequenceSelect is a proxym.SelectStrategy that selects the proxies of the provider by the indexes in a cycle.
+type sequenceSelect struct {
+	provider proxym.SelectStrategyProxyProvider
+	indexes  []int
+	next     int
+	mu       sync.Mutex
+}
+
+func (s *sequenceSelect) Select() (*proxym.Proxy, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := s.indexes[s.next%len(s.indexes)]
+	s.next++
+	return s.provider.GetProxies()[i], nil
+}
+
+func TestRetryTransportSkipsTriedProxies(t *testing.T) {
+	proxies := newProxies(3)
+	pm := proxym.NewProxyManager(
+		proxym.WithProxies(proxies...),
+		proxym.WithRotationStrategy(rotations.RoundRobinRotation{}),
+		proxym.WithSelectStrategy(func(provider proxym.SelectStrategyProxyProvider) proxym.SelectStrategy {
+			// The strategy selects the tried proxies again before the third one.
+			return &sequenceSelect{provider: provider, indexes: []int{0, 1, 0, 1, 2}}
+		}),
+	)
+
+	var tried []*proxym.Proxy
+	base := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		tried = append(tried, proxym.ProxyFromContext(req.Context()))
+		return &http.Response{StatusCode: http.StatusBadGateway, Body: http.NoBody, Request: req}, nil
+	})
+	rt := proxym.NewRetryTransport(pm, base, proxym.WithMaxRetries(3))
+
+	req, err := http.NewRequest(http.MethodGet, "http://example.com/", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := rt.RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	// Three distinct proxies are tried first, then the tried proxies are reused as no other candidate remains.
+	want := []*proxym.Proxy{proxies[0], proxies[1], proxies[2]}
+	if len(tried) != 4 {
+		t.Fatalf("got %d attempts, want 4", len(tried))
+	}
+	for i, p := range want {
+		if tried[i] != p {
+			t.Errorf("attempt %d: got %s, want %s", i, tried[i].URL(), p.URL())
+		}
+	}
+}
@@ -1,8 +1,30 @@
 package proxym
 
+import "context"
+
 // RotationStrategy is an interface for proxy rotation strategies.
 // It is used to determine if a proxy should be rotated.
 type RotationStrategy interface {
 	// ShouldRotate returns true if the proxy should be rotated.
 	ShouldRotate(proxy *Proxy) bool
 }
+
+// ContextRotationStrategy is a RotationStrategy that receives the context of the selection.
+//
+// ProxyManagerImpl.GetNextProxyContext calls ShouldRotateContext if the strategy implements it.
+type ContextRotationStrategy interface {
+	RotationStrategy
+	// ShouldRotateContext returns true if the proxy should be rotated like ShouldRotate.
+	ShouldRotateContext(ctx context.Context, proxy *Proxy) bool
+}
+
+// ShouldRotateContext returns true if the proxy should be rotated by the strategy.
+//
+// If the strategy implements ContextRotationStrategy, ShouldRotateContext of it is called,
+// otherwise ShouldRotate is called.
+func ShouldRotateContext(ctx context.Context, strategy RotationStrategy, proxy *Proxy) bool {
+	if s, ok := strategy.(ContextRotationStrategy); ok {
+		return s.ShouldRotateContext(ctx, proxy)
+	}
+	return strategy.ShouldRotate(proxy)
+}
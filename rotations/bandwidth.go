@@ -0,0 +1,22 @@
+package rotations
+
+import "github.com/nezbut/proxym"
+
+// BandwidthQuotaRotation is a rotation strategy that returns true
+// if the bytes transferred through the proxy are greater than or equal to a quota,
+// matching the providers that meter the traffic, for example per GB.
+//
+// The bytes are counted by ProxyTransport, see proxym.ProxyStats.BytesTransferred.
+type BandwidthQuotaRotation struct {
+	quota uint64
+}
+
+// NewBandwidthQuotaRotation returns a new BandwidthQuotaRotation with the quota in bytes.
+func NewBandwidthQuotaRotation(quota uint64) proxym.RotationStrategy {
+	return &BandwidthQuotaRotation{quota: quota}
+}
+
+// ShouldRotate returns true if the proxy has transferred the quota.
+func (r *BandwidthQuotaRotation) ShouldRotate(proxy *proxym.Proxy) bool {
+	return proxy.Stats().BytesTransferred() >= r.quota
+}
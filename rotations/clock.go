@@ -0,0 +1,29 @@
+package rotations
+
+import "github.com/nezbut/proxym"
+
+// ClockOption is option for the time-based rotation strategies.
+type ClockOption func(*clocked)
+
+// WithClock sets the clock used by the time-based rotation strategy.
+//
+// By default proxym.SystemClock is used.
+func WithClock(clock proxym.Clock) ClockOption {
+	return func(c *clocked) {
+		c.clock = clock
+	}
+}
+
+// clocked is embedded into the time-based rotation strategies.
+type clocked struct {
+	clock proxym.Clock
+}
+
+// newClocked returns a clocked with the applied options.
+func newClocked(opts []ClockOption) clocked {
+	c := clocked{clock: proxym.SystemClock{}}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	return c
+}
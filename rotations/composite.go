@@ -1,6 +1,10 @@
 package rotations
 
-import "github.com/nezbut/proxym"
+import (
+	"context"
+
+	"github.com/nezbut/proxym"
+)
 
 // CompositeRotationLogicType is a type for composite rotation logic.
 type CompositeRotationLogicType int
@@ -34,12 +38,18 @@ func NewCompositeRotationStrategy(
 
 // ShouldRotate returns true if the proxy should be rotated.
 func (c *CompositeRotation) ShouldRotate(proxy *proxym.Proxy) bool {
+	return c.ShouldRotateContext(context.Background(), proxy)
+}
+
+// ShouldRotateContext returns true if the proxy should be rotated,
+// the context is passed to the strategies that implement proxym.ContextRotationStrategy.
+func (c *CompositeRotation) ShouldRotateContext(ctx context.Context, proxy *proxym.Proxy) bool {
 	if len(c.strategies) == 0 {
 		return false
 	}
 
 	for _, strategy := range c.strategies {
-		result := strategy.ShouldRotate(proxy)
+		result := proxym.ShouldRotateContext(ctx, strategy, proxy)
 
 		if c.logic == RotationLogicOR && result {
 			return true
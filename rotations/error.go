@@ -17,3 +17,23 @@ func NewErrorThresholdRotation(threshold uint) proxym.RotationStrategy {
 func (e *ErrorThresholdRotation) ShouldRotate(proxy *proxym.Proxy) bool {
 	return proxy.Stats().ErrorCount() >= e.threshold
 }
+
+// DecayedErrorThresholdRotation is a rotation strategy that returns true
+// if the decayed errors of the proxy are greater than or equal to a threshold.
+//
+// Unlike ErrorThresholdRotation the errors decay over time by the half-life of the proxy statistics,
+// see proxym.ProxyStats.Decayed and proxym.WithStatsHalfLife, so a proxy that misbehaved long ago
+// is not rotated forever.
+type DecayedErrorThresholdRotation struct {
+	threshold float64
+}
+
+// NewDecayedErrorThresholdRotation returns a new DecayedErrorThresholdRotation.
+func NewDecayedErrorThresholdRotation(threshold float64) proxym.RotationStrategy {
+	return &DecayedErrorThresholdRotation{threshold: threshold}
+}
+
+// ShouldRotate returns true if the decayed errors of the proxy reach the threshold.
+func (e *DecayedErrorThresholdRotation) ShouldRotate(proxy *proxym.Proxy) bool {
+	return proxy.Stats().Decayed().Errors >= e.threshold
+}
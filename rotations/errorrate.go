@@ -0,0 +1,69 @@
+package rotations
+
+import (
+	"time"
+
+	"github.com/nezbut/proxym"
+)
+
+// ErrorRateRotation is a rotation strategy that returns true if the error rate of the proxy
+// in the recent time window is greater than the threshold, for example more than 30% errors in the last 5 minutes.
+//
+// Unlike ErrorThresholdRotation it uses the windowed counters of the proxy statistics, see proxym.ProxyStats.Window,
+// so a single old error does not keep causing the rotation forever.
+// The rate is not checked until the proxy has at least minRequests requests in the window.
+type ErrorRateRotation struct {
+	clocked
+	threshold   float64
+	window      time.Duration
+	minRequests uint
+}
+
+// NewErrorRateRotation returns a new ErrorRateRotation with the threshold of the error rate from 0 to 1.
+func NewErrorRateRotation(
+	threshold float64,
+	window time.Duration,
+	minRequests uint,
+	opts ...ClockOption,
+) proxym.RotationStrategy {
+	return &ErrorRateRotation{
+		clocked:     newClocked(opts),
+		threshold:   threshold,
+		window:      window,
+		minRequests: minRequests,
+	}
+}
+
+// ShouldRotate returns true if the error rate of the proxy in the window is greater than the threshold.
+func (r *ErrorRateRotation) ShouldRotate(proxy *proxym.Proxy) bool {
+	stats := proxy.Stats().Window(r.clock.Now().Add(-r.window))
+	return stats.Requests >= r.minRequests && stats.ErrorRate() > r.threshold
+}
+
+// RecentErrorRateRotation is a rotation strategy that returns true if the error rate of the last requests
+// of the proxy is greater than the threshold, for example more than 30% errors in the last 100 requests.
+//
+// Unlike ErrorRateRotation the window is the number of the requests, see proxym.ProxyStats.LastRequests,
+// so the proxies with little traffic are judged by as many requests as the busy ones.
+// The rate is not checked until the proxy has at least minRequests requests in the window.
+type RecentErrorRateRotation struct {
+	threshold   float64
+	requests    uint
+	minRequests uint
+}
+
+// NewRecentErrorRateRotation returns a new RecentErrorRateRotation with the threshold of the error rate from 0 to 1
+// of the last requests.
+func NewRecentErrorRateRotation(threshold float64, requests, minRequests uint) proxym.RotationStrategy {
+	return &RecentErrorRateRotation{
+		threshold:   threshold,
+		requests:    requests,
+		minRequests: minRequests,
+	}
+}
+
+// ShouldRotate returns true if the error rate of the last requests of the proxy is greater than the threshold.
+func (r *RecentErrorRateRotation) ShouldRotate(proxy *proxym.Proxy) bool {
+	stats := proxy.Stats().LastRequests(r.requests)
+	return stats.Requests >= r.minRequests && stats.ErrorRate() > r.threshold
+}
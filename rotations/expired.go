@@ -0,0 +1,21 @@
+package rotations
+
+import "github.com/nezbut/proxym"
+
+// ExpiredRotation is a rotation strategy that returns true
+// if the Metadata().ExpiresAt() of the proxy has passed.
+//
+// Proxies without an expiration date are never rotated by it.
+type ExpiredRotation struct {
+	clocked
+}
+
+// NewExpiredRotation returns a new ExpiredRotation.
+func NewExpiredRotation(opts ...ClockOption) proxym.RotationStrategy {
+	return &ExpiredRotation{clocked: newClocked(opts)}
+}
+
+// ShouldRotate returns true if the proxy has expired.
+func (r *ExpiredRotation) ShouldRotate(proxy *proxym.Proxy) bool {
+	return proxy.Metadata().IsExpired(r.clock.Now())
+}
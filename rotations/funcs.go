@@ -0,0 +1,11 @@
+package rotations
+
+import "github.com/nezbut/proxym"
+
+// RotationFunc is an adapter to allow the use of an ordinary function as a proxym.RotationStrategy.
+type RotationFunc func(proxy *proxym.Proxy) bool
+
+// ShouldRotate returns f(proxy).
+func (f RotationFunc) ShouldRotate(proxy *proxym.Proxy) bool {
+	return f(proxy)
+}
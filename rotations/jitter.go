@@ -0,0 +1,62 @@
+package rotations
+
+import (
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"github.com/nezbut/proxym"
+)
+
+// JitteredTTLRotation is a rotation strategy that rotates the proxy after a random interval within [min, max],
+// so the rotation timing is not a fixed pattern that anti-bot systems can detect.
+//
+// The interval is drawn again for each proxy and starts when ShouldRotate is first called with the proxy,
+// that is, on the first request after the one that selected it. The strategy instance should not be shared
+// between the manager and resources, as they have their own last used proxy.
+type JitteredTTLRotation struct {
+	clocked
+	minTTL   time.Duration
+	maxTTL   time.Duration
+	current  *proxym.Proxy
+	deadline time.Time
+	mu       sync.Mutex
+}
+
+// NewJitteredTTLRotation returns a new JitteredTTLRotation.
+//
+// If maxTTL is less than minTTL, the interval is always minTTL.
+func NewJitteredTTLRotation(minTTL, maxTTL time.Duration, opts ...ClockOption) proxym.RotationStrategy {
+	return &JitteredTTLRotation{
+		clocked: newClocked(opts),
+		minTTL:  minTTL,
+		maxTTL:  maxTTL,
+	}
+}
+
+// ShouldRotate returns true if the drawn interval of the proxy has passed.
+func (r *JitteredTTLRotation) ShouldRotate(proxy *proxym.Proxy) bool {
+	now := r.clock.Now()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.current != proxy {
+		r.current = proxy
+		r.deadline = now.Add(r.draw())
+	}
+	if now.Before(r.deadline) {
+		return false
+	}
+
+	r.current = nil
+	return true
+}
+
+// draw returns a random interval within [minTTL, maxTTL].
+func (r *JitteredTTLRotation) draw() time.Duration {
+	if r.maxTTL <= r.minTTL {
+		return r.minTTL
+	}
+	return r.minTTL + rand.N(r.maxTTL-r.minTTL+1) //nolint: gosec // can be used ordinary random sampling
+}
@@ -1 +1,16 @@
 package rotations_test
+
+import (
+	"fmt"
+
+	"github.com/nezbut/proxym"
+)
+
+// newProxies returns n proxies with distinct urls.
+func newProxies(n int) []*proxym.Proxy {
+	proxies := make([]*proxym.Proxy, 0, n)
+	for i := range n {
+		proxies = append(proxies, proxym.NewProxyStr(fmt.Sprintf("http://proxy%d:8080", i), nil))
+	}
+	return proxies
+}
@@ -0,0 +1,48 @@
+package rotations
+
+import (
+	"sync"
+	"time"
+
+	"github.com/nezbut/proxym"
+)
+
+// ScheduledRotation is a rotation strategy that forces a re-selection at schedule boundaries.
+//
+// The schedule predicate splits the time into windows, for example peak and off-peak hours.
+// The strategy returns true once when the predicate result changes since the previous call,
+// that is, when a new window is crossed into, so the select strategy can choose the proxy for the new window.
+// The first call only remembers the current window.
+//
+// The window is remembered by the strategy, not by the proxy, so the boundary is observed by the first
+// ShouldRotate call after it, whichever proxy it is called with. The strategy instance should not be shared
+// between the manager and resources, as they have their own last used proxy and only the first of them
+// to call it after the boundary would rotate.
+type ScheduledRotation struct {
+	clocked
+	schedule func(now time.Time) bool
+	window   bool
+	started  bool
+	mu       sync.Mutex
+}
+
+// NewScheduledRotation returns a new ScheduledRotation.
+func NewScheduledRotation(schedule func(now time.Time) bool, opts ...ClockOption) proxym.RotationStrategy {
+	return &ScheduledRotation{
+		clocked:  newClocked(opts),
+		schedule: schedule,
+	}
+}
+
+// ShouldRotate returns true if a new schedule window has been crossed into since the previous call.
+func (s *ScheduledRotation) ShouldRotate(_ *proxym.Proxy) bool {
+	window := s.schedule(s.clock.Now())
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	crossed := s.started && window != s.window
+	s.window = window
+	s.started = true
+	return crossed
+}
@@ -0,0 +1,71 @@
+package rotations_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nezbut/proxym"
+	"github.com/nezbut/proxym/rotations"
+	"github.com/nezbut/proxym/selects"
+)
+
+// fakeClock is a proxym.Clock with the manually set time.
+type fakeClock struct {
+	now time.Time
+	mu  sync.Mutex
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func TestScheduledRotationBoundary(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 8, 0, 0, 0, time.UTC)}
+	peak := func(now time.Time) bool {
+		return now.Hour() >= 9 && now.Hour() < 18
+	}
+	pm := proxym.NewProxyManager(
+		proxym.WithProxies(newProxies(3)...),
+		proxym.WithRotationStrategy(rotations.NewScheduledRotation(peak, rotations.WithClock(clock))),
+		proxym.WithSelectStrategy(selects.DefaultSelectStrategy()),
+	)
+
+	next := func() *proxym.Proxy {
+		t.Helper()
+		p, err := pm.GetNextProxy("example.com")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+
+	first := next()
+	for range 10 {
+		clock.Advance(5 * time.Minute)
+		if p := next(); p != first {
+			t.Fatalf("got a rotation within the window at %s", clock.Now())
+		}
+	}
+
+	// 08:50 -> 09:10 crosses into the peak window.
+	clock.Advance(20 * time.Minute)
+	second := next()
+	if second == first {
+		t.Fatal("got no rotation across the boundary")
+	}
+	for range 10 {
+		clock.Advance(5 * time.Minute)
+		if p := next(); p != second {
+			t.Fatalf("got a rotation within the window at %s", clock.Now())
+		}
+	}
+}
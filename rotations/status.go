@@ -0,0 +1,24 @@
+package rotations
+
+import "github.com/nezbut/proxym"
+
+// StatusCodeRotation is a rotation strategy that returns true
+// if the status code of the last response through the proxy is one of the codes, for example 403 or 429.
+type StatusCodeRotation struct {
+	codes map[int]struct{}
+}
+
+// NewStatusCodeRotation returns a new StatusCodeRotation.
+func NewStatusCodeRotation(codes ...int) proxym.RotationStrategy {
+	r := &StatusCodeRotation{codes: make(map[int]struct{}, len(codes))}
+	for _, code := range codes {
+		r.codes[code] = struct{}{}
+	}
+	return r
+}
+
+// ShouldRotate returns true if the last status code of the proxy is one of the codes.
+func (r *StatusCodeRotation) ShouldRotate(proxy *proxym.Proxy) bool {
+	_, ok := r.codes[proxy.Stats().LastStatusCode()]
+	return ok
+}
@@ -0,0 +1,49 @@
+package rotations
+
+import (
+	"sync"
+
+	"github.com/nezbut/proxym"
+)
+
+// StickyRoundRobinRotation is a rotation strategy that uses each proxy for exactly n requests and then rotates.
+//
+// Unlike RequestLimitedRotation it does not use the cumulative total requests of the proxy,
+// it counts the requests since the proxy became current.
+//
+// The manager calls ShouldRotate with its last used proxy on every request except the one that selected it,
+// so the strategy counts these calls: the proxy serves the selecting request and n-1 following requests.
+// When ShouldRotate is called with a proxy other than the previous one, for example after the manager rotated it
+// by another strategy, the counter starts again. The strategy instance should not be shared between
+// the manager and resources, as they have their own last used proxy.
+type StickyRoundRobinRotation struct {
+	n       uint
+	current *proxym.Proxy
+	count   uint
+	mu      sync.Mutex
+}
+
+// NewStickyRoundRobinRotation returns a new StickyRoundRobinRotation.
+func NewStickyRoundRobinRotation(n uint) proxym.RotationStrategy {
+	return &StickyRoundRobinRotation{n: n}
+}
+
+// ShouldRotate returns true if the proxy has been used for n requests since it became current.
+func (s *StickyRoundRobinRotation) ShouldRotate(proxy *proxym.Proxy) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.current != proxy {
+		s.current = proxy
+		s.count = 0
+	}
+
+	s.count++
+	if s.count < s.n {
+		return false
+	}
+
+	s.current = nil
+	s.count = 0
+	return true
+}
@@ -0,0 +1,30 @@
+package rotations_test
+
+import (
+	"testing"
+
+	"github.com/nezbut/proxym"
+	"github.com/nezbut/proxym/rotations"
+	"github.com/nezbut/proxym/selects"
+)
+
+func TestStickyRoundRobinRotation(t *testing.T) {
+	const n = 3
+	proxies := newProxies(3)
+	pm := proxym.NewProxyManager(
+		proxym.WithProxies(proxies...),
+		proxym.WithRotationStrategy(rotations.NewStickyRoundRobinRotation(n)),
+		proxym.WithSelectStrategy(selects.NewRoundRobinSelect),
+	)
+
+	// Two cycles over the pool: each proxy serves exactly n requests in a row.
+	for i := range 2 * n * len(proxies) {
+		p, err := pm.GetNextProxy("example.com")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := proxies[i/n%len(proxies)]; p != want {
+			t.Fatalf("request %d: got %s, want %s", i, p.URL(), want.URL())
+		}
+	}
+}
@@ -1,5 +1,7 @@
 package proxym
 
+import "context"
+
 // SelectStrategy is an interface for proxy selection strategies.
 // It is used to determine which proxy to use.
 type SelectStrategy interface {
@@ -19,3 +21,50 @@ type SelectStrategyProxyProvider interface {
 
 // SelectStrategyFactory is a function that returns a SelectStrategy from a SelectStrategyProxyProvider.
 type SelectStrategyFactory func(SelectStrategyProxyProvider) SelectStrategy
+
+// ContextSelectStrategy is a SelectStrategy that respects the cancellation and the deadline of the context.
+//
+// ProxyManagerImpl.GetNextProxyContext calls SelectContext if the strategy implements it.
+type ContextSelectStrategy interface {
+	SelectStrategy
+	// SelectContext returns the proxy to use like Select.
+	//
+	// If the context is done, the error of the context is returned.
+	SelectContext(ctx context.Context) (*Proxy, error)
+}
+
+// SelectContext selects the proxy by the strategy with the context.
+//
+// If the strategy implements ContextSelectStrategy, SelectContext of it is called,
+// otherwise the context is checked before Select is called.
+func SelectContext(ctx context.Context, strategy SelectStrategy) (*Proxy, error) {
+	if s, ok := strategy.(ContextSelectStrategy); ok {
+		return s.SelectContext(ctx)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return strategy.Select()
+}
+
+// DomainSelectStrategy is a SelectStrategy that selects the proxy by the target domain of the request.
+//
+// ProxyManagerImpl.GetNextProxyContext calls SelectDomain with the domain if the strategy implements it.
+type DomainSelectStrategy interface {
+	SelectStrategy
+	// SelectDomain returns the proxy to use for the domain like Select.
+	//
+	// The domain is empty if it is not known. If the context is done, the error of the context is returned.
+	SelectDomain(ctx context.Context, domain string) (*Proxy, error)
+}
+
+// SelectDomain selects the proxy for the domain by the strategy with the context.
+//
+// If the strategy implements DomainSelectStrategy, SelectDomain of it is called,
+// otherwise the proxy is selected like SelectContext.
+func SelectDomain(ctx context.Context, domain string, strategy SelectStrategy) (*Proxy, error) {
+	if s, ok := strategy.(DomainSelectStrategy); ok {
+		return s.SelectDomain(ctx, domain)
+	}
+	return SelectContext(ctx, strategy)
+}
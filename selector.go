@@ -1,6 +1,7 @@
 package proxym
 
 import (
+	"context"
 	"net/http"
 	"net/url"
 )
@@ -18,12 +19,18 @@ type ProxySelectorSetter interface {
 //
 // If the http.RoundTripper implementation is not supported, it returns an ErrUnsupportedRoundTripperImpl.
 // Supported http.RoundTripper: http.Transport and ProxySelectorSetter.
+//
+// The http.Transport is cloned as *http.Transport if the pool of the ProxyManager has no SOCKS5 proxies,
+// otherwise it is wrapped in a SOCKSTransport, so the SOCKS5 proxies are supported too, see IsSOCKS5.
+// The pool is checked when the http.RoundTripper is cloned, use NewSOCKSTransport directly
+// if the SOCKS5 proxies are added to the pool later.
 func CloneRoundTripperWithProxySelector(pm ProxyManager, rt http.RoundTripper) (http.RoundTripper, error) {
 	switch t := rt.(type) {
 	case *http.Transport:
-		cloned := t.Clone()
-		cloned.Proxy = GetProxySelector(pm)
-		return cloned, nil
+		if !hasSOCKS5(poolProxies(pm)) {
+			return cloneTransport(pm, t), nil
+		}
+		return NewSOCKSTransport(pm, t), nil
 	case ProxySelectorSetter:
 		return t.WithProxySelector(GetProxySelector(pm)), nil
 	default:
@@ -31,16 +38,100 @@ func CloneRoundTripperWithProxySelector(pm ProxyManager, rt http.RoundTripper) (
 	}
 }
 
+// ProxySelectorOption is option for GetProxySelector.
+type ProxySelectorOption func(*proxySelectorConfig)
+
+// proxySelectorConfig is the configuration of the ProxySelector.
+type proxySelectorConfig struct {
+	refuseExpired bool
+	clock         Clock
+}
+
+// WithRefuseExpired makes the ProxySelector refuse to hand out the proxies whose Metadata().ExpiresAt() has passed
+// by the clock, the request fails with ErrProxyExpired. If the clock is nil, SystemClock is used.
+func WithRefuseExpired(clock Clock) ProxySelectorOption {
+	return func(c *proxySelectorConfig) {
+		if clock == nil {
+			clock = SystemClock{}
+		}
+		c.refuseExpired = true
+		c.clock = clock
+	}
+}
+
 // GetProxySelector returns a ProxySelector that uses the ProxyManager to get the next available proxy.
-func GetProxySelector(pm ProxyManager) ProxySelector {
+//
+// If the request context has a proxy (see ContextWithProxy), it is used instead of getting the next one.
+func GetProxySelector(pm ProxyManager, opts ...ProxySelectorOption) ProxySelector {
+	cfg := &proxySelectorConfig{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
 	return func(req *http.Request) (*url.URL, error) {
-		proxy, err := pm.GetNextProxy(req.URL.Hostname())
-		if err != nil {
-			return nil, err
+		proxy := ProxyFromContext(req.Context())
+		if proxy == nil {
+			var err error
+			if proxy, _, err = nextProxy(pm, req); err != nil {
+				return nil, err
+			}
+		}
+		if cfg.refuseExpired && proxy.Metadata().IsExpired(cfg.clock.Now()) {
+			return nil, ErrProxyExpired
 		}
-		if proxy.IsDisabled() {
-			return nil, ErrProxyNotAvailable
+		return proxy.URL(), nil
+	}
+}
+
+// proxyConnectHeader returns a function for http.Transport.GetProxyConnectHeader
+// that adds the headers of the proxy from the request context to the static headers or the headers returned by base.
+func proxyConnectHeader(
+	static http.Header,
+	base func(ctx context.Context, proxyURL *url.URL, target string) (http.Header, error),
+) func(ctx context.Context, proxyURL *url.URL, target string) (http.Header, error) {
+	return func(ctx context.Context, proxyURL *url.URL, target string) (http.Header, error) {
+		header := static.Clone()
+		if base != nil {
+			var err error
+			if header, err = base(ctx, proxyURL, target); err != nil {
+				return nil, err
+			}
+		}
+
+		proxy := ProxyFromContext(ctx)
+		if proxy == nil {
+			return header, nil
+		}
+		if header == nil {
+			header = make(http.Header)
+		}
+		for name, values := range proxy.Metadata().Headers() {
+			header[name] = values
 		}
-		return proxy.url, nil
+		return header, nil
+	}
+}
+
+// nextProxy returns the next available proxy for the request and how it was selected,
+// the selection is aborted when the request context is done.
+//
+// The selection is reported only by ProxyManagerImpl itself, for other managers it is empty.
+func nextProxy(pm ProxyManager, req *http.Request) (*Proxy, selection, error) {
+	var (
+		proxy *Proxy
+		sel   selection
+		err   error
+	)
+	if impl, ok := pm.(*ProxyManagerImpl); ok {
+		proxy, sel, err = impl.selectProxy(req.Context(), NewRequestInfo(req))
+	} else {
+		proxy, err = pm.GetNextProxyContext(req.Context(), req.URL.Hostname())
+	}
+	if err != nil {
+		return nil, sel, err
+	}
+	if proxy.IsDisabled() {
+		return nil, sel, ErrProxyNotAvailable
 	}
+	return proxy, sel, nil
 }
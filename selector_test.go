@@ -0,0 +1,38 @@
+package proxym_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/nezbut/proxym"
+)
+
+func TestGetProxySelectorHostWithPort(t *testing.T) {
+	ipv6, host, global := proxym.NewProxyStr("http://ipv6:8080", nil),
+		proxym.NewProxyStr("http://host:8080", nil), proxym.NewProxyStr("http://global:8080", nil)
+	pm := newManager([]*proxym.Proxy{global}, proxym.WithResources(
+		newResource("[2001:DB8::1]:8443", ipv6),
+		newResource("example.com", host),
+	))
+	selector := proxym.GetProxySelector(pm)
+
+	for target, want := range map[string]*proxym.Proxy{
+		"https://[2001:db8::1]:8443/path": ipv6,
+		"http://[2001:db8::1]/":           ipv6,
+		"https://example.com:8443/path":   host,
+		"http://EXAMPLE.com:80/":          host,
+		"http://[2001:db8::2]:8443/":      global,
+	} {
+		req, err := http.NewRequest(http.MethodGet, target, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		u, err := selector(req)
+		if err != nil {
+			t.Fatalf("%s: %v", target, err)
+		}
+		if u.String() != want.String() {
+			t.Errorf("%s: got %s, want %s", target, u, want)
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package selects
+
+import "github.com/nezbut/proxym"
+
+// ClockOption is option for the time-based select strategies and filters.
+type ClockOption func(*clocked)
+
+// WithClock sets the clock used by the time-based select strategy or filter.
+//
+// By default proxym.SystemClock is used.
+func WithClock(clock proxym.Clock) ClockOption {
+	return func(c *clocked) {
+		c.clock = clock
+	}
+}
+
+// clocked is embedded into the time-based select strategies and filters.
+type clocked struct {
+	clock proxym.Clock
+}
+
+// newClocked returns a clocked with the applied options.
+func newClocked(opts []ClockOption) clocked {
+	c := clocked{clock: proxym.SystemClock{}}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	return c
+}
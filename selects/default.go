@@ -4,11 +4,14 @@ import "github.com/nezbut/proxym"
 
 // DefaultSelectStrategy returns the default select strategy.
 //
-// It returns a RandomSelect with RemoveActiveProxyFilter and RemoveDisabledFilter.
+// It returns a RandomSelect with RemoveActiveProxyFilter, RemoveDisabledFilter, RemoveDrainingFilter
+// and RemoveQuarantinedFilter.
 func DefaultSelectStrategy() proxym.SelectStrategyFactory {
 	return NewFilteredSelectFactory(
 		NewRandomSelect,
 		RemoveActiveProxyFilter{},
 		RemoveDisabledFilter{},
+		RemoveDrainingFilter{},
+		RemoveQuarantinedFilter{},
 	)
 }
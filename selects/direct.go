@@ -0,0 +1,32 @@
+package selects
+
+import "github.com/nezbut/proxym"
+
+// DirectSelect is a proxy selection strategy that always returns the direct connection,
+// see proxym.NewDirectConnection.
+//
+// It is used as the terminal strategy of FallbackSelect, so the requests degrade to the direct connection
+// instead of failing when all real proxies are disabled, see NewDirectFallbackSelectFactory.
+type DirectSelect struct {
+	direct *proxym.Proxy
+}
+
+// NewDirectSelect returns a new DirectSelect, the provider is not used.
+func NewDirectSelect(_ proxym.SelectStrategyProxyProvider) proxym.SelectStrategy {
+	return &DirectSelect{
+		direct: proxym.NewDirectConnection(),
+	}
+}
+
+// Select returns the direct connection.
+func (s *DirectSelect) Select() (*proxym.Proxy, error) {
+	return s.direct, nil
+}
+
+// NewDirectFallbackSelectFactory returns a new proxym.SelectStrategyFactory that selects by the strategy
+// of the factory and returns the direct connection when it fails, for example when all proxies are filtered out.
+//
+// Batch jobs can degrade gracefully instead of failing with proxym.ErrProxyNotAvailable.
+func NewDirectFallbackSelectFactory(factory proxym.SelectStrategyFactory) proxym.SelectStrategyFactory {
+	return NewFallbackSelectFactory(factory, NewDirectSelect)
+}
@@ -0,0 +1,7 @@
+// Package selects provides the proxym.SelectStrategy implementations and the filters applied before the selection.
+//
+// The manager reuses the last used proxy until the rotation strategy rotates it, so the select strategy is called
+// only on the rotations. The strategies that choose the proxy per request, for example by its domain,
+// its session key or the current in-flight requests, need a rotation strategy that rotates on every request,
+// for example rotations.NewStickyRoundRobinRotation(1).
+package selects
@@ -0,0 +1,65 @@
+package selects
+
+import (
+	"fmt"
+	"math/rand/v2"
+
+	"github.com/nezbut/proxym"
+)
+
+// defaultEpsilon is the default exploration probability of the EpsilonGreedySelect.
+const defaultEpsilon = 0.1
+
+// EpsilonGreedySelect is a proxy selection strategy that solves the proxy choice as a multi-armed bandit.
+//
+// With the probability 1-epsilon it exploits the proxy with the best Stats().SuccessRate(),
+// ties are broken randomly, and with the probability epsilon it explores a random proxy.
+// Proxies without requests have the success rate 1, so each new proxy is tried before it is judged.
+type EpsilonGreedySelect struct {
+	randomSource
+	provider proxym.SelectStrategyProxyProvider
+	epsilon  float64
+}
+
+// NewEpsilonGreedySelect returns a new EpsilonGreedySelect that explores with the probability 0.1.
+func NewEpsilonGreedySelect(provider proxym.SelectStrategyProxyProvider) proxym.SelectStrategy {
+	return NewEpsilonGreedySelectFactory(defaultEpsilon, nil)(provider)
+}
+
+// NewEpsilonGreedySelectFactory returns a new proxym.SelectStrategyFactory for EpsilonGreedySelect
+// with the exploration probability epsilon from 0 to 1.
+//
+// If rng is nil, the global random generator is used.
+func NewEpsilonGreedySelectFactory(epsilon float64, rng *rand.Rand) proxym.SelectStrategyFactory {
+	return func(provider proxym.SelectStrategyProxyProvider) proxym.SelectStrategy {
+		return &EpsilonGreedySelect{
+			randomSource: randomSource{rng: rng},
+			provider:     provider,
+			epsilon:      epsilon,
+		}
+	}
+}
+
+// Select returns the proxy to use.
+func (s *EpsilonGreedySelect) Select() (*proxym.Proxy, error) {
+	proxies := s.provider.GetProxies()
+	if len(proxies) == 0 {
+		return nil, fmt.Errorf("%w: empty proxies from provider", proxym.ErrFailedSelectProxy)
+	}
+	if s.float64() < s.epsilon {
+		return proxies[s.intN(len(proxies))], nil
+	}
+
+	best := -1.0
+	tied := make([]*proxym.Proxy, 0, 1)
+	for _, p := range proxies {
+		switch rate := p.Stats().SuccessRate(); {
+		case rate > best:
+			best = rate
+			tied = append(tied[:0], p)
+		case rate == best:
+			tied = append(tied, p)
+		}
+	}
+	return tied[s.intN(len(tied))], nil
+}
@@ -0,0 +1,63 @@
+package selects
+
+import (
+	"fmt"
+
+	"github.com/nezbut/proxym"
+)
+
+// ExpirySoonestSelect is a proxy selection strategy that returns the proxy with the nearest Metadata().ExpiresAt()
+// that is still in the future, so rented proxies are used up before they expire.
+//
+// Proxies without an expiration date are returned only if there are no proxies with it.
+// Expired proxies are never returned, they are expected to be filtered out by RemoveExpiredFilter.
+type ExpirySoonestSelect struct {
+	clocked
+	provider proxym.SelectStrategyProxyProvider
+}
+
+// NewExpirySoonestSelect returns a new ExpirySoonestSelect.
+func NewExpirySoonestSelect(provider proxym.SelectStrategyProxyProvider) proxym.SelectStrategy {
+	return NewExpirySoonestSelectFactory()(provider)
+}
+
+// NewExpirySoonestSelectFactory returns a new proxym.SelectStrategyFactory for ExpirySoonestSelect with the options.
+func NewExpirySoonestSelectFactory(opts ...ClockOption) proxym.SelectStrategyFactory {
+	return func(provider proxym.SelectStrategyProxyProvider) proxym.SelectStrategy {
+		return &ExpirySoonestSelect{
+			clocked:  newClocked(opts),
+			provider: provider,
+		}
+	}
+}
+
+// Select returns the proxy to use.
+func (s *ExpirySoonestSelect) Select() (*proxym.Proxy, error) {
+	proxies := s.provider.GetProxies()
+	if len(proxies) == 0 {
+		return nil, fmt.Errorf("%w: empty proxies from provider", proxym.ErrFailedSelectProxy)
+	}
+
+	now := s.clock.Now()
+	var soonest, withoutExpiry *proxym.Proxy
+	for _, p := range proxies {
+		meta := p.Metadata()
+		switch {
+		case meta.ExpiresAt().IsZero():
+			if withoutExpiry == nil {
+				withoutExpiry = p
+			}
+		case meta.IsExpired(now):
+		case soonest == nil || meta.ExpiresAt().Before(soonest.Metadata().ExpiresAt()):
+			soonest = p
+		}
+	}
+
+	if soonest != nil {
+		return soonest, nil
+	}
+	if withoutExpiry != nil {
+		return withoutExpiry, nil
+	}
+	return nil, fmt.Errorf("%w: all proxies are expired", proxym.ErrFailedSelectProxy)
+}
@@ -0,0 +1,55 @@
+package selects_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/nezbut/proxym"
+	"github.com/nezbut/proxym/selects"
+)
+
+func TestExpirySoonestSelect(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1_000_000, 0)}
+	expiring := func(rawURL string, expiresAt time.Time) *proxym.Proxy {
+		return proxym.NewProxyStr(rawURL, proxym.NewProxyMetadata("", proxym.ProxyPriorityMedium, expiresAt))
+	}
+	never := expiring("http://never:8080", time.Time{})
+	expired := expiring("http://expired:8080", clock.Now().Add(-time.Minute))
+	soonest := expiring("http://soonest:8080", clock.Now().Add(time.Hour))
+	later := expiring("http://later:8080", clock.Now().Add(24*time.Hour))
+
+	cases := []struct {
+		name    string
+		proxies []*proxym.Proxy
+		want    *proxym.Proxy
+	}{
+		{name: "soonest first", proxies: []*proxym.Proxy{never, later, expired, soonest}, want: soonest},
+		{name: "without expiry last", proxies: []*proxym.Proxy{never, expired}, want: never},
+	}
+	for _, tc := range cases {
+		strategy := selects.NewExpirySoonestSelectFactory(selects.WithClock(clock))(selects.StaticProvider(tc.proxies))
+		p, err := strategy.Select()
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		if p != tc.want {
+			t.Errorf("%s: got %s, want %s", tc.name, p.URL(), tc.want.URL())
+		}
+	}
+
+	// The soonest proxy expires, the next soonest is chosen.
+	clock.Advance(2 * time.Hour)
+	strategy := selects.NewExpirySoonestSelectFactory(selects.WithClock(clock))(
+		selects.StaticProvider{never, later, soonest})
+	if p, err := strategy.Select(); err != nil || p != later {
+		t.Errorf("got %v, %v after the soonest expired, want %s", p, err, later.URL())
+	}
+
+	for _, proxies := range [][]*proxym.Proxy{nil, {expired}} {
+		strategy := selects.NewExpirySoonestSelectFactory(selects.WithClock(clock))(selects.StaticProvider(proxies))
+		if _, err := strategy.Select(); !errors.Is(err, proxym.ErrFailedSelectProxy) {
+			t.Errorf("got error %v for %d unusable proxies, want ErrFailedSelectProxy", err, len(proxies))
+		}
+	}
+}
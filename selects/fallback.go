@@ -0,0 +1,67 @@
+package selects
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/nezbut/proxym"
+)
+
+// FallbackSelect is a proxy selection strategy that tries the strategies in order
+// and returns the first proxy returned without an error.
+//
+// For example, prefer PrioritySelect over the high priority proxies and fall back to RandomSelect
+// when all of them are disabled.
+type FallbackSelect struct {
+	strategies []proxym.SelectStrategy
+}
+
+// NewFallbackSelectFactory returns a new proxym.SelectStrategyFactory for FallbackSelect
+// that tries the primary strategy and then the fallback strategies in order.
+func NewFallbackSelectFactory(
+	primary proxym.SelectStrategyFactory,
+	fallbacks ...proxym.SelectStrategyFactory,
+) proxym.SelectStrategyFactory {
+	return func(provider proxym.SelectStrategyProxyProvider) proxym.SelectStrategy {
+		s := &FallbackSelect{strategies: make([]proxym.SelectStrategy, 0, len(fallbacks)+1)}
+		for _, factory := range append([]proxym.SelectStrategyFactory{primary}, fallbacks...) {
+			s.strategies = append(s.strategies, factory(provider))
+		}
+		return s
+	}
+}
+
+// Select returns the proxy of the first strategy that selects it.
+func (s *FallbackSelect) Select() (*proxym.Proxy, error) {
+	return s.SelectDomain(context.Background(), "")
+}
+
+// SelectContext returns the proxy of the first strategy that selects it like SelectDomain without the domain.
+func (s *FallbackSelect) SelectContext(ctx context.Context) (*proxym.Proxy, error) {
+	return s.SelectDomain(ctx, "")
+}
+
+// SelectDomain returns the proxy of the first strategy that selects it,
+// the context and the domain are passed to the strategies like proxym.SelectDomain does.
+//
+// If all strategies fail, the errors of the strategies are joined.
+func (s *FallbackSelect) SelectDomain(ctx context.Context, domain string) (*proxym.Proxy, error) {
+	errs := make([]error, 0, len(s.strategies))
+	for _, strategy := range s.strategies {
+		proxy, err := proxym.SelectDomain(ctx, domain, strategy)
+		if err == nil && proxy != nil {
+			return proxy, nil
+		}
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) == 0 {
+		return nil, fmt.Errorf("%w: no fallback strategy selected a proxy", proxym.ErrFailedSelectProxy)
+	}
+	return nil, fmt.Errorf("%w: all fallback strategies failed: %w", proxym.ErrFailedSelectProxy, errors.Join(errs...))
+}
@@ -0,0 +1,108 @@
+package selects
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"time"
+
+	"github.com/nezbut/proxym"
+)
+
+// defaultExploration is the default exploration share of the FastestSelect.
+const defaultExploration = 0.1
+
+// FastestSelect is a proxy selection strategy that returns the proxy
+// with the lowest Stats().AverageLatency(), the rolling average latency.
+//
+// With the exploration probability it returns a random proxy instead, preferring the proxies without
+// an observed latency, so new proxies are still probed and the latency of slow proxies is refreshed.
+// Proxies without an observed latency are also returned when no proxy has one.
+//
+// The latency of NewProbedFastestSelectFactory is the probe latency instead, see proxym.ProxyStats.Probe.
+type FastestSelect struct {
+	randomSource
+	provider    proxym.SelectStrategyProxyProvider
+	exploration float64
+	latency     func(*proxym.Proxy) (time.Duration, bool)
+}
+
+// NewFastestSelect returns a new FastestSelect that explores 10% of the selections.
+func NewFastestSelect(provider proxym.SelectStrategyProxyProvider) proxym.SelectStrategy {
+	return NewFastestSelectFactory(defaultExploration, nil)(provider)
+}
+
+// NewFastestSelectFactory returns a new proxym.SelectStrategyFactory for FastestSelect
+// with the exploration share of the selections from 0 to 1, for example 0.05 for 5%.
+//
+// If rng is nil, the global random generator is used.
+func NewFastestSelectFactory(exploration float64, rng *rand.Rand) proxym.SelectStrategyFactory {
+	return func(provider proxym.SelectStrategyProxyProvider) proxym.SelectStrategy {
+		return &FastestSelect{
+			randomSource: randomSource{rng: rng},
+			provider:     provider,
+			exploration:  exploration,
+			latency:      trafficLatency,
+		}
+	}
+}
+
+// NewProbedFastestSelectFactory returns a new proxym.SelectStrategyFactory for FastestSelect
+// like NewFastestSelectFactory, but the latency of a proxy is the time to the first byte of the probes
+// against the reference endpoint, see healthcheck.LatencyProber, so it is independent of the real traffic.
+// The proxies whose last probe failed are treated as the proxies without a latency.
+func NewProbedFastestSelectFactory(exploration float64, rng *rand.Rand) proxym.SelectStrategyFactory {
+	return func(provider proxym.SelectStrategyProxyProvider) proxym.SelectStrategy {
+		return &FastestSelect{
+			randomSource: randomSource{rng: rng},
+			provider:     provider,
+			exploration:  exploration,
+			latency:      probeLatency,
+		}
+	}
+}
+
+// trafficLatency returns the rolling average latency of the requests through the proxy.
+func trafficLatency(p *proxym.Proxy) (time.Duration, bool) {
+	stats := p.Stats()
+	return stats.AverageLatency(), stats.LatencySamples() != 0
+}
+
+// probeLatency returns the rolling average time to the first byte of the probes of the proxy.
+func probeLatency(p *proxym.Proxy) (time.Duration, bool) {
+	probe := p.Stats().Probe()
+	return probe.TTFB, probe.Samples != 0 && !probe.Failed
+}
+
+// Select returns the proxy to use.
+func (s *FastestSelect) Select() (*proxym.Proxy, error) {
+	proxies := s.provider.GetProxies()
+	if len(proxies) == 0 {
+		return nil, fmt.Errorf("%w: empty proxies from provider", proxym.ErrFailedSelectProxy)
+	}
+
+	var fastest *proxym.Proxy
+	var fastestLatency int64
+	unprobed := make([]*proxym.Proxy, 0)
+	for _, p := range proxies {
+		observed, ok := s.latency(p)
+		if !ok {
+			unprobed = append(unprobed, p)
+			continue
+		}
+		if latency := int64(observed); fastest == nil || latency < fastestLatency {
+			fastest, fastestLatency = p, latency
+		}
+	}
+
+	switch {
+	case fastest == nil:
+		return unprobed[s.intN(len(unprobed))], nil
+	case s.float64() < s.exploration:
+		if len(unprobed) != 0 {
+			return unprobed[s.intN(len(unprobed))], nil
+		}
+		return proxies[s.intN(len(proxies))], nil
+	default:
+		return fastest, nil
+	}
+}
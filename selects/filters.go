@@ -1,6 +1,10 @@
 package selects
 
-import "github.com/nezbut/proxym"
+import (
+	"strings"
+
+	"github.com/nezbut/proxym"
+)
 
 // RemoveActiveProxyFilter filters and removes the active proxy.
 type RemoveActiveProxyFilter struct{}
@@ -29,3 +33,210 @@ func (f RemoveDisabledFilter) Filter(proxies []*proxym.Proxy) []*proxym.Proxy {
 	}
 	return result
 }
+
+// RemoveDrainingFilter filters and removes the draining proxies.
+type RemoveDrainingFilter struct{}
+
+// Filter returns the filtered list of proxies.
+func (f RemoveDrainingFilter) Filter(proxies []*proxym.Proxy) []*proxym.Proxy {
+	result := make([]*proxym.Proxy, 0, len(proxies))
+	for _, p := range proxies {
+		if !p.IsDraining() {
+			result = append(result, p)
+		}
+	}
+	return result
+}
+
+// RemoveQuarantinedFilter filters and removes the quarantined proxies, see proxym.Proxy.Quarantine.
+//
+// The quarantined proxies are still probed by the manager, see proxym.WithQuarantine.
+type RemoveQuarantinedFilter struct{}
+
+// Filter returns the filtered list of proxies.
+func (f RemoveQuarantinedFilter) Filter(proxies []*proxym.Proxy) []*proxym.Proxy {
+	result := make([]*proxym.Proxy, 0, len(proxies))
+	for _, p := range proxies {
+		if !p.IsQuarantined() {
+			result = append(result, p)
+		}
+	}
+	return result
+}
+
+// RemoveRateLimitedFilter filters and removes the proxies whose rate limiter has no token,
+// see proxym.Proxy.SetRateLimiter.
+//
+// Proxies without a rate limiter are kept. The token is not taken, it is taken by proxym.ProxyTransport.
+type RemoveRateLimitedFilter struct{}
+
+// Filter returns the filtered list of proxies.
+func (f RemoveRateLimitedFilter) Filter(proxies []*proxym.Proxy) []*proxym.Proxy {
+	result := make([]*proxym.Proxy, 0, len(proxies))
+	for _, p := range proxies {
+		if limiter := p.RateLimiter(); limiter == nil || limiter.Available() {
+			result = append(result, p)
+		}
+	}
+	return result
+}
+
+// RemoveExpiredFilter filters and removes the proxies whose Metadata().ExpiresAt() has passed.
+//
+// Proxies without an expiration date are kept.
+type RemoveExpiredFilter struct {
+	clocked
+}
+
+// NewRemoveExpiredFilter returns a new RemoveExpiredFilter.
+func NewRemoveExpiredFilter(opts ...ClockOption) SelectFilter {
+	return &RemoveExpiredFilter{clocked: newClocked(opts)}
+}
+
+// Filter returns the filtered list of proxies.
+func (f *RemoveExpiredFilter) Filter(proxies []*proxym.Proxy) []*proxym.Proxy {
+	now := f.clock.Now()
+	result := make([]*proxym.Proxy, 0, len(proxies))
+	for _, p := range proxies {
+		if !p.Metadata().IsExpired(now) {
+			result = append(result, p)
+		}
+	}
+	return result
+}
+
+// RemoveOverloadedFilter filters and removes the proxies whose number of active references
+// is at or above the threshold.
+//
+// Unlike RemoveActiveProxyFilter, it does not empty the candidate set when every proxy is active
+// but still under the threshold, so the load is spread without starving the selection.
+type RemoveOverloadedFilter struct {
+	threshold int
+}
+
+// NewRemoveOverloadedFilter returns a new RemoveOverloadedFilter.
+func NewRemoveOverloadedFilter(threshold int) SelectFilter {
+	return &RemoveOverloadedFilter{threshold: threshold}
+}
+
+// Filter returns the filtered list of proxies.
+func (f *RemoveOverloadedFilter) Filter(proxies []*proxym.Proxy) []*proxym.Proxy {
+	result := make([]*proxym.Proxy, 0, len(proxies))
+	for _, p := range proxies {
+		if p.ActiveCount() < f.threshold {
+			result = append(result, p)
+		}
+	}
+	return result
+}
+
+// MaxConcurrencyFilter filters and removes the proxies whose number of in-flight requests
+// is at or above the limit, see proxym.Proxy.InFlight.
+//
+// Unlike RemoveOverloadedFilter, it counts the requests in progress through proxym.ProxyTransport,
+// not the active references of the manager. If the limit is not positive, all proxies are kept.
+type MaxConcurrencyFilter struct {
+	limit int
+}
+
+// NewMaxConcurrencyFilter returns a new MaxConcurrencyFilter.
+func NewMaxConcurrencyFilter(limit int) SelectFilter {
+	return &MaxConcurrencyFilter{limit: limit}
+}
+
+// Filter returns the filtered list of proxies.
+func (f *MaxConcurrencyFilter) Filter(proxies []*proxym.Proxy) []*proxym.Proxy {
+	if f.limit <= 0 {
+		return proxies
+	}
+	result := make([]*proxym.Proxy, 0, len(proxies))
+	for _, p := range proxies {
+		if p.InFlight() < f.limit {
+			result = append(result, p)
+		}
+	}
+	return result
+}
+
+// CountryFilter filters and keeps the proxies whose Metadata().Country() is in the Allowed list,
+// so the selection can be restricted to the countries for geo-targeted requests.
+//
+// The countries are compared case-insensitively. If Allowed is empty, all proxies are kept.
+type CountryFilter struct {
+	Allowed []string
+}
+
+// Filter returns the filtered list of proxies.
+func (f CountryFilter) Filter(proxies []*proxym.Proxy) []*proxym.Proxy {
+	if len(f.Allowed) == 0 {
+		return proxies
+	}
+	result := make([]*proxym.Proxy, 0, len(proxies))
+	for _, p := range proxies {
+		if containsCountry(f.Allowed, p.Metadata().Country()) {
+			result = append(result, p)
+		}
+	}
+	return result
+}
+
+// CountryDenyFilter filters and removes the proxies whose Metadata().Country() is in the Denied list.
+//
+// The countries are compared case-insensitively.
+type CountryDenyFilter struct {
+	Denied []string
+}
+
+// Filter returns the filtered list of proxies.
+func (f CountryDenyFilter) Filter(proxies []*proxym.Proxy) []*proxym.Proxy {
+	result := make([]*proxym.Proxy, 0, len(proxies))
+	for _, p := range proxies {
+		if !containsCountry(f.Denied, p.Metadata().Country()) {
+			result = append(result, p)
+		}
+	}
+	return result
+}
+
+// containsCountry returns true if the country is in the list of countries.
+func containsCountry(countries []string, country string) bool {
+	for _, c := range countries {
+		if strings.EqualFold(c, country) {
+			return true
+		}
+	}
+	return false
+}
+
+// TagFilter filters and keeps the proxies with the tags, see proxym.ProxyMetadata.AddTag.
+//
+// By default a proxy must have all Tags, if MatchAny is true, any of them is enough.
+// If Tags is empty, all proxies are kept.
+type TagFilter struct {
+	Tags     []string
+	MatchAny bool
+}
+
+// Filter returns the filtered list of proxies.
+func (f TagFilter) Filter(proxies []*proxym.Proxy) []*proxym.Proxy {
+	if len(f.Tags) == 0 {
+		return proxies
+	}
+	result := make([]*proxym.Proxy, 0, len(proxies))
+	for _, p := range proxies {
+		if f.match(p.Metadata()) {
+			result = append(result, p)
+		}
+	}
+	return result
+}
+
+// match returns true if the metadata has all tags or any of them if MatchAny is true.
+func (f TagFilter) match(meta *proxym.ProxyMetadata) bool {
+	for _, tag := range f.Tags {
+		if meta.HasTag(tag) == f.MatchAny {
+			return f.MatchAny
+		}
+	}
+	return !f.MatchAny
+}
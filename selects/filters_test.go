@@ -0,0 +1,35 @@
+package selects_test
+
+import (
+	"testing"
+
+	"github.com/nezbut/proxym"
+	"github.com/nezbut/proxym/rotations"
+	"github.com/nezbut/proxym/selects"
+)
+
+func TestRemoveOverloadedFilter(t *testing.T) {
+	proxies := newProxies(3)
+	pm := proxym.NewProxyManager(
+		proxym.WithProxies(proxies...),
+		proxym.WithRotationStrategy(rotations.RoundRobinRotation{}),
+		proxym.WithSelectStrategy(selects.NewRoundRobinSelect),
+	)
+
+	// The manager holds its last used proxy active.
+	active, err := pm.GetNextProxy("example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := len(selects.RemoveActiveProxyFilter{}.Filter(proxies)); got != len(proxies)-1 {
+		t.Fatalf("got %d proxies kept by RemoveActiveProxyFilter, want %d", got, len(proxies)-1)
+	}
+	if got := len(selects.NewRemoveOverloadedFilter(2).Filter(proxies)); got != len(proxies) {
+		t.Errorf("got %d proxies under the threshold kept, want %d", got, len(proxies))
+	}
+	for _, p := range selects.NewRemoveOverloadedFilter(1).Filter(proxies) {
+		if p == active {
+			t.Errorf("got %s at the threshold, want it filtered", p.URL())
+		}
+	}
+}
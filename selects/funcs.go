@@ -0,0 +1,28 @@
+package selects
+
+import "github.com/nezbut/proxym"
+
+// FilterFunc is an adapter to allow the use of an ordinary function as a SelectFilter.
+type FilterFunc func(proxies []*proxym.Proxy) []*proxym.Proxy
+
+// Filter returns f(proxies).
+func (f FilterFunc) Filter(proxies []*proxym.Proxy) []*proxym.Proxy {
+	return f(proxies)
+}
+
+// SelectFunc is an adapter to allow the use of an ordinary function as a proxym.SelectStrategy.
+//
+// For example, an inline factory:
+//
+//	func(provider proxym.SelectStrategyProxyProvider) proxym.SelectStrategy {
+//		return selects.SelectFunc(func() (*proxym.Proxy, error) {
+//			proxies := provider.GetProxies()
+//			// ...
+//		})
+//	}
+type SelectFunc func() (*proxym.Proxy, error)
+
+// Select returns f().
+func (f SelectFunc) Select() (*proxym.Proxy, error) {
+	return f()
+}
@@ -0,0 +1,75 @@
+package selects
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"strings"
+
+	"github.com/nezbut/proxym"
+)
+
+// ConsistentHashSelect is a proxy selection strategy that hashes the target domain to a proxy,
+// so the same domain always goes through the same proxy.
+//
+// It uses rendezvous hashing: when a proxy is removed, disabled and filtered out, only the domains
+// of that proxy move to other proxies, the other domains keep their proxies.
+//
+// The domain is passed by ProxyManagerImpl, see proxym.DomainSelectStrategy.
+// To route each request by its domain, use a rotation strategy that rotates on every request, see the package doc.
+// Filters that depend on the last used proxy, like RemoveActiveProxyFilter, move the domains between proxies.
+type ConsistentHashSelect struct {
+	provider proxym.SelectStrategyProxyProvider
+}
+
+// NewConsistentHashSelect returns a new ConsistentHashSelect.
+func NewConsistentHashSelect(provider proxym.SelectStrategyProxyProvider) proxym.SelectStrategy {
+	return &ConsistentHashSelect{
+		provider: provider,
+	}
+}
+
+// Select returns the proxy for the empty domain.
+func (s *ConsistentHashSelect) Select() (*proxym.Proxy, error) {
+	return s.SelectDomain(context.Background(), "")
+}
+
+// SelectDomain returns the proxy to which the domain is hashed.
+func (s *ConsistentHashSelect) SelectDomain(ctx context.Context, domain string) (*proxym.Proxy, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	proxies := s.provider.GetProxies()
+	if len(proxies) == 0 {
+		return nil, fmt.Errorf("%w: empty proxies from provider", proxym.ErrFailedSelectProxy)
+	}
+
+	domain = strings.ToLower(domain)
+	var best *proxym.Proxy
+	var bestWeight uint64
+	for _, p := range proxies {
+		if weight := rendezvousWeight(domain, p.String()); best == nil || weight > bestWeight {
+			best, bestWeight = p, weight
+		}
+	}
+	return best, nil
+}
+
+// rendezvousWeight returns the weight of the proxy for the key in the rendezvous hashing.
+func rendezvousWeight(key, proxy string) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(key))
+	_, _ = h.Write([]byte{0})
+	_, _ = h.Write([]byte(proxy))
+	return mix64(h.Sum64())
+}
+
+// mix64 is the finalizer of the SplitMix64 generator, it spreads the bits of the similar hashes.
+func mix64(x uint64) uint64 {
+	x ^= x >> 30 //nolint: mnd // SplitMix64 constants
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27 //nolint: mnd // SplitMix64 constants
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31 //nolint: mnd // SplitMix64 constants
+	return x
+}
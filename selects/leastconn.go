@@ -0,0 +1,57 @@
+package selects
+
+import (
+	"fmt"
+	"math/rand/v2"
+
+	"github.com/nezbut/proxym"
+)
+
+// LeastConnectionsSelect is a proxy selection strategy that returns the proxy
+// with the fewest in-flight requests, see proxym.Proxy.InFlight, ties are broken randomly.
+//
+// The in-flight requests are counted by proxym.ProxyTransport from the start of the request
+// until the response body is closed.
+// It needs a rotation strategy that rotates on every request, see the package doc.
+type LeastConnectionsSelect struct {
+	randomSource
+	provider proxym.SelectStrategyProxyProvider
+}
+
+// NewLeastConnectionsSelect returns a new LeastConnectionsSelect.
+func NewLeastConnectionsSelect(provider proxym.SelectStrategyProxyProvider) proxym.SelectStrategy {
+	return NewLeastConnectionsSelectFactory(nil)(provider)
+}
+
+// NewLeastConnectionsSelectFactory returns a new proxym.SelectStrategyFactory for LeastConnectionsSelect.
+//
+// If rng is nil, the global random generator is used.
+func NewLeastConnectionsSelectFactory(rng *rand.Rand) proxym.SelectStrategyFactory {
+	return func(provider proxym.SelectStrategyProxyProvider) proxym.SelectStrategy {
+		return &LeastConnectionsSelect{
+			randomSource: randomSource{rng: rng},
+			provider:     provider,
+		}
+	}
+}
+
+// Select returns the proxy to use.
+func (s *LeastConnectionsSelect) Select() (*proxym.Proxy, error) {
+	proxies := s.provider.GetProxies()
+	if len(proxies) == 0 {
+		return nil, fmt.Errorf("%w: empty proxies from provider", proxym.ErrFailedSelectProxy)
+	}
+
+	var least int
+	tied := make([]*proxym.Proxy, 0, 1)
+	for _, p := range proxies {
+		switch inFlight := p.InFlight(); {
+		case len(tied) == 0 || inFlight < least:
+			least = inFlight
+			tied = append(tied[:0], p)
+		case inFlight == least:
+			tied = append(tied, p)
+		}
+	}
+	return tied[s.intN(len(tied))], nil
+}
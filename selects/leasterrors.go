@@ -0,0 +1,122 @@
+package selects
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"time"
+
+	"github.com/nezbut/proxym"
+)
+
+// ErrorMetric is the metric of the errors of a proxy compared by LeastErrorsSelect.
+type ErrorMetric int
+
+// ErrorMetric constants.
+const (
+	// ErrorMetricCount compares Stats().ErrorCount().
+	ErrorMetricCount ErrorMetric = iota
+	// ErrorMetricRatio compares the ratio of the errors to the total requests, 0 for a proxy without requests.
+	ErrorMetricRatio
+	// ErrorMetricRecentRatio compares the ratio of the errors to the requests in the recent window,
+	// 0 for a proxy without requests in the window, see NewRecentLeastErrorsSelectFactory.
+	ErrorMetricRecentRatio
+	// ErrorMetricDecayedRatio compares the ratio of the decayed errors to the decayed requests,
+	// 0 for a proxy without requests, see proxym.ProxyStats.Decayed and proxym.WithStatsHalfLife.
+	ErrorMetricDecayedRatio
+)
+
+// LeastErrorsSelect is a proxy selection strategy that returns the proxy with the fewest errors
+// by the ErrorMetric, ties are broken randomly, so the traffic continuously shifts away from flaky proxies.
+type LeastErrorsSelect struct {
+	randomSource
+	clocked
+	provider     proxym.SelectStrategyProxyProvider
+	metric       ErrorMetric
+	window       time.Duration
+	lastRequests uint
+}
+
+// NewLeastErrorsSelect returns a new LeastErrorsSelect that compares the error count.
+func NewLeastErrorsSelect(provider proxym.SelectStrategyProxyProvider) proxym.SelectStrategy {
+	return NewLeastErrorsSelectFactory(ErrorMetricCount, nil)(provider)
+}
+
+// NewLeastErrorsSelectFactory returns a new proxym.SelectStrategyFactory for LeastErrorsSelect with the metric.
+//
+// If rng is nil, the global random generator is used.
+//
+// The ErrorMetricRecentRatio metric needs the window, use NewRecentLeastErrorsSelectFactory for it.
+func NewLeastErrorsSelectFactory(metric ErrorMetric, rng *rand.Rand) proxym.SelectStrategyFactory {
+	return func(provider proxym.SelectStrategyProxyProvider) proxym.SelectStrategy {
+		return &LeastErrorsSelect{
+			randomSource: randomSource{rng: rng},
+			clocked:      newClocked(nil),
+			provider:     provider,
+			metric:       metric,
+		}
+	}
+}
+
+// NewRecentLeastErrorsSelectFactory returns a new proxym.SelectStrategyFactory for LeastErrorsSelect
+// with the ErrorMetricRecentRatio metric, so the recent behavior of the proxies, not the old errors,
+// drives the selection.
+//
+// The recent window is the last lastRequests requests of the proxy if it is not 0,
+// see proxym.ProxyStats.LastRequests, otherwise the requests of the last window duration,
+// see proxym.ProxyStats.Window. If rng is nil, the global random generator is used.
+func NewRecentLeastErrorsSelectFactory(
+	window time.Duration,
+	lastRequests uint,
+	rng *rand.Rand,
+	opts ...ClockOption,
+) proxym.SelectStrategyFactory {
+	return func(provider proxym.SelectStrategyProxyProvider) proxym.SelectStrategy {
+		return &LeastErrorsSelect{
+			randomSource: randomSource{rng: rng},
+			clocked:      newClocked(opts),
+			provider:     provider,
+			metric:       ErrorMetricRecentRatio,
+			window:       window,
+			lastRequests: lastRequests,
+		}
+	}
+}
+
+// Select returns the proxy to use.
+func (s *LeastErrorsSelect) Select() (*proxym.Proxy, error) {
+	proxies := s.provider.GetProxies()
+	if len(proxies) == 0 {
+		return nil, fmt.Errorf("%w: empty proxies from provider", proxym.ErrFailedSelectProxy)
+	}
+
+	var least float64
+	tied := make([]*proxym.Proxy, 0, 1)
+	for _, p := range proxies {
+		switch value := s.errorValue(p); {
+		case len(tied) == 0 || value < least:
+			least = value
+			tied = append(tied[:0], p)
+		case value == least:
+			tied = append(tied, p)
+		}
+	}
+	return tied[s.intN(len(tied))], nil
+}
+
+// errorValue returns the errors of the proxy by the metric.
+func (s *LeastErrorsSelect) errorValue(proxy *proxym.Proxy) float64 {
+	switch s.metric {
+	case ErrorMetricRecentRatio:
+		if s.lastRequests != 0 {
+			return proxy.Stats().LastRequests(s.lastRequests).ErrorRate()
+		}
+		return proxy.Stats().Window(s.clock.Now().Add(-s.window)).ErrorRate()
+	case ErrorMetricDecayedRatio:
+		return proxy.Stats().Decayed().ErrorRate()
+	}
+	stats := proxy.Stats().Snapshot()
+	if s.metric == ErrorMetricRatio {
+		return 1 - stats.SuccessRate()
+	}
+	return float64(stats.ErrorCount)
+}
@@ -0,0 +1,84 @@
+package selects
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/nezbut/proxym"
+)
+
+// priorityTiers are the priority tiers from the highest to the lowest.
+var priorityTiers = []proxym.ProxyPriority{
+	proxym.ProxyPriorityHigh,
+	proxym.ProxyPriorityMedium,
+	proxym.ProxyPriorityLow,
+}
+
+// PrioritySelect is a proxy selection strategy that prefers the proxies with the higher Metadata().Priority().
+//
+// The proxies are split into the high, medium and low tiers, each tier has its own select strategy.
+// The strategy of a lower tier is used only when the strategy of the higher tier fails
+// with proxym.ErrFailedSelectProxy, that is, the higher tier is empty, exhausted or filtered out.
+// Priorities above proxym.ProxyPriorityHigh belong to the high tier.
+type PrioritySelect struct {
+	tiers []proxym.SelectStrategy
+}
+
+// NewPrioritySelect returns a new PrioritySelect that selects a random proxy within a tier.
+func NewPrioritySelect(provider proxym.SelectStrategyProxyProvider) proxym.SelectStrategy {
+	return NewPrioritySelectFactory(NewRandomSelect)(provider)
+}
+
+// NewPrioritySelectFactory returns a new proxym.SelectStrategyFactory for PrioritySelect
+// that selects the proxy within a tier by the strategy of the tierFactory.
+func NewPrioritySelectFactory(tierFactory proxym.SelectStrategyFactory) proxym.SelectStrategyFactory {
+	return func(provider proxym.SelectStrategyProxyProvider) proxym.SelectStrategy {
+		s := &PrioritySelect{tiers: make([]proxym.SelectStrategy, 0, len(priorityTiers))}
+		for _, priority := range priorityTiers {
+			s.tiers = append(s.tiers, tierFactory(&priorityProvider{provider: provider, priority: priority}))
+		}
+		return s
+	}
+}
+
+// Select returns the proxy from the highest available tier.
+func (s *PrioritySelect) Select() (*proxym.Proxy, error) {
+	return s.SelectContext(context.Background())
+}
+
+// SelectContext returns the proxy from the highest available tier like SelectDomain without the domain.
+func (s *PrioritySelect) SelectContext(ctx context.Context) (*proxym.Proxy, error) {
+	return s.SelectDomain(ctx, "")
+}
+
+// SelectDomain returns the proxy from the highest available tier,
+// the context and the domain are passed to the strategies of the tiers like proxym.SelectDomain does.
+func (s *PrioritySelect) SelectDomain(ctx context.Context, domain string) (*proxym.Proxy, error) {
+	for _, tier := range s.tiers {
+		proxy, err := proxym.SelectDomain(ctx, domain, tier)
+		if errors.Is(err, proxym.ErrFailedSelectProxy) || (err == nil && proxy == nil) {
+			continue
+		}
+		return proxy, err
+	}
+	return nil, fmt.Errorf("%w: no proxies in any priority tier", proxym.ErrFailedSelectProxy)
+}
+
+// priorityProvider is a provider of the proxies of the priority tier.
+type priorityProvider struct {
+	provider proxym.SelectStrategyProxyProvider
+	priority proxym.ProxyPriority
+}
+
+// GetProxies returns the proxies of the priority tier.
+func (p *priorityProvider) GetProxies() []*proxym.Proxy {
+	proxies := p.provider.GetProxies()
+	tier := make([]*proxym.Proxy, 0, len(proxies))
+	for _, proxy := range proxies {
+		if min(proxy.Metadata().Priority(), proxym.ProxyPriorityHigh) == p.priority {
+			tier = append(tier, proxy)
+		}
+	}
+	return tier
+}
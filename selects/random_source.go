@@ -0,0 +1,35 @@
+package selects
+
+import (
+	"math/rand/v2"
+	"sync"
+)
+
+// randomSource is embedded into the select strategies that sample with an optional random generator.
+//
+// If rng is nil, the global random generator is used, otherwise the calls are serialized,
+// as rand.Rand is not safe for concurrent use.
+type randomSource struct {
+	rng *rand.Rand
+	mu  sync.Mutex
+}
+
+// float64 returns a random number in [0, 1).
+func (r *randomSource) float64() float64 {
+	if r.rng == nil {
+		return rand.Float64() //nolint: gosec // can be used ordinary random sampling
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.rng.Float64()
+}
+
+// intN returns a random number in [0, n).
+func (r *randomSource) intN(n int) int {
+	if r.rng == nil {
+		return rand.IntN(n) //nolint: gosec // can be used ordinary random sampling
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.rng.IntN(n)
+}
@@ -0,0 +1,94 @@
+package selects
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+
+	"github.com/nezbut/proxym"
+)
+
+// ScoredSelect is a proxy selection strategy that scores each candidate with a user-supplied function
+// and returns the highest-scoring one.
+//
+// Candidates scoring NaN, -Inf or below the floor are excluded.
+// If several candidates have the highest score, the tie-break function chooses among them,
+// by default the first one is returned.
+type ScoredSelect struct {
+	provider proxym.SelectStrategyProxyProvider
+	score    func(*proxym.Proxy) float64
+	floor    float64
+	tieBreak func([]*proxym.Proxy) *proxym.Proxy
+}
+
+// ScoredSelectOption is option for ScoredSelect.
+type ScoredSelectOption func(*ScoredSelect)
+
+// WithScoreFloor sets the floor of the score, candidates scoring below it are excluded.
+func WithScoreFloor(floor float64) ScoredSelectOption {
+	return func(s *ScoredSelect) {
+		s.floor = floor
+	}
+}
+
+// WithTieBreak sets the function that chooses among the candidates with the highest score.
+func WithTieBreak(tieBreak func(tied []*proxym.Proxy) *proxym.Proxy) ScoredSelectOption {
+	return func(s *ScoredSelect) {
+		s.tieBreak = tieBreak
+	}
+}
+
+// FirstTieBreak returns the first of the tied proxies.
+func FirstTieBreak(tied []*proxym.Proxy) *proxym.Proxy {
+	return tied[0]
+}
+
+// RandomTieBreak returns a random one of the tied proxies.
+func RandomTieBreak(tied []*proxym.Proxy) *proxym.Proxy {
+	return tied[rand.IntN(len(tied))] //nolint: gosec // can be used ordinary random sampling
+}
+
+// NewScoredSelectFactory returns a new proxym.SelectStrategyFactory for ScoredSelect with the score function.
+func NewScoredSelectFactory(
+	score func(*proxym.Proxy) float64, opts ...ScoredSelectOption,
+) proxym.SelectStrategyFactory {
+	return func(provider proxym.SelectStrategyProxyProvider) proxym.SelectStrategy {
+		s := &ScoredSelect{
+			provider: provider,
+			score:    score,
+			floor:    math.Inf(-1),
+			tieBreak: FirstTieBreak,
+		}
+		for _, opt := range opts {
+			opt(s)
+		}
+		return s
+	}
+}
+
+// Select returns the proxy to use.
+func (s *ScoredSelect) Select() (*proxym.Proxy, error) {
+	proxies := s.provider.GetProxies()
+	if len(proxies) == 0 {
+		return nil, fmt.Errorf("%w: empty proxies from provider", proxym.ErrFailedSelectProxy)
+	}
+
+	best := math.Inf(-1)
+	tied := make([]*proxym.Proxy, 0, 1)
+	for _, p := range proxies {
+		score := s.score(p)
+		switch {
+		case math.IsNaN(score) || math.IsInf(score, -1) || score < s.floor:
+		case score > best:
+			best = score
+			tied = append(tied[:0], p)
+		case score == best:
+			tied = append(tied, p)
+		}
+	}
+
+	if len(tied) == 0 {
+		return nil, fmt.Errorf("%w: all proxies scored below the floor", proxym.ErrFailedSelectProxy)
+	}
+	return s.tieBreak(tied), nil
+}
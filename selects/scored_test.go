@@ -0,0 +1,56 @@
+package selects_test
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/nezbut/proxym"
+	"github.com/nezbut/proxym/selects"
+)
+
+func TestScoredSelect(t *testing.T) {
+	proxies := weightedProxies(1, 5, 5, 3)
+	// The custom scorer prefers the heavier proxies.
+	byWeight := func(p *proxym.Proxy) float64 {
+		return float64(p.Metadata().Weight())
+	}
+
+	strategy := selects.NewScoredSelectFactory(byWeight)(selects.StaticProvider(proxies))
+	if p, err := strategy.Select(); err != nil || p != proxies[1] {
+		t.Errorf("got %v, %v, want the first of the highest-scoring proxies %s", p, err, proxies[1].URL())
+	}
+
+	var tied []*proxym.Proxy
+	last := func(candidates []*proxym.Proxy) *proxym.Proxy {
+		tied = candidates
+		return candidates[len(candidates)-1]
+	}
+	strategy = selects.NewScoredSelectFactory(byWeight, selects.WithTieBreak(last))(selects.StaticProvider(proxies))
+	if p, err := strategy.Select(); err != nil || p != proxies[2] {
+		t.Errorf("got %v, %v, want the proxy chosen by the tie-break %s", p, err, proxies[2].URL())
+	}
+	if len(tied) != 2 || tied[0] != proxies[1] || tied[1] != proxies[2] {
+		t.Errorf("got %d tied proxies, want the 2 highest-scoring ones", len(tied))
+	}
+
+	// NaN and -Inf are excluded, the floor excludes the low scores.
+	excluded := func(p *proxym.Proxy) float64 {
+		switch p {
+		case proxies[1]:
+			return math.NaN()
+		case proxies[2]:
+			return math.Inf(-1)
+		default:
+			return byWeight(p)
+		}
+	}
+	strategy = selects.NewScoredSelectFactory(excluded, selects.WithScoreFloor(2))(selects.StaticProvider(proxies))
+	if p, err := strategy.Select(); err != nil || p != proxies[3] {
+		t.Errorf("got %v, %v, want the only proxy above the floor %s", p, err, proxies[3].URL())
+	}
+	strategy = selects.NewScoredSelectFactory(excluded, selects.WithScoreFloor(10))(selects.StaticProvider(proxies))
+	if _, err := strategy.Select(); !errors.Is(err, proxym.ErrFailedSelectProxy) {
+		t.Errorf("got error %v with all proxies below the floor, want ErrFailedSelectProxy", err)
+	}
+}
@@ -1 +1,89 @@
 package selects_test
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nezbut/proxym"
+	"github.com/nezbut/proxym/selects"
+)
+
+// newProxies returns n proxies with distinct urls.
+func newProxies(n int) []*proxym.Proxy {
+	proxies := make([]*proxym.Proxy, 0, n)
+	for i := range n {
+		proxies = append(proxies, proxym.NewProxyStr(fmt.Sprintf("http://proxy%d:8080", i), nil))
+	}
+	return proxies
+}
+
+// fakeClock is a proxym.Clock with the manually set time.
+type fakeClock struct {
+	now time.Time
+	mu  sync.Mutex
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func countSelections(t *testing.T, strategy proxym.SelectStrategy, n int) map[*proxym.Proxy]int {
+	t.Helper()
+	counts := make(map[*proxym.Proxy]int)
+	for range n {
+		p, err := strategy.Select()
+		if err != nil {
+			t.Fatal(err)
+		}
+		counts[p]++
+	}
+	return counts
+}
+
+func benchmarkSelect(b *testing.B, factory proxym.SelectStrategyFactory) {
+	b.Helper()
+	strategy := factory(selects.StaticProvider(selects.GenerateProxies(100, 1)))
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for range b.N {
+		if _, err := strategy.Select(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkRandomSelect(b *testing.B) {
+	benchmarkSelect(b, selects.NewRandomSelect)
+}
+
+func BenchmarkRoundRobinSelect(b *testing.B) {
+	benchmarkSelect(b, selects.NewRoundRobinSelect)
+}
+
+func BenchmarkWeightedRoundRobinSelect(b *testing.B) {
+	benchmarkSelect(b, selects.NewWeightedRoundRobinSelect)
+}
+
+func BenchmarkSplitSelect(b *testing.B) {
+	benchmarkSelect(b, selects.NewSplitSelectFactory(
+		rand.New(rand.NewPCG(1, 1)),
+		selects.SplitRoute{Weight: 10, Strategy: selects.NewRandomSelect},
+		selects.SplitRoute{Weight: 90, Strategy: selects.NewRoundRobinSelect},
+	))
+}
+
+func BenchmarkDefaultSelectStrategy(b *testing.B) {
+	benchmarkSelect(b, selects.DefaultSelectStrategy())
+}
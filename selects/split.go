@@ -0,0 +1,99 @@
+package selects
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"sync"
+
+	"github.com/nezbut/proxym"
+)
+
+// SplitRoute is a route of the SplitSelect.
+type SplitRoute struct {
+	// Weight is the share of selections routed to the strategy, for example 10 and 90 for a 10%/90% split.
+	Weight uint
+	// Strategy is the factory of the select strategy used for the route.
+	Strategy proxym.SelectStrategyFactory
+	// Provider is the provider of the proxies for the route, for example a separate pool of a new proxy provider.
+	// If it is nil, the provider passed to the factory is used.
+	Provider proxym.SelectStrategyProxyProvider
+}
+
+// splitRoute is a built route of the SplitSelect.
+type splitRoute struct {
+	weight   uint
+	strategy proxym.SelectStrategy
+}
+
+// SplitSelect is a proxy selection strategy that routes selections across multiple inner strategies
+// by the configured weights using a weighted random draw.
+//
+// It can be used for canary or gradual rollout of a new proxy provider.
+type SplitSelect struct {
+	routes []splitRoute
+	total  uint
+	rng    *rand.Rand
+	mu     sync.Mutex
+}
+
+// NewSplitSelectFactory returns a new proxym.SelectStrategyFactory for SplitSelect.
+//
+// If rng is nil, the global random generator is used.
+func NewSplitSelectFactory(rng *rand.Rand, routes ...SplitRoute) proxym.SelectStrategyFactory {
+	return func(provider proxym.SelectStrategyProxyProvider) proxym.SelectStrategy {
+		s := &SplitSelect{
+			routes: make([]splitRoute, 0, len(routes)),
+			rng:    rng,
+		}
+		for _, route := range routes {
+			routeProvider := route.Provider
+			if routeProvider == nil {
+				routeProvider = provider
+			}
+			s.routes = append(s.routes, splitRoute{
+				weight:   route.Weight,
+				strategy: route.Strategy(routeProvider),
+			})
+			s.total += route.Weight
+		}
+		return s
+	}
+}
+
+// Select returns the proxy from the strategy of the drawn route.
+func (s *SplitSelect) Select() (*proxym.Proxy, error) {
+	return s.SelectContext(context.Background())
+}
+
+// SelectContext returns the proxy from the strategy of the drawn route like SelectDomain without the domain.
+func (s *SplitSelect) SelectContext(ctx context.Context) (*proxym.Proxy, error) {
+	return s.SelectDomain(ctx, "")
+}
+
+// SelectDomain returns the proxy from the strategy of the drawn route,
+// the context and the domain are passed to the strategy like proxym.SelectDomain does.
+func (s *SplitSelect) SelectDomain(ctx context.Context, domain string) (*proxym.Proxy, error) {
+	if s.total == 0 {
+		return nil, fmt.Errorf("%w: no split routes with weight", proxym.ErrFailedSelectProxy)
+	}
+
+	draw := s.draw()
+	for _, route := range s.routes {
+		if draw < route.weight {
+			return proxym.SelectDomain(ctx, domain, route.strategy)
+		}
+		draw -= route.weight
+	}
+	return nil, fmt.Errorf("%w: no split route drawn", proxym.ErrFailedSelectProxy)
+}
+
+// draw returns a random number in [0, total).
+func (s *SplitSelect) draw() uint {
+	if s.rng == nil {
+		return rand.UintN(s.total) //nolint: gosec // can be used ordinary random sampling
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.rng.UintN(s.total)
+}
@@ -0,0 +1,29 @@
+package selects_test
+
+import (
+	"math/rand/v2"
+	"testing"
+
+	"github.com/nezbut/proxym"
+	"github.com/nezbut/proxym/selects"
+)
+
+func TestSplitSelectRatio(t *testing.T) {
+	poolA := selects.StaticProvider{proxym.NewProxyStr("http://a1:8080", nil), proxym.NewProxyStr("http://a2:8080", nil)}
+	poolB := selects.StaticProvider{proxym.NewProxyStr("http://b1:8080", nil)}
+	strategy := selects.NewSplitSelectFactory(
+		rand.New(rand.NewPCG(1, 2)),
+		selects.SplitRoute{Weight: 90, Strategy: selects.NewRoundRobinSelect, Provider: poolA},
+		selects.SplitRoute{Weight: 10, Strategy: selects.NewRoundRobinSelect, Provider: poolB},
+	)(nil)
+
+	const n = 20000
+	counts := countSelections(t, strategy, n)
+	// The share of the canary pool is 10% within 1 percentage point, about 6 standard deviations.
+	if got := float64(counts[poolB[0]]) / n; got < 0.09 || got > 0.11 {
+		t.Errorf("got %.3f of the selections through pool B, want 0.10", got)
+	}
+	if got := counts[poolA[0]] + counts[poolA[1]] + counts[poolB[0]]; got != n {
+		t.Errorf("got %d selections through the pools, want %d", got, n)
+	}
+}
@@ -0,0 +1,55 @@
+package selects
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"time"
+
+	"github.com/nezbut/proxym"
+)
+
+// StaticProvider is a proxym.SelectStrategyProxyProvider backed by a fixed slice of proxies.
+//
+// It can be used to benchmark and validate select strategies in isolation:
+//
+//	strategy := selects.NewRandomSelect(selects.StaticProvider(selects.GenerateProxies(100, 1)))
+type StaticProvider []*proxym.Proxy
+
+// GetProxies returns the copied list of proxies.
+func (p StaticProvider) GetProxies() []*proxym.Proxy {
+	proxies := make([]*proxym.Proxy, len(p))
+	copy(proxies, p)
+	return proxies
+}
+
+// NewSeededProxy creates a new proxy from a string url with the statistics from the snapshot.
+//
+// It panics if the url is invalid.
+func NewSeededProxy(urlStr string, stats proxym.StatsSnapshot) *proxym.Proxy {
+	p := proxym.NewProxyStr(urlStr, nil)
+	p.Stats().Restore(stats)
+	return p
+}
+
+// GenerateProxies creates n proxies with pseudo-random statistics generated from the seed,
+// the same seed always generates the same statistics.
+func GenerateProxies(n int, seed uint64) []*proxym.Proxy {
+	rng := rand.New(rand.NewPCG(seed, seed)) //nolint: gosec // can be used ordinary random sampling
+	base := time.Unix(0, 0)
+
+	proxies := make([]*proxym.Proxy, 0, n)
+	for i := range n {
+		total := rng.UintN(1000)
+		success := rng.UintN(total + 1)
+		proxies = append(proxies, NewSeededProxy(
+			fmt.Sprintf("http://proxy%d:8080", i),
+			proxym.StatsSnapshot{
+				TotalRequests: total,
+				SuccessCount:  success,
+				ErrorCount:    total - success,
+				LastUsed:      base.Add(time.Duration(rng.Int64N(int64(time.Hour)))),
+			},
+		))
+	}
+	return proxies
+}
@@ -0,0 +1,112 @@
+package selects
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/nezbut/proxym"
+)
+
+// stickyPin is the proxy pinned to a key.
+type stickyPin struct {
+	proxy     *proxym.Proxy
+	expiresAt time.Time
+}
+
+// StickySelect is a proxy selection strategy that pins a proxy per key for the TTL, then rotates it.
+//
+// The key is the session key from the context, see proxym.ContextWithSessionKey, or the target domain
+// if there is no session key. Sites that tie the sessions or cookies to the client IP keep seeing the same IP.
+// The proxy of a new key is selected by the inner strategy. A pinned proxy that is no longer returned
+// by the provider, for example it is disabled and filtered out, is replaced before the TTL expires.
+//
+// The pins apply per request only with a rotation strategy that rotates on every request, see the package doc.
+type StickySelect struct {
+	clocked
+	provider  proxym.SelectStrategyProxyProvider
+	inner     proxym.SelectStrategy
+	ttl       time.Duration
+	pins      map[string]stickyPin
+	lastSweep time.Time
+	mu        sync.Mutex
+}
+
+// NewStickySelectFactory returns a new proxym.SelectStrategyFactory for StickySelect with the TTL of the pins
+// and the factory of the inner strategy, if it is nil, NewRandomSelect is used.
+func NewStickySelectFactory(
+	ttl time.Duration,
+	inner proxym.SelectStrategyFactory,
+	opts ...ClockOption,
+) proxym.SelectStrategyFactory {
+	if inner == nil {
+		inner = NewRandomSelect
+	}
+	return func(provider proxym.SelectStrategyProxyProvider) proxym.SelectStrategy {
+		return &StickySelect{
+			clocked:  newClocked(opts),
+			provider: provider,
+			inner:    inner(provider),
+			ttl:      ttl,
+			pins:     make(map[string]stickyPin),
+		}
+	}
+}
+
+// Select returns the proxy pinned to the empty key.
+func (s *StickySelect) Select() (*proxym.Proxy, error) {
+	return s.SelectDomain(context.Background(), "")
+}
+
+// SelectContext returns the proxy pinned to the session key from the context.
+func (s *StickySelect) SelectContext(ctx context.Context) (*proxym.Proxy, error) {
+	return s.SelectDomain(ctx, "")
+}
+
+// SelectDomain returns the proxy pinned to the session key from the context or to the domain.
+func (s *StickySelect) SelectDomain(ctx context.Context, domain string) (*proxym.Proxy, error) {
+	key, ok := proxym.SessionKeyFromContext(ctx)
+	if !ok {
+		key = domain
+	}
+	now := s.clock.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sweep(now)
+
+	if pin, ok := s.pins[key]; ok && now.Before(pin.expiresAt) && s.available(pin.proxy) {
+		return pin.proxy, nil
+	}
+
+	proxy, err := proxym.SelectDomain(ctx, domain, s.inner)
+	if err != nil || proxy == nil {
+		delete(s.pins, key)
+		return proxy, err
+	}
+	s.pins[key] = stickyPin{proxy: proxy, expiresAt: now.Add(s.ttl)}
+	return proxy, nil
+}
+
+// available returns true if the proxy is still returned by the provider.
+func (s *StickySelect) available(proxy *proxym.Proxy) bool {
+	for _, p := range s.provider.GetProxies() {
+		if p == proxy {
+			return true
+		}
+	}
+	return false
+}
+
+// sweep removes the expired pins at most once per TTL.
+func (s *StickySelect) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < s.ttl {
+		return
+	}
+	s.lastSweep = now
+	for key, pin := range s.pins {
+		if !now.Before(pin.expiresAt) {
+			delete(s.pins, key)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package selects
+
+import (
+	"math"
+	"time"
+
+	"github.com/nezbut/proxym"
+)
+
+// warmupScale is the multiplier of the weights while the warm-up is enabled,
+// it gives the effective weights a granularity of a tenth.
+const warmupScale = 10
+
+// defaultWarmupMinFactor is the default starting factor of the warm-up.
+const defaultWarmupMinFactor = 0.1
+
+// Warmup is the warm-up of new proxies in the weighted select strategies.
+//
+// A proxy in warm-up gets a reduced effective weight, so it does not immediately take full traffic.
+// The ramp curve is linear: the effective weight factor grows from MinFactor to 1
+// with the progress of the warm-up, which is the elapsed part of Duration since Stats().FirstUsed()
+// or the made part of Requests by Stats().TotalRequests(), whichever is greater.
+// A proxy that has never been used starts with MinFactor.
+//
+// The zero Warmup disables the warm-up.
+type Warmup struct {
+	// Duration is the duration of the warm-up since the first use of the proxy.
+	Duration time.Duration
+	// Requests is the number of requests of the warm-up.
+	Requests uint
+	// MinFactor is the starting effective weight factor in (0, 1], by default 0.1.
+	MinFactor float64
+	// Clock is the clock used for the duration, by default proxym.SystemClock.
+	Clock proxym.Clock
+}
+
+// enabled returns true if the warm-up is enabled.
+func (w Warmup) enabled() bool {
+	return w.Duration > 0 || w.Requests > 0
+}
+
+// factor returns the effective weight factor of the proxy.
+func (w Warmup) factor(p *proxym.Proxy) float64 {
+	stats := p.Stats().Snapshot()
+
+	progress := 0.0
+	if w.Requests > 0 {
+		progress = float64(stats.TotalRequests) / float64(w.Requests)
+	}
+	if w.Duration > 0 && !stats.FirstUsed.IsZero() {
+		clock := w.Clock
+		if clock == nil {
+			clock = proxym.SystemClock{}
+		}
+		progress = math.Max(progress, float64(clock.Now().Sub(stats.FirstUsed))/float64(w.Duration))
+	}
+
+	minFactor := w.MinFactor
+	if minFactor <= 0 || minFactor > 1 {
+		minFactor = defaultWarmupMinFactor
+	}
+	return minFactor + (1-minFactor)*math.Min(progress, 1)
+}
+
+// weight returns the effective weight of the proxy.
+func (w Warmup) weight(p *proxym.Proxy) uint {
+	weight := p.Metadata().Weight()
+	if !w.enabled() {
+		return weight
+	}
+	return max(uint(math.Round(float64(weight*warmupScale)*w.factor(p))), 1)
+}
@@ -0,0 +1,54 @@
+package selects_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nezbut/proxym"
+	"github.com/nezbut/proxym/selects"
+)
+
+func TestWeightedRoundRobinSelectWarmup(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1_000_000, 0)}
+	seeded := func(rawURL string, firstUsed time.Time) *proxym.Proxy {
+		return selects.NewSeededProxy(rawURL, proxym.StatsSnapshot{
+			TotalRequests: 1, SuccessCount: 1, FirstUsed: firstUsed, LastUsed: firstUsed,
+		})
+	}
+	fresh := seeded("http://fresh:8080", clock.Now())
+	proxies := []*proxym.Proxy{
+		seeded("http://proxy1:8080", clock.Now().Add(-24*time.Hour)),
+		seeded("http://proxy2:8080", clock.Now().Add(-24*time.Hour)),
+		fresh,
+	}
+
+	strategy := selects.NewWeightedRoundRobinSelectFactory(selects.Warmup{
+		Duration:  time.Hour,
+		MinFactor: 0.1,
+		Clock:     clock,
+	})(selects.StaticProvider(proxies))
+
+	share := func() float64 {
+		counts := countSelections(t, strategy, 2100)
+		return float64(counts[fresh]) / 2100
+	}
+
+	// The effective weights are 10:10:1 at the start, 10:10:6 at the middle (rounded) and 10:10:10 at the end.
+	steps := []struct {
+		elapsed time.Duration
+		want    float64
+	}{
+		{0, 1.0 / 21},
+		{30 * time.Minute, 6.0 / 26},
+		{time.Hour, 1.0 / 3},
+		{2 * time.Hour, 1.0 / 3},
+	}
+	var elapsed time.Duration
+	for _, step := range steps {
+		clock.Advance(step.elapsed - elapsed)
+		elapsed = step.elapsed
+		if got := share(); got < step.want-0.01 || got > step.want+0.01 {
+			t.Errorf("after %v: got share %.3f, want %.3f", step.elapsed, got, step.want)
+		}
+	}
+}
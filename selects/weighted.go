@@ -0,0 +1,107 @@
+package selects
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/nezbut/proxym"
+)
+
+// staleWeightSelections is the number of selections after which the current weight of a proxy
+// that was not among the candidates is dropped.
+const staleWeightSelections = 1024
+
+// WeightedRoundRobinSelect is a proxy selection strategy that returns proxies in a smooth weighted round-robin
+// fashion by Metadata().Weight(), a proxy with weight 3 is returned three times as often as a proxy with weight 1.
+//
+// The strategy keeps the current weight of the smooth weighted round-robin per proxy, on each Select
+// the current weights of the candidates grow by their weights, the proxy with the greatest current weight
+// is returned and its current weight is reduced by the sum of the weights, which costs O(n) for n proxies.
+// The current weights are carried across the changes of the candidates, so the filters that remove
+// the last used or the active proxy on almost every call do not distort the distribution,
+// and a weight changed via metadata at runtime takes effect on the next Select.
+// The current weight of a proxy that is not among the candidates for 1024 selections is dropped.
+//
+// With the Warmup the effective weights of new proxies grow during the warm-up.
+type WeightedRoundRobinSelect struct {
+	provider   proxym.SelectStrategyProxyProvider
+	warmup     Warmup
+	current    map[*proxym.Proxy]*weightState
+	selections uint64
+	mu         sync.Mutex
+}
+
+// weightState is the smooth weighted round-robin state of a proxy.
+type weightState struct {
+	current  int
+	lastSeen uint64
+}
+
+// NewWeightedRoundRobinSelect returns a new WeightedRoundRobinSelect.
+func NewWeightedRoundRobinSelect(provider proxym.SelectStrategyProxyProvider) proxym.SelectStrategy {
+	return &WeightedRoundRobinSelect{
+		provider: provider,
+		current:  make(map[*proxym.Proxy]*weightState),
+	}
+}
+
+// NewWeightedRoundRobinSelectFactory returns a new proxym.SelectStrategyFactory
+// for WeightedRoundRobinSelect with the warm-up of new proxies.
+func NewWeightedRoundRobinSelectFactory(warmup Warmup) proxym.SelectStrategyFactory {
+	return func(provider proxym.SelectStrategyProxyProvider) proxym.SelectStrategy {
+		return &WeightedRoundRobinSelect{
+			provider: provider,
+			warmup:   warmup,
+			current:  make(map[*proxym.Proxy]*weightState),
+		}
+	}
+}
+
+// Select returns the proxy to use.
+func (s *WeightedRoundRobinSelect) Select() (*proxym.Proxy, error) {
+	proxies := s.provider.GetProxies()
+	if len(proxies) == 0 {
+		return nil, fmt.Errorf("%w: empty proxies from provider", proxym.ErrFailedSelectProxy)
+	}
+	weights := make([]int, len(proxies))
+	for i, p := range proxies {
+		weights[i] = int(s.warmup.weight(p)) //nolint: gosec // the weights are far below the int range
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.selections++
+	total := 0
+	var best *weightState
+	bestIndex := 0
+	for i, p := range proxies {
+		state, ok := s.current[p]
+		if !ok {
+			state = &weightState{}
+			s.current[p] = state
+		}
+		state.lastSeen = s.selections
+		state.current += weights[i]
+		total += weights[i]
+		if best == nil || state.current > best.current {
+			best, bestIndex = state, i
+		}
+	}
+	best.current -= total
+
+	if s.selections%staleWeightSelections == 0 {
+		s.dropStale()
+	}
+	return proxies[bestIndex], nil
+}
+
+// dropStale drops the current weights of the proxies that were not among the candidates
+// for staleWeightSelections selections.
+func (s *WeightedRoundRobinSelect) dropStale() {
+	for p, state := range s.current {
+		if s.selections-state.lastSeen >= staleWeightSelections {
+			delete(s.current, p)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package selects_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nezbut/proxym"
+	"github.com/nezbut/proxym/rotations"
+	"github.com/nezbut/proxym/selects"
+)
+
+func weightedProxies(weights ...uint) []*proxym.Proxy {
+	proxies := make([]*proxym.Proxy, 0, len(weights))
+	for i, w := range weights {
+		meta := proxym.NewProxyMetadata("", proxym.ProxyPriorityMedium, time.Time{})
+		meta.SetWeight(w)
+		proxies = append(proxies, proxym.NewProxyStr("http://proxy"+string(rune('a'+i))+":8080", meta))
+	}
+	return proxies
+}
+
+func TestWeightedRoundRobinSelectDistribution(t *testing.T) {
+	proxies := weightedProxies(3, 1, 1)
+	strategy := selects.NewWeightedRoundRobinSelect(selects.StaticProvider(proxies))
+
+	counts := countSelections(t, strategy, 1000)
+	for i, want := range []int{600, 200, 200} {
+		if got := counts[proxies[i]]; got != want {
+			t.Errorf("proxy %d: got %d selections, want %d", i, got, want)
+		}
+	}
+}
+
+func TestWeightedRoundRobinSelectWeightChange(t *testing.T) {
+	proxies := weightedProxies(3, 1, 1)
+	strategy := selects.NewWeightedRoundRobinSelect(selects.StaticProvider(proxies))
+	countSelections(t, strategy, 501)
+
+	proxies[2].Metadata().SetWeight(3)
+	// One cycle of the new weights is 7 selections, the distribution holds within it.
+	counts := countSelections(t, strategy, 700)
+	for i, want := range []int{300, 100, 300} {
+		if got := counts[proxies[i]]; got < want-3 || got > want+3 {
+			t.Errorf("proxy %d: got %d selections, want %d", i, got, want)
+		}
+	}
+}
+
+func TestWeightedRoundRobinSelectFiltered(t *testing.T) {
+	proxies := weightedProxies(3, 1, 1)
+	pm := proxym.NewProxyManager(
+		proxym.WithProxies(proxies...),
+		proxym.WithRotationStrategy(rotations.RoundRobinRotation{}),
+		proxym.WithSelectStrategy(selects.NewFilteredSelectFactory(
+			selects.NewWeightedRoundRobinSelect, selects.RemoveActiveProxyFilter{},
+		)),
+	)
+
+	counts := make(map[*proxym.Proxy]int)
+	for range 1000 {
+		p, err := pm.GetNextProxy("example.com")
+		if err != nil {
+			t.Fatal(err)
+		}
+		counts[p]++
+	}
+
+	// The active proxy is removed, so the heaviest proxy gets at most every other selection,
+	// none of the proxies is starved and the others split the rest by their equal weights.
+	heavy, b, c := counts[proxies[0]], counts[proxies[1]], counts[proxies[2]]
+	if heavy > 500 || heavy <= b || heavy <= c {
+		t.Errorf("heaviest proxy: got %d selections, want more than the others and at most 500", heavy)
+	}
+	if b < 200 || c < 200 || b-c > 5 || c-b > 5 {
+		t.Errorf("equal proxies: got %d and %d selections, want about the same", b, c)
+	}
+}
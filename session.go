@@ -0,0 +1,92 @@
+package proxym
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Placeholders of the SessionizedProxy username template.
+const (
+	SessionPlaceholder = "{session}"
+	CountryPlaceholder = "{country}"
+)
+
+// ErrInvalidSessionTemplate is the error of a SessionizedProxy template without SessionPlaceholder.
+var ErrInvalidSessionTemplate = errors.New("invalid session template")
+
+// SessionizedProxy is a rotating gateway that encodes the session in the proxy username,
+// for example "user-session-{session}-country-{country}".
+//
+// Every session is a distinct exit of the gateway, so one gateway url expands into many logical proxies
+// that are selected, rotated and disabled independently.
+type SessionizedProxy struct {
+	gateway  *url.URL
+	template string
+	meta     *ProxyMetadata
+}
+
+// NewSessionizedProxy returns a new SessionizedProxy of the gateway url with the username template.
+//
+// SessionPlaceholder is replaced by the session id and CountryPlaceholder by the country of the metadata,
+// the password of the gateway url is kept. Every logical proxy gets a copy of the metadata, it may be nil.
+// It returns an error if the url is invalid or the template has no SessionPlaceholder.
+func NewSessionizedProxy(gatewayURL, template string, meta *ProxyMetadata) (*SessionizedProxy, error) {
+	gateway, err := url.Parse(gatewayURL)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.Contains(template, SessionPlaceholder) {
+		return nil, fmt.Errorf("%w: no %s in %q", ErrInvalidSessionTemplate, SessionPlaceholder, template)
+	}
+	return &SessionizedProxy{gateway: gateway, template: template, meta: meta}, nil
+}
+
+// Username returns the username of the session.
+func (s *SessionizedProxy) Username(session string) string {
+	var country string
+	if s.meta != nil {
+		country = s.meta.Country()
+	}
+	return strings.NewReplacer(SessionPlaceholder, session, CountryPlaceholder, country).Replace(s.template)
+}
+
+// Proxy returns the logical proxy of the session.
+func (s *SessionizedProxy) Proxy(session string) *Proxy {
+	u := *s.gateway
+	if password, ok := s.gateway.User.Password(); ok {
+		u.User = url.UserPassword(s.Username(session), password)
+	} else {
+		u.User = url.User(s.Username(session))
+	}
+
+	var meta *ProxyMetadata
+	if s.meta != nil {
+		meta = s.meta.Clone()
+	}
+	return NewProxy(&u, meta)
+}
+
+// NewSession returns the logical proxy of a new random session.
+func (s *SessionizedProxy) NewSession() *Proxy {
+	return s.Proxy(newSessionID())
+}
+
+// Proxies returns n logical proxies of distinct random sessions, for example for AddProxies.
+func (s *SessionizedProxy) Proxies(n int) []*Proxy {
+	proxies := make([]*Proxy, 0, n)
+	for range n {
+		proxies = append(proxies, s.NewSession())
+	}
+	return proxies
+}
+
+// newSessionID returns a random session id of 16 hex characters.
+func newSessionID() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,234 @@
+package proxym
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"golang.org/x/net/proxy"
+)
+
+// DialContextFunc is a function that dials the address on the network, like net.Dialer.DialContext.
+type DialContextFunc func(ctx context.Context, network, addr string) (net.Conn, error)
+
+// Dial dials the address on the network with the background context.
+func (f DialContextFunc) Dial(network, addr string) (net.Conn, error) {
+	return f(context.Background(), network, addr)
+}
+
+// DialContext dials the address on the network.
+func (f DialContextFunc) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	return f(ctx, network, addr)
+}
+
+// IsSOCKS5 returns true if the url is a SOCKS5 proxy url, that is, its scheme is "socks5" or "socks5h".
+func IsSOCKS5(u *url.URL) bool {
+	if u == nil {
+		return false
+	}
+	scheme := strings.ToLower(u.Scheme)
+	return scheme == "socks5" || scheme == "socks5h"
+}
+
+// SOCKS5Dialer returns the function that dials the addresses through the SOCKS5 proxy of the url
+// with the username and the password of the url if any. The connections to the proxy are dialed by forward,
+// if it is nil, a net.Dialer is used.
+//
+// With the "socks5" scheme the host names of the addresses are resolved locally and the proxy receives
+// the IP addresses, with the "socks5h" scheme the host names are resolved by the proxy.
+//
+// It returns an ErrUnsupportedProxyScheme error if the url is not a SOCKS5 proxy url, see IsSOCKS5.
+func SOCKS5Dialer(u *url.URL, forward DialContextFunc) (DialContextFunc, error) {
+	if !IsSOCKS5(u) {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedProxyScheme, u.Redacted())
+	}
+	if forward == nil {
+		forward = (&net.Dialer{}).DialContext
+	}
+
+	var auth *proxy.Auth
+	if u.User != nil {
+		password, _ := u.User.Password()
+		auth = &proxy.Auth{User: u.User.Username(), Password: password}
+	}
+	port := u.Port()
+	if port == "" {
+		port = defaultProxyPorts["socks5"]
+	}
+	dialer, err := proxy.SOCKS5("tcp", net.JoinHostPort(u.Hostname(), port), auth, forward)
+	if err != nil {
+		return nil, err
+	}
+	contextDialer, ok := dialer.(proxy.ContextDialer)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedProxyScheme, u.Redacted())
+	}
+
+	if strings.EqualFold(u.Scheme, "socks5h") {
+		return contextDialer.DialContext, nil
+	}
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		resolved, err := resolveAddr(ctx, addr)
+		if err != nil {
+			return nil, err
+		}
+		return contextDialer.DialContext(ctx, network, resolved)
+	}, nil
+}
+
+// resolveAddr returns the address with the host name resolved to the first IP address.
+func resolveAddr(ctx context.Context, addr string) (string, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return "", err
+	}
+	if net.ParseIP(host) != nil {
+		return addr, nil
+	}
+	ips, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return "", err
+	}
+	if len(ips) == 0 {
+		return "", &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+	}
+	return net.JoinHostPort(ips[0].IP.String(), port), nil
+}
+
+// SOCKSTransport is http.RoundTripper that sends the requests through the proxies selected by the ProxyManager,
+// the HTTP and HTTPS proxies are used by the base http.Transport with a ProxySelector,
+// the SOCKS5 proxies are dialed with SOCKS5Dialer by a clone of the base http.Transport per proxy,
+// so the mixed pools of the HTTP and SOCKS5 proxies work with the per-request selection,
+// and the connections of the SOCKS5 proxies are pooled per proxy.
+//
+// If the request context has a proxy (see ContextWithProxy), it is used instead of selecting a new one.
+//
+// The http.Transport of a SOCKS5 proxy is kept by its url while the proxy is in the pool of the ProxyManager,
+// the transports of the removed proxies are evicted and their idle connections are closed
+// when the transport of a new SOCKS5 proxy is created.
+//
+// CloneRoundTripperWithProxySelector returns SOCKSTransport for http.Transport if the pool has SOCKS5 proxies,
+// so NewClient and PatchClient support them.
+type SOCKSTransport struct {
+	pm         ProxyManager
+	base       *http.Transport
+	transports map[string]*http.Transport
+	mu         sync.Mutex
+}
+
+// NewSOCKSTransport returns a new SOCKSTransport with a clone of the base http.Transport.
+func NewSOCKSTransport(pm ProxyManager, base *http.Transport) *SOCKSTransport {
+	return &SOCKSTransport{
+		pm:         pm,
+		base:       cloneTransport(pm, base),
+		transports: make(map[string]*http.Transport),
+	}
+}
+
+// cloneTransport returns a clone of the http.Transport with the ProxySelector of the ProxyManager.
+func cloneTransport(pm ProxyManager, t *http.Transport) *http.Transport {
+	cloned := t.Clone()
+	cloned.Proxy = GetProxySelector(pm)
+	cloned.GetProxyConnectHeader = proxyConnectHeader(t.ProxyConnectHeader, t.GetProxyConnectHeader)
+	return cloned
+}
+
+// poolProxies returns the proxies of the pool of the ProxyManager,
+// for ProxyManagerImpl the proxies of its resources are included.
+func poolProxies(pm ProxyManager) []*Proxy {
+	if impl, ok := pm.(*ProxyManagerImpl); ok {
+		return impl.fleet()
+	}
+	return pm.GetProxies()
+}
+
+// hasSOCKS5 returns true if any of the proxies is a SOCKS5 proxy, see IsSOCKS5.
+func hasSOCKS5(proxies []*Proxy) bool {
+	for _, p := range proxies {
+		if IsSOCKS5(p.URL()) {
+			return true
+		}
+	}
+	return false
+}
+
+// RoundTrip selects the proxy and sends the request through it.
+func (t *SOCKSTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	p := ProxyFromContext(req.Context())
+	if p == nil {
+		var err error
+		if p, _, err = nextProxy(t.pm, req); err != nil {
+			return nil, err
+		}
+		req = req.WithContext(ContextWithProxy(req.Context(), p))
+	}
+	if !IsSOCKS5(p.URL()) {
+		return t.base.RoundTrip(req)
+	}
+
+	transport, err := t.socksTransport(p.URL())
+	if err != nil {
+		return nil, err
+	}
+	return transport.RoundTrip(req)
+}
+
+// CloseIdleConnections closes the idle connections of the base http.Transport and of the SOCKS5 proxies.
+func (t *SOCKSTransport) CloseIdleConnections() {
+	t.base.CloseIdleConnections()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, transport := range t.transports {
+		transport.CloseIdleConnections()
+	}
+}
+
+// socksTransport returns the http.Transport of the SOCKS5 proxy, it is created on the first use.
+func (t *SOCKSTransport) socksTransport(u *url.URL) (*http.Transport, error) {
+	key := u.String()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if transport, ok := t.transports[key]; ok {
+		return transport, nil
+	}
+
+	dial, err := SOCKS5Dialer(u, t.base.DialContext)
+	if err != nil {
+		return nil, err
+	}
+	t.evictRemoved()
+	transport := t.base.Clone()
+	transport.Proxy = nil
+	transport.GetProxyConnectHeader = nil
+	transport.OnProxyConnectResponse = nil
+	transport.DialContext = dial
+	transport.DialTLSContext = nil
+	t.transports[key] = transport
+	return transport, nil
+}
+
+// evictRemoved removes the transports of the SOCKS5 proxies that are no longer in the pool of the ProxyManager
+// and closes their idle connections, t.mu must be held.
+func (t *SOCKSTransport) evictRemoved() {
+	if len(t.transports) == 0 {
+		return
+	}
+	pooled := make(map[string]struct{})
+	for _, p := range poolProxies(t.pm) {
+		if u := p.URL(); IsSOCKS5(u) {
+			pooled[u.String()] = struct{}{}
+		}
+	}
+	for key, transport := range t.transports {
+		if _, ok := pooled[key]; !ok {
+			transport.CloseIdleConnections()
+			delete(t.transports, key)
+		}
+	}
+}
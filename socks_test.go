@@ -0,0 +1,57 @@
+package proxym_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/nezbut/proxym"
+)
+
+func TestCloneRoundTripperWithProxySelectorKeepsTransport(t *testing.T) {
+	pm := newManager(newProxies(2))
+	rt, err := proxym.CloneRoundTripperWithProxySelector(pm, http.DefaultTransport)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := rt.(*http.Transport); !ok {
+		t.Errorf("got %T for the pool without SOCKS5 proxies, want *http.Transport", rt)
+	}
+
+	pm = newManager([]*proxym.Proxy{proxym.NewProxyStr("socks5://127.0.0.1:1", nil)})
+	if rt, err = proxym.CloneRoundTripperWithProxySelector(pm, http.DefaultTransport); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := rt.(*proxym.SOCKSTransport); !ok {
+		t.Errorf("got %T for the pool with SOCKS5 proxies, want *proxym.SOCKSTransport", rt)
+	}
+}
+
+func TestSOCKSTransportEvictsRemovedProxies(t *testing.T) {
+	// Nothing listens on port 1, the transports are created and the dials fail.
+	first := proxym.NewProxyStr("socks5h://first@127.0.0.1:1", nil)
+	second := proxym.NewProxyStr("socks5h://second@127.0.0.1:1", nil)
+	pm := newManager([]*proxym.Proxy{first, second})
+	rt := proxym.NewSOCKSTransport(pm, http.DefaultTransport.(*http.Transport))
+
+	roundTrip := func(p *proxym.Proxy) {
+		t.Helper()
+		req, err := http.NewRequest(http.MethodGet, "http://example.com/", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req = req.WithContext(proxym.ContextWithProxy(req.Context(), p))
+		if resp, err := rt.RoundTrip(req); err == nil {
+			resp.Body.Close()
+		}
+	}
+
+	roundTrip(first)
+	if got := rt.SOCKSTransports(); got != 1 {
+		t.Fatalf("got %d transports, want 1", got)
+	}
+	pm.RemoveProxies(first)
+	roundTrip(second)
+	if got := rt.SOCKSTransports(); got != 1 {
+		t.Errorf("got %d transports after the proxy was removed, want 1", got)
+	}
+}
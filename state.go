@@ -0,0 +1,164 @@
+package proxym
+
+import (
+	"fmt"
+	"time"
+)
+
+// ManagerState is a serializable copy of the whole ProxyManagerImpl: the proxies with their metadata,
+// statistics and disabled flags, the global pool and the resources, see ProxyManagerImpl.Snapshot.
+//
+// The pool and the resources reference the proxies by id, see Proxy.ID.
+// The strategies, the matchers and the options are not part of the state.
+type ManagerState struct {
+	// SavedAt is the time when the state was taken.
+	SavedAt time.Time `json:"saved_at"`
+	// Proxies are the proxies of the full fleet.
+	Proxies []ProxyRecord `json:"proxies"`
+	// Pool are the ids of the proxies of the global pool.
+	Pool []string `json:"pool"`
+	// Resources are the resources in order.
+	Resources []ResourceState `json:"resources"`
+}
+
+// ProxyRecord is a serializable copy of a proxy with its url.
+type ProxyRecord struct {
+	// URL is the url of the proxy with the credentials, it is empty for a direct connection.
+	URL string `json:"url"`
+	ProxyState
+}
+
+// ResourceState is a serializable copy of a ResourceConfig.
+//
+// The match priority and the share are informational, the restored resource keeps the settings of its options.
+type ResourceState struct {
+	// Domain is the domain of the resource, see ResourceConfig.Domain.
+	Domain string `json:"domain"`
+	// MatchPriority is the match priority of the resource, see ResourceConfig.MatchPriority.
+	MatchPriority int `json:"match_priority"`
+	// Share is the fair share of the resource, see ResourceConfig.Share.
+	Share uint `json:"share"`
+	// Proxies are the ids of the proxies of the resource.
+	Proxies []string `json:"proxies"`
+	// Stats are the statistics of the requests to the resource, see ResourceConfig.Stats.
+	Stats StatsSnapshot `json:"stats"`
+}
+
+// Snapshot returns the serializable state of the ProxyManagerImpl for backup, migration and debugging,
+// see RestoreProxyManager.
+//
+// It returns an ErrDuplicateProxy error if distinct proxies of the fleet have the same id,
+// that is, the same normalized url, since they can not be told apart in the state, see WithDuplicatePolicy.
+func (pm *ProxyManagerImpl) Snapshot() (*ManagerState, error) {
+	fleet := pm.fleet()
+	state := &ManagerState{
+		SavedAt: pm.clock.Now(),
+		Proxies: make([]ProxyRecord, 0, len(fleet)),
+	}
+
+	seen := make(map[string]struct{}, len(fleet))
+	for _, p := range fleet {
+		if _, ok := seen[p.ID()]; ok {
+			return nil, fmt.Errorf("%w: %s", ErrDuplicateProxy, p.URL().Redacted())
+		}
+		seen[p.ID()] = struct{}{}
+
+		var rawURL string
+		if u := p.URL(); u != nil {
+			rawURL = u.String()
+		}
+		state.Proxies = append(state.Proxies, ProxyRecord{URL: rawURL, ProxyState: p.State()})
+	}
+
+	state.Pool = proxyIDs(pm.GetProxies())
+	for _, rc := range pm.Resources() {
+		state.Resources = append(state.Resources, ResourceState{
+			Domain:        rc.Domain(),
+			MatchPriority: rc.MatchPriority(),
+			Share:         rc.Share(),
+			Proxies:       proxyIDs(rc.GetProxies()),
+			Stats:         rc.Stats().Snapshot(),
+		})
+	}
+	return state, nil
+}
+
+// RestoreProxyManager creates a new ProxyManagerImpl with the options and restores the state into it.
+//
+// The proxies of the state are created with their metadata, statistics and disabled flags
+// and the global pool is set like WithProxies before the options. The strategies are set by the options,
+// like for NewProxyManager. The resources of the state are restored into the resources set by WithResources
+// with the same domain in order: their proxies are added and their statistics are restored.
+//
+// It returns an error if a proxy url is invalid, if a referenced proxy is missing in the state
+// or an ErrResourceNotFound error if a resource of the state is not set by the options.
+func RestoreProxyManager(state *ManagerState, opts ...ProxyManagerImplOption) (*ProxyManagerImpl, error) {
+	proxies := make(map[string]*Proxy, len(state.Proxies))
+	for _, record := range state.Proxies {
+		proxy := NewDirectConnection()
+		if record.URL != "" {
+			var err error
+			if proxy, err = NewProxyParsedStr(record.URL, nil); err != nil {
+				return nil, err
+			}
+		}
+		proxy.RestoreState(record.ProxyState)
+		proxies[proxy.ID()] = proxy
+	}
+
+	pool, err := lookupProxies(proxies, state.Pool)
+	if err != nil {
+		return nil, err
+	}
+	pm := NewProxyManager(append([]ProxyManagerImplOption{WithProxies(pool...)}, opts...)...)
+
+	used := make(map[*ResourceConfig]struct{}, len(state.Resources))
+	resources := pm.Resources()
+	for _, rs := range state.Resources {
+		rc := findResource(resources, used, rs.Domain)
+		if rc == nil {
+			return nil, fmt.Errorf("%w: %q is not set by the options", ErrResourceNotFound, rs.Domain)
+		}
+		used[rc] = struct{}{}
+
+		resourceProxies, err := lookupProxies(proxies, rs.Proxies)
+		if err != nil {
+			return nil, err
+		}
+		rc.AddProxies(resourceProxies...)
+		rc.Stats().Restore(rs.Stats)
+	}
+	return pm, nil
+}
+
+// proxyIDs returns the ids of the proxies.
+func proxyIDs(proxies []*Proxy) []string {
+	ids := make([]string, 0, len(proxies))
+	for _, p := range proxies {
+		ids = append(ids, p.ID())
+	}
+	return ids
+}
+
+// lookupProxies returns the proxies by the ids.
+func lookupProxies(proxies map[string]*Proxy, ids []string) ([]*Proxy, error) {
+	found := make([]*Proxy, 0, len(ids))
+	for _, id := range ids {
+		proxy, ok := proxies[id]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrProxyNotFound, id)
+		}
+		found = append(found, proxy)
+	}
+	return found, nil
+}
+
+// findResource returns the first unused resource with the domain, nil if there is none.
+func findResource(resources []*ResourceConfig, used map[*ResourceConfig]struct{}, domain string) *ResourceConfig {
+	for _, rc := range resources {
+		if _, ok := used[rc]; !ok && rc.Domain() == domain {
+			return rc
+		}
+	}
+	return nil
+}
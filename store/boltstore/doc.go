@@ -0,0 +1,3 @@
+// Package boltstore provides the bbolt-backed proxym.Store for the single-binary deployments
+// that want the durable state of the proxies without an external database.
+package boltstore
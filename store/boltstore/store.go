@@ -0,0 +1,110 @@
+package boltstore
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/nezbut/proxym"
+	bolt "go.etcd.io/bbolt"
+)
+
+// Defaults of the Store.
+const (
+	defaultBucket      = "proxym"
+	defaultOpenTimeout = time.Second
+	fileMode           = 0o600
+)
+
+// stateKey is the key of the snapshot in the bucket.
+var stateKey = []byte("state")
+
+// Store is a proxym.Store that keeps the snapshot as JSON in the bucket of the bbolt database.
+type Store struct {
+	db     *bolt.DB
+	bucket []byte
+	owned  bool
+}
+
+// Option is option for Store.
+type Option func(*Store)
+
+// WithBucket sets the name of the bucket, by default "proxym".
+func WithBucket(name string) Option {
+	return func(s *Store) {
+		s.bucket = []byte(name)
+	}
+}
+
+// NewStore returns a new Store in the opened database, the database is closed by the caller.
+func NewStore(db *bolt.DB, opts ...Option) *Store {
+	s := &Store{db: db, bucket: []byte(defaultBucket)}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Open opens or creates the database file and returns a new Store in it, the database is closed by Close.
+//
+// The database file is locked by the process, Open fails if it is not unlocked within a second.
+func Open(path string, opts ...Option) (*Store, error) {
+	db, err := bolt.Open(path, fileMode, &bolt.Options{Timeout: defaultOpenTimeout})
+	if err != nil {
+		return nil, err
+	}
+	s := NewStore(db, opts...)
+	s.owned = true
+	return s, nil
+}
+
+// Close closes the database opened by Open, it does nothing for the database passed to NewStore.
+func (s *Store) Close() error {
+	if !s.owned {
+		return nil
+	}
+	return s.db.Close()
+}
+
+// Save saves the snapshot replacing the saved one in one transaction.
+func (s *Store) Save(ctx context.Context, snapshot *proxym.FleetSnapshot) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+	return s.db.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.CreateBucketIfNotExists(s.bucket)
+		if err != nil {
+			return err
+		}
+		return bucket.Put(stateKey, data)
+	})
+}
+
+// Load returns the saved snapshot, proxym.ErrNoSnapshot if nothing is saved.
+func (s *Store) Load(ctx context.Context) (*proxym.FleetSnapshot, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var snapshot *proxym.FleetSnapshot
+	err := s.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(s.bucket)
+		if bucket == nil {
+			return proxym.ErrNoSnapshot
+		}
+		data := bucket.Get(stateKey)
+		if data == nil {
+			return proxym.ErrNoSnapshot
+		}
+		snapshot = &proxym.FleetSnapshot{}
+		return json.Unmarshal(data, snapshot)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
@@ -0,0 +1,2 @@
+// Package store provides the proxym.Store implementations to persist the state of the proxies.
+package store
@@ -0,0 +1,80 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/nezbut/proxym"
+)
+
+// JSONFile is a proxym.Store that keeps the snapshot in the JSON file.
+//
+// The file is replaced atomically, so a crash during Save does not corrupt the saved snapshot.
+type JSONFile struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewJSONFile returns a new JSONFile with the path of the file.
+func NewJSONFile(path string) *JSONFile {
+	return &JSONFile{path: path}
+}
+
+// Save writes the snapshot to a temporary file and renames it to the file.
+func (f *JSONFile) Save(ctx context.Context, snapshot *proxym.FleetSnapshot) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	tmp, err := os.CreateTemp(filepath.Dir(f.path), filepath.Base(f.path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), f.path)
+}
+
+// Load reads the snapshot from the file, it returns proxym.ErrNoSnapshot if the file does not exist.
+func (f *JSONFile) Load(ctx context.Context) (*proxym.FleetSnapshot, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	data, err := os.ReadFile(f.path)
+	f.mu.Unlock()
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, proxym.ErrNoSnapshot
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var snapshot proxym.FleetSnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, err
+	}
+	return &snapshot, nil
+}
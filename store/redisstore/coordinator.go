@@ -0,0 +1,224 @@
+package redisstore
+
+import (
+	"context"
+	"encoding/json"
+	"maps"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/nezbut/proxym"
+	"github.com/redis/go-redis/v9"
+)
+
+// disabledState is the disabled status of a proxy, until is the unix time in milliseconds, 0 is indefinitely.
+type disabledState struct {
+	disabled bool
+	until    int64
+}
+
+// Coordinator shares the disabled status and the statistics of the proxies between the instances
+// of an application through Redis, the proxies are matched by the id, see proxym.Proxy.ID.
+//
+// On every sync the local changes of the disabled status are published to the "<prefix>:disabled" hash
+// and the changes of the other instances are applied to the local proxies, so when one instance disables a proxy,
+// the others stop selecting it within the sync interval. If both changed the status, the local change wins.
+//
+// The statistics of every instance are published to the "<prefix>:stats:<instance>" hash,
+// the statistics of the cluster are returned by ClusterStats.
+type Coordinator struct {
+	pm     *proxym.ProxyManagerImpl
+	client redis.UniversalClient
+	opts   *options
+	synced map[string]disabledState
+	syncMu sync.Mutex
+	cancel context.CancelFunc
+	done   chan struct{}
+	mu     sync.Mutex
+}
+
+// NewCoordinator returns a new Coordinator of the ProxyManagerImpl with the client.
+func NewCoordinator(pm *proxym.ProxyManagerImpl, client redis.UniversalClient, opts ...Option) *Coordinator {
+	return &Coordinator{
+		pm:     pm,
+		client: client,
+		opts:   newOptions(opts),
+		synced: make(map[string]disabledState),
+	}
+}
+
+// Start starts the periodic syncs in the background, the first sync runs immediately.
+//
+// The syncs run until the context is canceled or Stop is called.
+// Calling Start on a started Coordinator does nothing.
+func (c *Coordinator) Start(ctx context.Context) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.cancel != nil {
+		return
+	}
+
+	ctx, c.cancel = context.WithCancel(ctx)
+	c.done = make(chan struct{})
+	go c.run(ctx, c.done)
+}
+
+// Stop stops the periodic syncs and waits for the running sync to finish.
+func (c *Coordinator) Stop() {
+	c.mu.Lock()
+	cancel, done := c.cancel, c.done
+	c.cancel, c.done = nil, nil
+	c.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+		<-done
+	}
+}
+
+// run runs the periodic syncs until the context is canceled.
+func (c *Coordinator) run(ctx context.Context, done chan struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(c.opts.interval)
+	defer ticker.Stop()
+
+	for {
+		err := c.Sync(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		if c.opts.onSync != nil {
+			c.opts.onSync(err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Sync publishes the local changes and applies the changes of the other instances once.
+//
+// The published and the applied statuses are remembered only if the changes are published,
+// so the local changes of a failed sync are published again by the next one.
+// The times follow the clock of the manager, see proxym.WithClock.
+func (c *Coordinator) Sync(ctx context.Context) error {
+	remote, err := c.client.HGetAll(ctx, c.opts.key("disabled")).Result()
+	if err != nil {
+		return err
+	}
+
+	c.syncMu.Lock()
+	defer c.syncMu.Unlock()
+
+	now := c.pm.Clock().Now()
+	disabledKey := c.opts.key("disabled")
+	statsKey := c.opts.key("stats:" + c.opts.instance)
+	pipe := c.client.TxPipeline()
+	synced := make(map[string]disabledState)
+	stats := make(map[string]any)
+	for _, p := range c.pm.Fleet() {
+		id := p.ID()
+		local := localState(p)
+		if local != c.synced[id] {
+			if local.disabled {
+				pipe.HSet(ctx, disabledKey, id, strconv.FormatInt(local.until, 10))
+			} else {
+				pipe.HDel(ctx, disabledKey, id)
+			}
+			synced[id] = local
+		} else if shared := remoteState(remote[id], now); shared != local {
+			applyState(p, shared)
+			synced[id] = shared
+		}
+
+		data, err := json.Marshal(p.Stats().Snapshot())
+		if err != nil {
+			pipe.Discard()
+			return err
+		}
+		stats[id] = data
+	}
+
+	ttl := c.opts.interval * statsTTLIntervals
+	if len(stats) != 0 {
+		pipe.HSet(ctx, statsKey, stats)
+		pipe.Expire(ctx, statsKey, ttl)
+	}
+	instancesKey := c.opts.key("instances")
+	pipe.ZAdd(ctx, instancesKey, redis.Z{Score: float64(now.UnixMilli()), Member: c.opts.instance})
+	pipe.ZRemRangeByScore(ctx, instancesKey, "-inf", strconv.FormatInt(now.Add(-ttl).UnixMilli(), 10))
+	if _, err := pipe.Exec(ctx); err != nil {
+		return err
+	}
+	maps.Copy(c.synced, synced)
+	return nil
+}
+
+// ClusterStats returns the statistics of the proxies summed over the live instances by the proxy id,
+// see proxym.StatsSnapshot.Add. The statistics of an instance are as fresh as its last sync.
+func (c *Coordinator) ClusterStats(ctx context.Context) (map[string]proxym.StatsSnapshot, error) {
+	since := c.pm.Clock().Now().Add(-c.opts.interval * statsTTLIntervals).UnixMilli()
+	instances, err := c.client.ZRangeByScore(ctx, c.opts.key("instances"), &redis.ZRangeBy{
+		Min: strconv.FormatInt(since, 10),
+		Max: "+inf",
+	}).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	cluster := make(map[string]proxym.StatsSnapshot)
+	for _, instance := range instances {
+		values, err := c.client.HGetAll(ctx, c.opts.key("stats:"+instance)).Result()
+		if err != nil {
+			return nil, err
+		}
+		for id, value := range values {
+			var snapshot proxym.StatsSnapshot
+			if err := json.Unmarshal([]byte(value), &snapshot); err != nil {
+				return nil, err
+			}
+			cluster[id] = cluster[id].Add(snapshot)
+		}
+	}
+	return cluster, nil
+}
+
+// localState returns the disabled status of the local proxy.
+func localState(p *proxym.Proxy) disabledState {
+	if !p.IsDisabled() {
+		return disabledState{}
+	}
+	until := p.DisabledUntil()
+	if until.IsZero() {
+		return disabledState{disabled: true}
+	}
+	return disabledState{disabled: true, until: until.UnixMilli()}
+}
+
+// remoteState returns the disabled status of the value of the shared hash, the expired status is enabled.
+func remoteState(value string, now time.Time) disabledState {
+	if value == "" {
+		return disabledState{}
+	}
+	until, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || (until != 0 && until <= now.UnixMilli()) {
+		return disabledState{}
+	}
+	return disabledState{disabled: true, until: until}
+}
+
+// applyState sets the disabled status to the local proxy.
+func applyState(p *proxym.Proxy, state disabledState) {
+	switch {
+	case !state.disabled:
+		p.Enable()
+	case state.until == 0:
+		p.Disable()
+	default:
+		p.DisableUntil(time.UnixMilli(state.until))
+	}
+}
@@ -0,0 +1,3 @@
+// Package redisstore provides the Redis-backed proxym.Store and the Coordinator
+// that shares the state of the proxies between the instances of an application.
+package redisstore
@@ -0,0 +1,82 @@
+package redisstore
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// Defaults of the options.
+const (
+	defaultKeyPrefix    = "proxym"
+	defaultSyncInterval = 2 * time.Second
+	// statsTTLIntervals is the number of the sync intervals after which the stats of a stopped instance expire.
+	statsTTLIntervals = 10
+)
+
+// Option is option for Store and Coordinator.
+type Option func(*options)
+
+// options are the options of Store and Coordinator.
+type options struct {
+	prefix   string
+	interval time.Duration
+	instance string
+	onSync   func(error)
+}
+
+// WithKeyPrefix sets the prefix of the Redis keys, by default "proxym".
+//
+// The instances that share the state must use the same prefix.
+func WithKeyPrefix(prefix string) Option {
+	return func(o *options) {
+		o.prefix = prefix
+	}
+}
+
+// WithSyncInterval sets the interval between the syncs of the Coordinator, by default 2 seconds.
+// A non-positive interval is replaced by the default.
+func WithSyncInterval(interval time.Duration) Option {
+	return func(o *options) {
+		o.interval = interval
+	}
+}
+
+// WithInstanceID sets the id of the instance under which the Coordinator shares its stats,
+// by default the host name and the process id.
+func WithInstanceID(id string) Option {
+	return func(o *options) {
+		o.instance = id
+	}
+}
+
+// WithOnSync sets the function called after each sync of the Coordinator with its error, nil on success.
+func WithOnSync(onSync func(error)) Option {
+	return func(o *options) {
+		o.onSync = onSync
+	}
+}
+
+// newOptions returns the options with the defaults.
+func newOptions(opts []Option) *options {
+	o := &options{
+		prefix:   defaultKeyPrefix,
+		interval: defaultSyncInterval,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.interval <= 0 {
+		o.interval = defaultSyncInterval
+	}
+	if o.instance == "" {
+		host, _ := os.Hostname()
+		o.instance = fmt.Sprintf("%s-%d", host, os.Getpid())
+	}
+	return o
+}
+
+// key returns the Redis key with the prefix.
+func (o *options) key(name string) string {
+	return o.prefix + ":" + name
+}
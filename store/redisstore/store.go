@@ -0,0 +1,47 @@
+package redisstore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/nezbut/proxym"
+	"github.com/redis/go-redis/v9"
+)
+
+// Store is a proxym.Store that keeps the snapshot in Redis as JSON under the "<prefix>:state" key.
+type Store struct {
+	client redis.UniversalClient
+	opts   *options
+}
+
+// NewStore returns a new Store with the client, only WithKeyPrefix applies to it.
+func NewStore(client redis.UniversalClient, opts ...Option) *Store {
+	return &Store{client: client, opts: newOptions(opts)}
+}
+
+// Save saves the snapshot replacing the saved one.
+func (s *Store) Save(ctx context.Context, snapshot *proxym.FleetSnapshot) error {
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+	return s.client.Set(ctx, s.opts.key("state"), data, 0).Err()
+}
+
+// Load returns the saved snapshot, proxym.ErrNoSnapshot if nothing is saved.
+func (s *Store) Load(ctx context.Context) (*proxym.FleetSnapshot, error) {
+	data, err := s.client.Get(ctx, s.opts.key("state")).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, proxym.ErrNoSnapshot
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var snapshot proxym.FleetSnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, err
+	}
+	return &snapshot, nil
+}
@@ -0,0 +1,40 @@
+package proxym
+
+import "net/http"
+
+// SuccessPredicate is a function that classifies the result of the request through the proxy as a success,
+// the response is nil if the request failed without a response.
+//
+// ProxyTransport records the results to the proxy statistics by the predicate, see WithSuccessPredicate,
+// so the rotation and select strategies that use the errors react to the responses the predicate rejects.
+type SuccessPredicate func(resp *http.Response, err error) bool
+
+// DefaultSuccessPredicate counts any response without an error as a success, whatever its status code is.
+func DefaultSuccessPredicate(resp *http.Response, err error) bool {
+	return resp != nil && err == nil
+}
+
+// FailureStatusCodes returns the SuccessPredicate that counts the responses with the status codes as failures
+// and any other response without an error as a success,
+// for example FailureStatusCodes(http.StatusForbidden, http.StatusTooManyRequests) for a proxy returning endless 403s.
+func FailureStatusCodes(codes ...int) SuccessPredicate {
+	failures := make(map[int]struct{}, len(codes))
+	for _, code := range codes {
+		failures[code] = struct{}{}
+	}
+	return func(resp *http.Response, err error) bool {
+		if !DefaultSuccessPredicate(resp, err) {
+			return false
+		}
+		_, failed := failures[resp.StatusCode]
+		return !failed
+	}
+}
+
+// SuccessStatusRange returns the SuccessPredicate that counts the responses with the status codes
+// from minCode to maxCode inclusive as successes, for example SuccessStatusRange(200, 399).
+func SuccessStatusRange(minCode, maxCode int) SuccessPredicate {
+	return func(resp *http.Response, err error) bool {
+		return DefaultSuccessPredicate(resp, err) && resp.StatusCode >= minCode && resp.StatusCode <= maxCode
+	}
+}
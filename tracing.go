@@ -0,0 +1,86 @@
+package proxym
+
+import (
+	"fmt"
+	"net/http"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName is the name of the OpenTelemetry tracer of proxym.
+const tracerName = "github.com/nezbut/proxym"
+
+// Attribute keys of the spans of ProxyTransport.
+const (
+	attrDomain         = attribute.Key("proxym.domain")
+	attrProxyURL       = attribute.Key("proxym.proxy.url")
+	attrRotation       = attribute.Key("proxym.rotation.decision")
+	attrSelectStrategy = attribute.Key("proxym.select.strategy")
+	attrStatusCode     = attribute.Key("http.response.status_code")
+)
+
+// Rotation decisions of the selection.
+const (
+	// decisionInitial is the selection without the last used proxy.
+	decisionInitial = "initial"
+	// decisionReused is the selection that kept the last used proxy.
+	decisionReused = "reused"
+	// decisionRotated is the selection that rotated the last used proxy.
+	decisionRotated = "rotated"
+)
+
+// selection describes how the proxy was selected.
+type selection struct {
+	decision string
+	strategy string
+	resource *ResourceConfig
+}
+
+// attributes returns the span attributes of the selection.
+func (s selection) attributes() []attribute.KeyValue {
+	attrs := make([]attribute.KeyValue, 0, 2) //nolint: mnd // number of attributes
+	if s.decision != "" {
+		attrs = append(attrs, attrRotation.String(s.decision))
+	}
+	if s.strategy != "" {
+		attrs = append(attrs, attrSelectStrategy.String(s.strategy))
+	}
+	return attrs
+}
+
+// strategyName returns the name of the select strategy, its type name.
+func strategyName(strategy SelectStrategy) string {
+	return fmt.Sprintf("%T", strategy)
+}
+
+// WithTracerProvider sets the OpenTelemetry trace.TracerProvider of the ProxyTransport.
+//
+// RoundTrip is traced by the "proxym.RoundTrip" span and the proxy selection by the child "proxym.GetNextProxy" span
+// with the attributes of the selected proxy url, the rotation decision and the name of the select strategy.
+// The rotation decision and the strategy are known only for ProxyManagerImpl.
+func WithTracerProvider(provider trace.TracerProvider) ProxyTransportOption {
+	return func(pt *ProxyTransport) {
+		pt.tracer = provider.Tracer(tracerName)
+	}
+}
+
+// proxyURLAttribute returns the span attribute of the proxy url with the password redacted.
+func proxyURLAttribute(proxy *Proxy) attribute.KeyValue {
+	if u := proxy.URL(); u != nil {
+		return attrProxyURL.String(u.Redacted())
+	}
+	return attrProxyURL.String(proxy.String())
+}
+
+// recordResult records the response or the error to the span.
+func recordResult(span trace.Span, resp *http.Response, err error) {
+	if resp != nil {
+		span.SetAttributes(attrStatusCode.Int(resp.StatusCode))
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+}
@@ -1,48 +1,311 @@
 package proxym
 
 import (
+	"context"
+	"io"
+	"log/slog"
+	"net"
 	"net/http"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
 )
 
-// ProxyTransport is http.RoundTripper that first receives the response through the base transport
-// Then updates the proxy data.
+// ProxyTransport is http.RoundTripper that first selects the proxy for the request,
+// receives the response through the base transport, then updates the proxy data.
+//
+// The selected proxy is stored in the request context, see ProxyFromContext,
+// so the response can be attributed to the proxy that served it even under concurrency.
 //
 // The base transport must receive a proxy via ProxySelector for requests.
 type ProxyTransport struct {
 	pm            ProxyManager
 	baseTransport http.RoundTripper
+	proxyHeader   string
+	tracer        trace.Tracer
+	disablePolicy DisablePolicy
+	waitRateLimit bool
+	logger        *slog.Logger
+	logLevels     LogLevels
+	isSuccess     SuccessPredicate
+	validator     ResponseValidator
+	peekSize      int
+	banBlocked    bool
+	banTTL        time.Duration
 }
 
 // NewProxyTransport returns a new ProxyTransport.
-func NewProxyTransport(pm ProxyManager, baseTransport http.RoundTripper) *ProxyTransport {
-	return &ProxyTransport{pm: pm, baseTransport: baseTransport}
+func NewProxyTransport(pm ProxyManager, baseTransport http.RoundTripper, opts ...ProxyTransportOption) *ProxyTransport {
+	pt := &ProxyTransport{
+		pm:            pm,
+		baseTransport: baseTransport,
+		tracer:        noop.NewTracerProvider().Tracer(tracerName),
+		isSuccess:     DefaultSuccessPredicate,
+	}
+	for _, opt := range opts {
+		opt(pt)
+	}
+	return pt
 }
 
-// RoundTrip calls the base transport and updates the proxy data.
+// RoundTrip selects the proxy, calls the base transport and updates the proxy data.
+//
+// The request is counted as in-flight for the proxy until the response body is closed.
+// The bytes of the request body are counted as sent while the base transport reads it,
+// so the bodies of unknown length are counted too, the bytes of the response body are counted as received
+// while it is read, see ProxyStats.BytesTransferred.
+//
+// If the proxy has a timeout, see ProxyManagerImpl.ProxyTimeout and ProxyMetadata.Timeout,
+// the request context gets a deadline that covers the whole request including reading the response body.
+//
+// If the request context already has a proxy (see ContextWithProxy), it is used instead of selecting a new one.
+//
+// If the proxy has a rate limiter, see Proxy.SetRateLimiter, the request takes a token from it,
+// if the bucket is empty, the request fails with ErrProxyRateLimited or waits for a token, see WithRateLimitWait.
+//
+// The request is traced if the ProxyTransport has a tracer provider, see WithTracerProvider.
 func (pt *ProxyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx, span := pt.tracer.Start(req.Context(), "proxym.RoundTrip", trace.WithSpanKind(trace.SpanKindClient))
+	req = req.WithContext(ctx)
+
+	proxy := ProxyFromContext(ctx)
+	if proxy == nil {
+		var err error
+		if proxy, err = pt.nextProxy(req); err != nil {
+			recordResult(span, nil, err)
+			span.End()
+			return nil, err
+		}
+		req = req.WithContext(ContextWithProxy(req.Context(), proxy))
+	}
+	span.SetAttributes(proxyURLAttribute(proxy))
+	if err := pt.takeToken(req.Context(), proxy); err != nil {
+		recordResult(span, nil, err)
+		span.End()
+		return nil, err
+	}
+	req = injectProxyHeaders(req, proxy)
+	if req.Body != nil && req.Body != http.NoBody {
+		req.Body = &countingBody{ReadCloser: req.Body, stats: proxy.Stats()}
+	}
+
+	req, cancel := pt.withTimeout(req, proxy)
+	track := pt.track(req, proxy)
+	release := func() {
+		track()
+		cancel()
+		span.End()
+	}
+
+	start := time.Now()
 	resp, err := pt.baseTransport.RoundTrip(req)
-	proxy := pt.pm.LastUsed()
-	if proxy != nil {
-		proxy.Update(resp, err)
+	status, failed := pt.outcome(resp, err)
+	cause := err
+	if blockErr := pt.validate(resp, err); blockErr != nil {
+		failed, cause = true, blockErr
+		pt.blocked(NewRequestInfo(req), proxy)
+	}
+	proxy.record(status, failed)
+	if recorder, ok := pt.pm.(resultRecorder); ok {
+		recorder.recordResult(NewRequestInfo(req), proxy, status, failed)
+	}
+	if failed {
+		pt.logFailedRequest(req.Context(), NewRequestInfo(req), proxy, status, cause)
+	}
+	pt.cooldownFailed(req.Context(), proxy)
+	if err == nil {
+		proxy.Stats().ObserveLatency(time.Since(start))
+	}
+	recordResult(span, resp, err)
+
+	if resp == nil || resp.Body == nil {
+		release()
+	} else {
+		resp.Body = &releaseBody{ReadCloser: resp.Body, stats: proxy.Stats(), release: release}
+	}
+
+	if resp != nil && pt.proxyHeader != "" {
+		if resp.Header == nil {
+			resp.Header = make(http.Header)
+		}
+		resp.Header.Set(pt.proxyHeader, proxy.String())
 	}
 	return resp, err
 }
 
+// nextProxy returns the next available proxy for the request within the "proxym.GetNextProxy" span.
+func (pt *ProxyTransport) nextProxy(req *http.Request) (*Proxy, error) {
+	_, span := pt.tracer.Start(req.Context(), "proxym.GetNextProxy",
+		trace.WithAttributes(attrDomain.String(req.URL.Hostname())),
+	)
+	defer span.End()
+
+	proxy, sel, err := nextProxy(pt.pm, req)
+	span.SetAttributes(sel.attributes()...)
+	if err != nil {
+		recordResult(span, nil, err)
+		return nil, err
+	}
+	span.SetAttributes(proxyURLAttribute(proxy))
+	return proxy, nil
+}
+
+// outcome returns the status code of the response, zero if there is no response,
+// and true if the request failed by the SuccessPredicate, see WithSuccessPredicate.
+func (pt *ProxyTransport) outcome(resp *http.Response, err error) (int, bool) {
+	status, _ := responseOutcome(resp, err)
+	return status, !pt.isSuccess(resp, err)
+}
+
+// takeToken takes a token from the rate limiter of the proxy if it has one, see Proxy.SetRateLimiter.
+//
+// If the bucket is empty, it waits for a token with the WithRateLimitWait option,
+// otherwise it returns ErrProxyRateLimited.
+func (pt *ProxyTransport) takeToken(ctx context.Context, proxy *Proxy) error {
+	limiter := proxy.RateLimiter()
+	switch {
+	case limiter == nil:
+		return nil
+	case pt.waitRateLimit:
+		return limiter.Wait(ctx)
+	case !limiter.Allow():
+		return ErrProxyRateLimited
+	default:
+		return nil
+	}
+}
+
+// cooldownFailed disables the proxy for the cooldown if it has failed too many consecutive times,
+// see WithFailureCooldown.
+func (pt *ProxyTransport) cooldownFailed(ctx context.Context, proxy *Proxy) {
+	if duration := pt.disablePolicy.Apply(proxy); duration > 0 {
+		pt.logCooldown(ctx, proxy, duration)
+	}
+}
+
+// withTimeout returns the request with the context deadline by the timeout of the proxy
+// and the function that cancels the context.
+//
+// If the ProxyManager has a ProxyTimeout method, like ProxyManagerImpl, it is used,
+// otherwise the timeout from the metadata of the proxy is used.
+func (pt *ProxyTransport) withTimeout(req *http.Request, proxy *Proxy) (*http.Request, context.CancelFunc) {
+	var timeout time.Duration
+	if provider, ok := pt.pm.(interface{ ProxyTimeout(*Proxy) time.Duration }); ok {
+		timeout = provider.ProxyTimeout(proxy)
+	} else {
+		timeout = proxy.Metadata().Timeout()
+	}
+	if timeout <= 0 {
+		return req, func() {}
+	}
+
+	ctx, cancel := context.WithTimeout(req.Context(), timeout)
+	return req.WithContext(ctx), cancel
+}
+
+// track counts the request as in-flight for the proxy and for the manager if it tracks requests,
+// it returns the function that releases the request.
+func (pt *ProxyTransport) track(req *http.Request, proxy *Proxy) func() {
+	proxy.acquire()
+	done := func() {}
+	if tracker, ok := pt.pm.(requestTracker); ok {
+		done = tracker.trackRequest(NewRequestInfo(req), proxy)
+	}
+	return func() {
+		done()
+		proxy.release()
+	}
+}
+
+// injectProxyHeaders returns the request with the headers of the proxy if the request is sent to the http proxy
+// as is, that is, the target is http. For https targets the headers are sent in CONNECT, see proxyConnectHeader.
+func injectProxyHeaders(req *http.Request, proxy *Proxy) *http.Request {
+	proxyURL := proxy.URL()
+	if req.URL.Scheme != "http" || proxyURL == nil || (proxyURL.Scheme != "http" && proxyURL.Scheme != "https") {
+		return req
+	}
+	headers := proxy.Metadata().Headers()
+	if len(headers) == 0 {
+		return req
+	}
+
+	req = req.Clone(req.Context())
+	for name, values := range headers {
+		req.Header[name] = values
+	}
+	return req
+}
+
+// countingBody is a request body that adds the bytes read from it to the sent bytes of the proxy.
+type countingBody struct {
+	io.ReadCloser
+	stats *ProxyStats
+}
+
+// Read reads the body and adds the read bytes to the sent bytes of the proxy.
+func (b *countingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if n > 0 {
+		b.stats.AddBytes(uint64(n), 0) //nolint: gosec // checked to be positive
+	}
+	return n, err
+}
+
+// releaseBody is a response body that counts the received bytes
+// and releases the in-flight request when it is closed.
+type releaseBody struct {
+	io.ReadCloser
+	stats   *ProxyStats
+	release func()
+	once    sync.Once
+}
+
+// Read reads the body and adds the read bytes to the received bytes of the proxy.
+func (b *releaseBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if n > 0 {
+		b.stats.AddBytes(0, uint64(n)) //nolint: gosec // checked to be positive
+	}
+	return n, err
+}
+
+// Close closes the body and releases the in-flight request.
+func (b *releaseBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.release)
+	return err
+}
+
+// Settings of the fallback transport, the same as in http.DefaultTransport.
+const (
+	fallbackDialTimeout           = 30 * time.Second
+	fallbackKeepAlive             = 30 * time.Second
+	fallbackMaxIdleConns          = 100
+	fallbackIdleConnTimeout       = 90 * time.Second
+	fallbackTLSHandshakeTimeout   = 10 * time.Second
+	fallbackExpectContinueTimeout = 1 * time.Second
+)
+
 // NewClient returns a new http.Client with a ProxyTransport and with a cloned http.DefaultTransport.
+//
+// If http.DefaultTransport is replaced by an unsupported http.RoundTripper implementation,
+// a fresh http.Transport with the default settings is used instead.
 func NewClient(pm ProxyManager) *http.Client {
-	cloned, _ := CloneRoundTripperWithProxySelector(pm, http.DefaultTransport)
 	return &http.Client{
-		Transport: NewProxyTransport(pm, cloned),
+		Transport: NewProxyTransport(pm, cloneDefaultTransport(pm)),
 	}
 }
 
 // PatchClient patches the http.Client with a ProxyTransport and with a cloned client.Transport.
 //
+// If client.Transport is nil, http.DefaultTransport is cloned like in NewClient.
+//
 // Call this function in the application initialization, as this function is not thread-safe.
 func PatchClient(client *http.Client, pm ProxyManager) error {
 	if client.Transport == nil {
-		cloned, _ := CloneRoundTripperWithProxySelector(pm, http.DefaultTransport)
-		client.Transport = NewProxyTransport(pm, cloned)
+		client.Transport = NewProxyTransport(pm, cloneDefaultTransport(pm))
 	} else {
 		cloned, err := CloneRoundTripperWithProxySelector(pm, client.Transport)
 		if err != nil {
@@ -52,3 +315,28 @@ func PatchClient(client *http.Client, pm ProxyManager) error {
 	}
 	return nil
 }
+
+// cloneDefaultTransport returns a cloned http.DefaultTransport with a ProxySelector
+// or a fresh http.Transport with a ProxySelector if http.DefaultTransport is not supported.
+func cloneDefaultTransport(pm ProxyManager) http.RoundTripper {
+	cloned, err := CloneRoundTripperWithProxySelector(pm, http.DefaultTransport)
+	if err != nil {
+		cloned, _ = CloneRoundTripperWithProxySelector(pm, newFallbackTransport())
+	}
+	return cloned
+}
+
+// newFallbackTransport returns a fresh http.Transport with the settings of http.DefaultTransport.
+func newFallbackTransport() *http.Transport {
+	return &http.Transport{
+		DialContext: (&net.Dialer{
+			Timeout:   fallbackDialTimeout,
+			KeepAlive: fallbackKeepAlive,
+		}).DialContext,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          fallbackMaxIdleConns,
+		IdleConnTimeout:       fallbackIdleConnTimeout,
+		TLSHandshakeTimeout:   fallbackTLSHandshakeTimeout,
+		ExpectContinueTimeout: fallbackExpectContinueTimeout,
+	}
+}
@@ -0,0 +1,203 @@
+package proxym_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nezbut/proxym"
+	"github.com/nezbut/proxym/rotations"
+	"github.com/nezbut/proxym/selects"
+)
+
+// newProxyServers starts n HTTP proxy servers that answer the proxied requests themselves
+// with their own url in the X-Served-By header and returns the proxies of them.
+func newProxyServers(t *testing.T, n int) []*proxym.Proxy {
+	t.Helper()
+	proxies := make([]*proxym.Proxy, 0, n)
+	for range n {
+		var server *httptest.Server
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set("X-Served-By", server.URL)
+		}))
+		t.Cleanup(server.Close)
+		proxies = append(proxies, proxym.NewProxyStr(server.URL, nil))
+	}
+	return proxies
+}
+
+// roundTripperFunc is an adapter to use a function as http.RoundTripper.
+type roundTripperFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestProxyTransportAttribution(t *testing.T) {
+	proxies := newProxyServers(t, 4)
+	pm := proxym.NewProxyManager(
+		proxym.WithProxies(proxies...),
+		proxym.WithRotationStrategy(rotations.RoundRobinRotation{}),
+		proxym.WithSelectStrategy(selects.NewRandomSelect),
+	)
+	base, err := proxym.CloneRoundTripperWithProxySelector(pm, http.DefaultTransport)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &http.Client{Transport: proxym.NewProxyTransport(pm, base, proxym.WithProxyHeader("X-Proxy"))}
+
+	var wg sync.WaitGroup
+	for range 64 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := client.Get("http://example.com/")
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			resp.Body.Close()
+
+			served := resp.Header.Get("X-Served-By")
+			if p := proxym.ProxyFromContext(resp.Request.Context()); p == nil || p.String() != served {
+				t.Errorf("got the proxy %v from the context, want %s that served the request", p, served)
+			}
+			if got := resp.Header.Get("X-Proxy"); got != served {
+				t.Errorf("got the proxy header %s, want %s that served the request", got, served)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestProxyTransportProxyHeaders(t *testing.T) {
+	type seen struct {
+		method string
+		route  string
+	}
+	var (
+		mu   sync.Mutex
+		log  = make(map[string][]seen)
+		urls []string
+	)
+	for range 2 {
+		var server *httptest.Server
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			log[server.URL] = append(log[server.URL], seen{method: r.Method, route: r.Header.Get("X-Route")})
+			mu.Unlock()
+			if r.Method == http.MethodConnect {
+				// The tunnel is refused, only the CONNECT request is checked.
+				w.WriteHeader(http.StatusBadGateway)
+			}
+		}))
+		t.Cleanup(server.Close)
+		urls = append(urls, server.URL)
+	}
+	routed := proxym.NewProxyStr(urls[0], proxym.NewProxyMetadata("", proxym.ProxyPriorityMedium, time.Time{}))
+	if err := routed.Metadata().SetHeader("X-Route", "eu"); err != nil {
+		t.Fatal(err)
+	}
+	plain := proxym.NewProxyStr(urls[1], nil)
+	pm := newManager([]*proxym.Proxy{routed, plain})
+	client := proxym.NewClient(pm)
+
+	for _, p := range []*proxym.Proxy{routed, plain} {
+		for _, target := range []string{"http://example.com/", "https://example.com/"} {
+			req, err := http.NewRequestWithContext(proxym.ContextWithProxy(context.Background(), p),
+				http.MethodGet, target, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp, err := client.Do(req); err == nil {
+				resp.Body.Close()
+			}
+		}
+	}
+
+	for url, want := range map[string]string{urls[0]: "eu", urls[1]: ""} {
+		requests := log[url]
+		if len(requests) != 2 {
+			t.Fatalf("proxy %s: got %d requests, want the request and the CONNECT", url, len(requests))
+		}
+		for _, r := range requests {
+			if r.route != want {
+				t.Errorf("proxy %s: got X-Route %q in %s, want %q", url, r.route, r.method, want)
+			}
+		}
+	}
+}
+
+func TestNewClientUnsupportedDefaultTransport(t *testing.T) {
+	defaultTransport := http.DefaultTransport
+	http.DefaultTransport = roundTripperFunc(func(*http.Request) (*http.Response, error) {
+		t.Error("got a request through the unsupported default transport")
+		return nil, http.ErrNotSupported
+	})
+	t.Cleanup(func() { http.DefaultTransport = defaultTransport })
+
+	proxies := newProxyServers(t, 1)
+	pm := newManager(proxies)
+	patched := &http.Client{}
+	if err := proxym.PatchClient(patched, pm); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, client := range map[string]*http.Client{"NewClient": proxym.NewClient(pm), "PatchClient": patched} {
+		resp, err := client.Get("http://example.com/")
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		resp.Body.Close()
+		if got := resp.Header.Get("X-Served-By"); got != proxies[0].String() {
+			t.Errorf("%s: got the response served by %q, want the proxy %s", name, got, proxies[0])
+		}
+	}
+}
+
+func TestProxyTransportPriorityTimeouts(t *testing.T) {
+	tiered := func(rawURL string, priority proxym.ProxyPriority, timeout time.Duration) *proxym.Proxy {
+		meta := proxym.NewProxyMetadata("", priority, time.Time{})
+		meta.SetTimeout(timeout)
+		return proxym.NewProxyStr(rawURL, meta)
+	}
+	low := tiered("http://low:8080", proxym.ProxyPriorityLow, 0)
+	high := tiered("http://high:8080", proxym.ProxyPriorityHigh, 0)
+	// The timeout of the proxy takes precedence over the timeout of its tier.
+	lowOwn := tiered("http://low-own:8080", proxym.ProxyPriorityLow, time.Second)
+	timeouts := map[proxym.ProxyPriority]time.Duration{
+		proxym.ProxyPriorityLow:  10 * time.Millisecond,
+		proxym.ProxyPriorityHigh: time.Second,
+	}
+	pm := newManager([]*proxym.Proxy{low, high, lowOwn}, proxym.WithPriorityTimeouts(timeouts))
+
+	// The upstream answers after 100ms unless the request deadline comes first.
+	slow := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		select {
+		case <-time.After(100 * time.Millisecond):
+			return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody, Request: req}, nil
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		}
+	})
+	pt := proxym.NewProxyTransport(pm, slow)
+
+	for p, wantErr := range map[*proxym.Proxy]bool{low: true, high: false, lowOwn: false} {
+		req, err := http.NewRequestWithContext(proxym.ContextWithProxy(context.Background(), p),
+			http.MethodGet, "http://example.com/", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := pt.RoundTrip(req)
+		if err == nil {
+			resp.Body.Close()
+		}
+		if gotErr := errors.Is(err, context.DeadlineExceeded); gotErr != wantErr {
+			t.Errorf("%s: got error %v, want the timeout %t", p.URL(), err, wantErr)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package proxym
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// DefaultProxySchemes are the proxy schemes supported by net/http, they are allowed by WithStrictValidation by default.
+var DefaultProxySchemes = []string{"http", "https", "socks5", "socks5h"}
+
+// ErrInvalidProxyURL is the error of a proxy url rejected by the validation, see ProxyURLError.
+var ErrInvalidProxyURL = errors.New("invalid proxy url")
+
+// ProxyURLError is an error of a proxy url rejected by the validation.
+//
+// It wraps ErrInvalidProxyURL, so errors.Is works with it.
+type ProxyURLError struct {
+	// URL is the url of the proxy with the password redacted.
+	URL string
+	// Reason is the reason of the rejection, for example "unsupported scheme \"ftp\"".
+	Reason string
+}
+
+// Error returns the error message.
+func (e *ProxyURLError) Error() string {
+	return fmt.Sprintf("%s %q: %s", ErrInvalidProxyURL, e.URL, e.Reason)
+}
+
+// Unwrap returns ErrInvalidProxyURL.
+func (e *ProxyURLError) Unwrap() error {
+	return ErrInvalidProxyURL
+}
+
+// ValidateProxy returns a *ProxyURLError if the url of the proxy has a scheme out of the schemes,
+// has no host or has an invalid port. If schemes is empty, DefaultProxySchemes are allowed.
+//
+// A direct connection is always valid.
+func ValidateProxy(proxy *Proxy, schemes ...string) error {
+	u := proxy.URL()
+	if u == nil {
+		return nil
+	}
+	if len(schemes) == 0 {
+		schemes = DefaultProxySchemes
+	}
+
+	var reason string
+	switch port := u.Port(); {
+	case u.Scheme == "":
+		reason = "missing scheme"
+	case !slices.Contains(schemes, strings.ToLower(u.Scheme)):
+		reason = fmt.Sprintf("unsupported scheme %q", u.Scheme)
+	case u.Hostname() == "":
+		reason = "missing host"
+	case strings.HasSuffix(u.Host, ":") && port == "":
+		reason = "empty port"
+	case port != "" && !validPort(port):
+		reason = fmt.Sprintf("invalid port %q", port)
+	default:
+		return nil
+	}
+	return &ProxyURLError{URL: u.Redacted(), Reason: reason}
+}
+
+// validPort returns true if the port is a number from 1 to 65535.
+func validPort(port string) bool {
+	n, err := strconv.ParseUint(port, 10, 16)
+	return err == nil && n != 0
+}
+
+// ValidateProxies validates the proxies like AddProxies does and returns the joined errors of the invalid proxies.
+//
+// It returns nil if the strict validation is not enabled, see WithStrictValidation.
+func (pm *ProxyManagerImpl) ValidateProxies(proxies ...*Proxy) error {
+	if pm.validSchemes == nil {
+		return nil
+	}
+	errs := make([]error, 0)
+	for _, proxy := range proxies {
+		if err := ValidateProxy(proxy, pm.validSchemes...); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,112 @@
+package proxym
+
+import "time"
+
+// Settings of the windowed counters of ProxyStats.
+const (
+	// windowBucketSize is the time span of a bucket of the windowed counters.
+	windowBucketSize = 10 * time.Second
+	// windowRetention is the maximum age of the buckets of the windowed counters.
+	windowRetention = time.Hour
+	// maxRecentRequests is the maximum number of the last requests kept by the windowed counters.
+	maxRecentRequests = 1000
+)
+
+// WindowStats is the number of the requests and the errors of the proxy in a recent time window,
+// see ProxyStats.Window.
+type WindowStats struct {
+	Requests uint
+	Errors   uint
+}
+
+// ErrorRate returns the ratio of the errors to the requests in the window.
+//
+// If there are no requests, it returns 0.
+func (w WindowStats) ErrorRate() float64 {
+	if w.Requests == 0 {
+		return 0
+	}
+	return float64(w.Errors) / float64(w.Requests)
+}
+
+// SuccessRate returns the ratio of the successful requests to the requests in the window.
+//
+// If there are no requests, it returns 1 like StatsSnapshot.SuccessRate.
+func (w WindowStats) SuccessRate() float64 {
+	return 1 - w.ErrorRate()
+}
+
+// windowBucket is the counters of the requests started in the bucket time span.
+type windowBucket struct {
+	start    time.Time
+	requests uint
+	errors   uint
+}
+
+// statsWindow is the windowed counters of the requests, the buckets older than windowRetention are dropped.
+//
+// The outcomes of the last maxRecentRequests requests are kept in the ring buffer.
+type statsWindow struct {
+	buckets []windowBucket
+	recent  []bool
+	next    int
+}
+
+// record counts the request at now.
+func (w *statsWindow) record(now time.Time, failed bool) {
+	if len(w.recent) < maxRecentRequests {
+		w.recent = append(w.recent, failed)
+	} else {
+		w.recent[w.next] = failed
+	}
+	w.next = (w.next + 1) % maxRecentRequests
+
+	if n := len(w.buckets); n == 0 || !now.Before(w.buckets[n-1].start.Add(windowBucketSize)) {
+		w.buckets = append(w.buckets, windowBucket{start: now.Truncate(windowBucketSize)})
+		w.prune(now)
+	}
+	last := &w.buckets[len(w.buckets)-1]
+	last.requests++
+	if failed {
+		last.errors++
+	}
+}
+
+// prune drops the buckets older than windowRetention.
+func (w *statsWindow) prune(now time.Time) {
+	cutoff := now.Add(-windowRetention)
+	i := 0
+	for i < len(w.buckets) && w.buckets[i].start.Add(windowBucketSize).Before(cutoff) {
+		i++
+	}
+	if i > 0 {
+		w.buckets = append(w.buckets[:0], w.buckets[i:]...)
+	}
+}
+
+// since returns the counters of the buckets that end after since.
+func (w *statsWindow) since(since time.Time) WindowStats {
+	var stats WindowStats
+	for i := len(w.buckets) - 1; i >= 0; i-- {
+		bucket := w.buckets[i]
+		if !bucket.start.Add(windowBucketSize).After(since) {
+			break
+		}
+		stats.Requests += bucket.requests
+		stats.Errors += bucket.errors
+	}
+	return stats
+}
+
+// last returns the counters of the last n requests.
+func (w *statsWindow) last(n uint) WindowStats {
+	var stats WindowStats
+	size := len(w.recent)
+	for i := 1; i <= size && stats.Requests < n; i++ {
+		stats.Requests++
+		if w.recent[(w.next-i+size)%size] {
+			stats.Errors++
+		}
+	}
+	return stats
+}